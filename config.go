@@ -0,0 +1,189 @@
+package gocosmos
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	settingDefaultDb   = "DEFAULTDB"
+	settingDb          = "DB"
+	settingMaxRetries  = "MAXRETRIES"
+	settingConsistency = "CONSISTENCYLEVEL"
+	settingRegions     = "PREFERREDREGIONS"
+
+	defaultTimeout = 10 * time.Second
+)
+
+// consistencyLevels maps accepted consistency level names (lower-cased) to the values expected by Cosmos DB.
+var consistencyLevels = map[string]string{
+	"strong":           "Strong",
+	"boundedstaleness": "BoundedStaleness",
+	"bounded":          "BoundedStaleness",
+	"session":          "Session",
+	"consistentprefix": "ConsistentPrefix",
+	"eventual":         "Eventual",
+}
+
+// _normalizeConsistencyLevel validates the consistency level name (case-insensitive) and returns the value expected
+// by Cosmos DB. Empty string is returned as-is, meaning the account's default consistency level.
+func _normalizeConsistencyLevel(level string) (string, error) {
+	level = strings.TrimSpace(level)
+	if level == "" {
+		return "", nil
+	}
+	if v, ok := consistencyLevels[strings.ToLower(level)]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("invalid consistency level <%s>, accepted values: Strong, BoundedStaleness, Session, ConsistentPrefix or Eventual", level)
+}
+
+// Config holds the settings to construct a RestClient or a driver.Connector without encoding them in a connection string.
+//
+// Use ParseConnectionString to build a Config from a connection string, or NewConfig to build one with default
+// values and then customize it. Prefer NewConfig over a Config literal: the zero value of some settings (e.g. AutoId)
+// differs from their default value.
+//
+// @Available since v1.2.0
+type Config struct {
+	// Endpoint is the endpoint to access Cosmos DB, e.g. https://localhost:8081/ (required).
+	Endpoint string
+	// AccountKey is the base64-encoded account key used to authenticate (required if Credential is nil).
+	AccountKey string
+	// Credential authorizes the requests sent to Cosmos DB, default is the AccountKeyCredential of AccountKey.
+	Credential Credential
+	// RequestHook, if not nil, intercepts the HTTP requests sent to Cosmos DB and their responses.
+	RequestHook RequestHook
+	// HttpClient is used to make REST calls if supplied. Timeout and InsecureSkipVerify are ignored in this case.
+	HttpClient *http.Client
+	// Timeout is the operation timeout, default value is 10 seconds.
+	Timeout time.Duration
+	// ApiVersion is the Cosmos DB REST API version, default value is DefaultApiVersion.
+	ApiVersion string
+	// AutoId, if true, makes CreateDocument generate a new id for documents that do not have one.
+	// Note: NewConfig and connection strings default to true, whereas the zero value of Config is false.
+	AutoId bool
+	// InsecureSkipVerify, if true, disables CA verification for https endpoint.
+	InsecureSkipVerify bool
+	// MaxRetries is the number of times a throttled request (status 429) is retried before the error is returned.
+	MaxRetries int
+	// DefaultDb is the default database used by database/sql connections.
+	DefaultDb string
+	// ConsistencyLevel is the default consistency level of read and query requests, empty means the account's default.
+	// Accepted values (case-insensitive): "", "Strong", "BoundedStaleness", "Session", "ConsistentPrefix" or "Eventual".
+	ConsistencyLevel string
+	// TrackSessionTokens, if true, makes the RestClient track session tokens returned by document operations and attach
+	// them to subsequent read/query requests, guaranteeing read-your-writes at Session consistency level.
+	// With database/sql, session tokens are tracked per connection regardless of this setting; enabling it shares the
+	// tracked tokens between all connections created by the same Connector.
+	TrackSessionTokens bool
+	// PreferredRegions lists the preferred Azure regions (e.g. "East US") in order of preference.
+	// If not empty, region routing is enabled: the regions of the account are discovered, reads are sent to the first
+	// available preferred region and writes to the write region, requests fail over to the next region if a region
+	// is unavailable.
+	PreferredRegions []string
+	// RegionRefreshInterval is the interval at which the regions of the account are re-discovered when region routing
+	// is enabled, default value is 5 minutes.
+	RegionRefreshInterval time.Duration
+}
+
+// NewConfig creates a new Config with the supplied endpoint and account key, other settings have default values.
+//
+// @Available since v1.2.0
+func NewConfig(endpoint, accountKey string) Config {
+	return Config{
+		Endpoint:   endpoint,
+		AccountKey: accountKey,
+		Timeout:    defaultTimeout,
+		ApiVersion: DefaultApiVersion,
+		AutoId:     true,
+	}
+}
+
+func _parseConnStr(connStr string) map[string]string {
+	params := make(map[string]string)
+	parts := strings.Split(connStr, ";")
+	for _, part := range parts {
+		tokens := strings.SplitN(part, "=", 2)
+		key := strings.ToUpper(strings.TrimSpace(tokens[0]))
+		if len(tokens) == 2 {
+			params[key] = strings.TrimSpace(tokens[1])
+		} else {
+			params[key] = ""
+		}
+	}
+	return params
+}
+
+// ParseConnectionString parses a connection string and returns the corresponding Config.
+//
+// See NewRestClient and Driver.Open for the connection string format.
+//
+// @Available since v1.2.0
+func ParseConnectionString(connStr string) (Config, error) {
+	return _configFromParams(_parseConnStr(connStr))
+}
+
+func _configFromParams(params map[string]string) (Config, error) {
+	cfg := NewConfig(strings.TrimSuffix(params[settingEndpoint], "/"), params[settingAccountKey])
+	if cfg.Endpoint == "" {
+		return cfg, errors.New("AccountEndpoint not found in connection string")
+	}
+	if cfg.AccountKey == "" {
+		return cfg, errors.New("AccountKey not found in connection string")
+	}
+	if timeoutMs, err := strconv.Atoi(params[settingTimeout]); err == nil && timeoutMs > 0 {
+		cfg.Timeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+	if apiVersion := params[settingVersion]; apiVersion != "" {
+		cfg.ApiVersion = apiVersion
+	}
+	if autoId, err := strconv.ParseBool(params[settingAutoId]); err == nil {
+		cfg.AutoId = autoId
+	}
+	if insecureSkipVerify, err := strconv.ParseBool(params[settingInsecureSkipVerify]); err == nil {
+		cfg.InsecureSkipVerify = insecureSkipVerify
+	}
+	if maxRetries, err := strconv.Atoi(params[settingMaxRetries]); err == nil && maxRetries > 0 {
+		cfg.MaxRetries = maxRetries
+	}
+	var ok bool
+	if cfg.DefaultDb, ok = params[settingDefaultDb]; !ok {
+		cfg.DefaultDb = params[settingDb]
+	}
+	for _, region := range strings.Split(params[settingRegions], ",") {
+		if region = strings.TrimSpace(region); region != "" {
+			cfg.PreferredRegions = append(cfg.PreferredRegions, region)
+		}
+	}
+	var err error
+	if cfg.ConsistencyLevel, err = _normalizeConsistencyLevel(params[settingConsistency]); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+func (cfg Config) validate() (Credential, error) {
+	if strings.TrimSpace(cfg.Endpoint) == "" {
+		return nil, errors.New("endpoint is missing")
+	}
+	credential := cfg.Credential
+	if credential == nil {
+		keyCredential, err := NewAccountKeyCredential(cfg.AccountKey)
+		if err != nil {
+			return nil, err
+		}
+		credential = keyCredential
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, fmt.Errorf("invalid MaxRetries value: %d", cfg.MaxRetries)
+	}
+	if _, err := _normalizeConsistencyLevel(cfg.ConsistencyLevel); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
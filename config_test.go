@@ -0,0 +1,80 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseConnectionString(t *testing.T) {
+	testName := "TestParseConnectionString"
+	testData := []struct {
+		name      string
+		connStr   string
+		expected  Config
+		mustError bool
+	}{
+		{name: "error_no_endpoint", connStr: "AccountKey=demo", mustError: true},
+		{name: "error_empty_endpoint", connStr: "AccountEndpoint;AccountKey=demo", mustError: true},
+		{name: "error_no_key", connStr: "AccountEndpoint=https://localhost:8081/", mustError: true},
+		{name: "error_invalid_consistency", connStr: "AccountEndpoint=https://localhost:8081/;AccountKey=demo;ConsistencyLevel=weak", mustError: true},
+
+		{name: "basic", connStr: "AccountEndpoint=https://localhost:8081/;AccountKey=demo",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 10 * time.Second, ApiVersion: DefaultApiVersion, AutoId: true}},
+		{name: "all_settings", connStr: "accountendpoint=https://localhost:8081;AccountKey=demo;TimeoutMs=1234;Version=2018-12-31;DefaultDb=mydb;AutoId=false;InsecureSkipVerify=true;MaxRetries=3",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 1234 * time.Millisecond, ApiVersion: "2018-12-31", DefaultDb: "mydb", InsecureSkipVerify: true, MaxRetries: 3}},
+		{name: "consistency", connStr: "AccountEndpoint=https://localhost:8081;AccountKey=demo;ConsistencyLevel=consistentprefix",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 10 * time.Second, ApiVersion: DefaultApiVersion, AutoId: true, ConsistencyLevel: "ConsistentPrefix"}},
+		{name: "preferred_regions", connStr: "AccountEndpoint=https://localhost:8081;AccountKey=demo;PreferredRegions=East US, West Europe,",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 10 * time.Second, ApiVersion: DefaultApiVersion, AutoId: true, PreferredRegions: []string{"East US", "West Europe"}}},
+		{name: "db_alias", connStr: "AccountEndpoint=https://localhost:8081;AccountKey=demo;Db=mydb",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 10 * time.Second, ApiVersion: DefaultApiVersion, AutoId: true, DefaultDb: "mydb"}},
+		{name: "invalid_values_ignored", connStr: "AccountEndpoint=https://localhost:8081;AccountKey=demo;TimeoutMs=-1;AutoId=abc;MaxRetries=-2",
+			expected: Config{Endpoint: "https://localhost:8081", AccountKey: "demo", Timeout: 10 * time.Second, ApiVersion: DefaultApiVersion, AutoId: true}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			cfg, err := ParseConnectionString(testCase.connStr)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if !reflect.DeepEqual(cfg, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, cfg)
+			}
+		})
+	}
+}
+
+func TestConfig_validate(t *testing.T) {
+	testName := "TestConfig_validate"
+	testData := []struct {
+		name      string
+		cfg       Config
+		mustError bool
+	}{
+		{name: "error_no_endpoint", cfg: NewConfig("", "ZGVtbw=="), mustError: true},
+		{name: "error_no_key", cfg: NewConfig("https://localhost:8081", ""), mustError: true},
+		{name: "error_invalid_key", cfg: NewConfig("https://localhost:8081", "demo/invalid_key"), mustError: true},
+		{name: "error_negative_retries", cfg: Config{Endpoint: "https://localhost:8081", AccountKey: "ZGVtbw==", MaxRetries: -1}, mustError: true},
+		{name: "error_invalid_consistency", cfg: Config{Endpoint: "https://localhost:8081", AccountKey: "ZGVtbw==", ConsistencyLevel: "weak"}, mustError: true},
+		{name: "valid", cfg: NewConfig("https://localhost:8081", "ZGVtbw==")},
+		{name: "valid_credential", cfg: Config{Endpoint: "https://localhost:8081", Credential: &AccountKeyCredential{}}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := testCase.cfg.validate()
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: validation must fail", testName+"/"+testCase.name)
+			}
+			if !testCase.mustError && err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+		})
+	}
+}
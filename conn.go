@@ -15,6 +15,19 @@ var (
 type Conn struct {
 	restClient *RestClient // Azure Cosmos DB REST API client.
 	defaultDb  string      // default database used in Cosmos DB operations.
+	dsnDb      string      // default database specified in the DSN, defaultDb is changed by USE statement.
+	closed     bool        // true if the connection has been closed.
+
+	// (since v1.2.0) session tokens tracked by this connection, nil if they are tracked by the REST client.
+	sessions *SessionContainer
+}
+
+func newConn(restClient *RestClient, defaultDb string) *Conn {
+	conn := &Conn{restClient: restClient, defaultDb: defaultDb, dsnDb: defaultDb}
+	if restClient.GetSessionContainer() == nil {
+		conn.sessions = NewSessionContainer()
+	}
+	return conn
 }
 
 // Prepare implements driver.Conn/Prepare.
@@ -31,9 +44,48 @@ func (c *Conn) PrepareContext(_ context.Context, query string) (driver.Stmt, err
 
 // Close implements driver.Conn/Close.
 func (c *Conn) Close() error {
+	c.closed = true
+	return nil
+}
+
+// Ping implements driver.Pinger/Ping.
+//
+// Ping makes a cheap authenticated call to the server: the default database is read if there is one, otherwise
+// the database account is read. It verifies both the endpoint and the account key.
+//
+// @Available since v1.2.0
+func (c *Conn) Ping(_ context.Context) error {
+	if c.closed {
+		return driver.ErrBadConn
+	}
+	if c.defaultDb != "" {
+		return c.restClient.GetDatabase(c.defaultDb).Error()
+	}
+	return c.restClient.GetDatabaseAccount().Error()
+}
+
+// ResetSession implements driver.SessionResetter/ResetSession.
+//
+// ResetSession is called by database/sql before a pooled connection is reused, it clears the state of the previous
+// session, including the session tokens tracked by the connection and the default database changed by USE statement.
+//
+// @Available since v1.2.0
+func (c *Conn) ResetSession(_ context.Context) error {
+	if c.closed {
+		return driver.ErrBadConn
+	}
+	c.defaultDb = c.dsnDb
+	c.sessions.Clear()
 	return nil
 }
 
+// IsValid implements driver.Validator/IsValid.
+//
+// @Available since v1.2.0
+func (c *Conn) IsValid() bool {
+	return !c.closed
+}
+
 // Begin implements driver.Conn/Begin.
 func (c *Conn) Begin() (driver.Tx, error) {
 	return c.BeginTx(context.Background(), driver.TxOptions{})
@@ -47,6 +99,8 @@ func (c *Conn) BeginTx(_ context.Context, _ driver.TxOptions) (driver.Tx, error)
 }
 
 // CheckNamedValue implements driver.NamedValueChecker/CheckNamedValue.
+//
+// Named values (sql.Named) are accepted since v1.2.0 and are bound to @name placeholders.
 func (c *Conn) CheckNamedValue(_ *driver.NamedValue) error {
 	// since Cosmos DB is document db, it accepts any value types
 	return nil
@@ -0,0 +1,119 @@
+package gocosmos
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Credential authorizes the requests sent to Cosmos DB.
+//
+// The default implementation (see NewAccountKeyCredential) signs requests with the account key. Other implementations,
+// e.g. a resource token or a Microsoft Entra ID token provider, can be supplied via Config.Credential.
+//
+// @Available since v1.2.0
+type Credential interface {
+	// Authorize sets the authorization headers of the request, which accesses the resource of type resType (e.g. "docs")
+	// identified by resId (e.g. "dbs/mydb/colls/mycoll/docs/mydoc"). The request is not sent if an error is returned.
+	//
+	// Authorize is called each time the request is sent, including when it is retried.
+	Authorize(req *http.Request, resType, resId string) error
+}
+
+// AccountKeyCredential is the Credential signing requests with the account key.
+//
+// @Available since v1.2.0
+type AccountKeyCredential struct {
+	key []byte // decoded account key
+}
+
+// NewAccountKeyCredential creates a new AccountKeyCredential from the base64-encoded account key.
+//
+// @Available since v1.2.0
+func NewAccountKeyCredential(accountKey string) (*AccountKeyCredential, error) {
+	if accountKey == "" {
+		return nil, errors.New("account key is missing")
+	}
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot base64 decode account key: %s", err)
+	}
+	return &AccountKeyCredential{key: key}, nil
+}
+
+// Authorize implements Credential/Authorize.
+//
+// @Available since v1.2.0
+func (c *AccountKeyCredential) Authorize(req *http.Request, resType, resId string) error {
+	now := time.Now().In(locGmt)
+	/*
+	 * M.A.I. 2022-02-16
+	 * The original statement had a single ToLower. In the resulting string the resId gets lowered when from MS Docs it should be left unaltered
+	 * I came across an error on a collection with a mixed case name...
+	 * stringToSign := strings.ToLower(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", method, resType, resId, now.Format(time.RFC1123), ""))
+	 */
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(req.Method), strings.ToLower(resType), resId, strings.ToLower(now.Format(time.RFC1123)), "")
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	authHeader := "type=master&ver=1.0&sig=" + signature
+	authHeader = url.QueryEscape(authHeader)
+	req.Header.Set(httpHeaderAuthorization, authHeader)
+	req.Header.Set(restApiHeaderDate, now.Format(time.RFC1123))
+	return nil
+}
+
+// RequestHook intercepts the HTTP requests sent to Cosmos DB, e.g. for logging, tracing or metrics.
+//
+// The hook is called with each (already authorized) request and the function sending it; it must call send (possibly
+// with a modified request) and return its result, or return an error to abort the request.
+// Retried requests are passed to the hook each time they are sent.
+//
+// @Available since v1.2.0
+type RequestHook func(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error)
+
+// restResourceKey is the context key of the resource accessed by a request, see RestClient.addAuthHeader.
+type restResourceKey struct{}
+
+// restResource identifies the resource accessed by a request, to be authorized by the Credential.
+type restResource struct {
+	resType, resId string
+}
+
+// restTransport is the http.RoundTripper of a RestClient: it authorizes requests with the credential and passes them
+// through the request hook before sending them via the underlying transport.
+type restTransport struct {
+	base       http.RoundTripper
+	credential Credential
+	hook       RequestHook
+}
+
+// RoundTrip implements http.RoundTripper/RoundTrip.
+func (t *restTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if res, ok := req.Context().Value(restResourceKey{}).(restResource); ok {
+		// a RoundTripper must not modify the supplied request
+		req = req.Clone(req.Context())
+		if err := t.credential.Authorize(req, res.resType, res.resId); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, err
+		}
+	}
+	if t.hook != nil {
+		return t.hook(req, t.base.RoundTrip)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// _withResource records in the request the resource it accesses, so that the request is authorized when it is sent.
+func _withResource(req *http.Request, resType, resId string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), restResourceKey{}, restResource{resType: resType, resId: resId}))
+}
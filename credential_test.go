@@ -0,0 +1,105 @@
+package gocosmos
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNewAccountKeyCredential(t *testing.T) {
+	testName := "TestNewAccountKeyCredential"
+	if _, err := NewAccountKeyCredential(""); err == nil {
+		t.Fatalf("%s failed: empty key must be rejected", testName)
+	}
+	if _, err := NewAccountKeyCredential("demo/invalid_key"); err == nil {
+		t.Fatalf("%s failed: invalid key must be rejected", testName)
+	}
+	credential, err := NewAccountKeyCredential("ZGVtbw==")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	req, _ := http.NewRequest("GET", "https://localhost:8081/dbs/mydb", nil)
+	if err := credential.Authorize(req, "dbs", "dbs/mydb"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if auth := req.Header.Get(httpHeaderAuthorization); !strings.HasPrefix(auth, "type%3Dmaster%26ver%3D1.0%26sig%3D") {
+		t.Fatalf("%s failed: unexpected authorization header %q", testName, auth)
+	}
+	if req.Header.Get(restApiHeaderDate) == "" {
+		t.Fatalf("%s failed: date header is missing", testName)
+	}
+}
+
+type testCredential struct {
+	numCalls int32
+	err      error
+}
+
+func (c *testCredential) Authorize(req *http.Request, resType, resId string) error {
+	atomic.AddInt32(&c.numCalls, 1)
+	req.Header.Set(httpHeaderAuthorization, "test "+resType+" "+resId)
+	return c.err
+}
+
+func TestRestClient_credentialAndHook(t *testing.T) {
+	testName := "TestRestClient_credentialAndHook"
+	var numRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(httpHeaderAuthorization) != "test dbs dbs/mydb" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if atomic.AddInt32(&numRequests, 1) == 1 {
+			w.Header().Set(respHeaderRetryAfterMs, "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"mydb","_rid":"rid","_ts":1,"_self":"dbs/rid/","_etag":"\"etag\""}`))
+	}))
+	defer server.Close()
+
+	credential := &testCredential{}
+	var hookStatuses []int
+	cfg := NewConfig(server.URL, "")
+	cfg.Credential = credential
+	cfg.MaxRetries = 1
+	cfg.RequestHook = func(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+		resp, err := send(req)
+		if err == nil {
+			hookStatuses = append(hookStatuses, resp.StatusCode)
+		}
+		return resp, err
+	}
+	client, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	result := client.GetDatabase("mydb")
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if result.Id != "mydb" {
+		t.Fatalf("%s failed: expected database %q but received %q", testName, "mydb", result.Id)
+	}
+	if n := atomic.LoadInt32(&credential.numCalls); n != 2 {
+		t.Fatalf("%s failed: expected the credential to be called %d times but received %d", testName, 2, n)
+	}
+	if len(hookStatuses) != 2 || hookStatuses[0] != http.StatusTooManyRequests || hookStatuses[1] != http.StatusOK {
+		t.Fatalf("%s failed: unexpected statuses seen by the hook %v", testName, hookStatuses)
+	}
+
+	// a request that cannot be authorized is not sent
+	credential.err = errors.New("no token")
+	atomic.StoreInt32(&numRequests, 0)
+	result = client.GetDatabase("mydb")
+	if err := result.Error(); err == nil || !strings.Contains(err.Error(), "no token") {
+		t.Fatalf("%s failed: expected the credential error but received %v", testName, err)
+	}
+	if n := atomic.LoadInt32(&numRequests); n != 0 {
+		t.Fatalf("%s failed: expected no request to be sent but received %d", testName, n)
+	}
+}
@@ -1,11 +1,14 @@
 package gocosmos
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"time"
 
@@ -88,6 +91,35 @@ var (
 	ErrQueryNotSupported = errors.New("this operation is not supported, please use Exec")
 )
 
+// BulkWriteError is returned by statements that write multiple documents (e.g. multi-row INSERT) when some of the
+// documents could not be written. Other documents have been written successfully.
+//
+// @Available since v1.2.0
+type BulkWriteError struct {
+	NumRows      int           // number of documents the statement attempted to write
+	NumSucceeded int           // number of documents written successfully
+	RowErrors    map[int]error // errors of failed documents, keyed by row index (0-based)
+}
+
+// Error implements error/Error.
+func (e *BulkWriteError) Error() string {
+	rows := make([]int, 0, len(e.RowErrors))
+	for row := range e.RowErrors {
+		rows = append(rows, row)
+	}
+	sort.Ints(rows)
+	const maxReportedRows = 5
+	details := make([]string, 0, maxReportedRows+1)
+	for i, row := range rows {
+		if i >= maxReportedRows {
+			details = append(details, fmt.Sprintf("and %d more", len(rows)-maxReportedRows))
+			break
+		}
+		details = append(details, fmt.Sprintf("row #%d: %s", row, e.RowErrors[row]))
+	}
+	return fmt.Sprintf("%d of %d rows failed (%s)", len(e.RowErrors), e.NumRows, strings.Join(details, "; "))
+}
+
 // Driver is Azure Cosmos DB implementation of driver.Driver.
 type Driver struct {
 }
@@ -96,21 +128,70 @@ type Driver struct {
 //
 // connStr is expected in the following format:
 //
-//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;DefaultDb=<db-name>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>]
+//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;DefaultDb=<db-name>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>][;MaxRetries=<num-retries>][;ConsistencyLevel=<consistency-level>][;PreferredRegions=<region1>,<region2>...]
 //
-// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, and InsecureSkipVerify is false
+// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, InsecureSkipVerify is false and MaxRetries is 0
 //
 // - DefaultDb is added since v0.1.1
 // - AutoId is added since v0.1.2
 // - InsecureSkipVerify is added since v0.1.4
+// - MaxRetries is added since v1.2.0
+// - ConsistencyLevel is added since v1.2.0: default consistency level of read and query requests, one of Strong, BoundedStaleness, Session, ConsistentPrefix or Eventual
+// - PreferredRegions is added since v1.2.0: comma-separated list of preferred regions, enables region routing (see Config.PreferredRegions)
 func (d *Driver) Open(connStr string) (driver.Conn, error) {
-	restClient, err := NewRestClient(nil, connStr)
+	cfg, err := ParseConnectionString(connStr)
 	if err != nil {
 		return nil, err
 	}
-	defaultDb, ok := restClient.params["DEFAULTDB"]
-	if !ok {
-		defaultDb = restClient.params["DB"]
+	restClient, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-	return &Conn{restClient: restClient, defaultDb: defaultDb}, nil
+	return newConn(restClient, cfg.DefaultDb), nil
+}
+
+// OpenConnector implements driver.DriverContext/OpenConnector.
+//
+// See Open for the format of connStr. The connection string is parsed when a connection is established, so that
+// errors are reported by sql.DB operations (e.g. Ping) as with Open.
+//
+// @Available since v1.2.0
+func (d *Driver) OpenConnector(connStr string) (driver.Connector, error) {
+	return &Connector{driver: d, connStr: connStr}, nil
+}
+
+// NewConnector creates a driver.Connector from the supplied Config, to be used with sql.OpenDB.
+//
+// Connections created by the returned connector share the same RestClient (and its http.Client).
+//
+// @Available since v1.2.0
+func NewConnector(cfg Config) (*Connector, error) {
+	restClient, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Connector{driver: &Driver{}, restClient: restClient, defaultDb: cfg.DefaultDb}, nil
+}
+
+// Connector is Azure Cosmos DB implementation of driver.Connector.
+//
+// @Available since v1.2.0
+type Connector struct {
+	driver     *Driver
+	connStr    string      // connection string, used if restClient is nil
+	restClient *RestClient // REST client shared by all connections
+	defaultDb  string      // default database used in Cosmos DB operations
+}
+
+// Connect implements driver.Connector/Connect.
+func (c *Connector) Connect(_ context.Context) (driver.Conn, error) {
+	if c.restClient == nil {
+		return c.driver.Open(c.connStr)
+	}
+	return newConn(c.restClient, c.defaultDb), nil
+}
+
+// Driver implements driver.Connector/Driver.
+func (c *Connector) Driver() driver.Driver {
+	return c.driver
 }
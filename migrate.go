@@ -0,0 +1,221 @@
+package gocosmos
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMigrationCollection is the name of the collection that records applied migrations, used by NewMigrator if no
+// collection name is specified.
+//
+// @Available since v1.2.0
+const DefaultMigrationCollection = "gocosmos_migrations"
+
+// Migration is a versioned script of gocosmos statements (see ExecScript), applied by a Migrator.
+//
+// @Available since v1.2.0
+type Migration struct {
+	Version int64  // version of the migration, migrations are applied in ascending order of versions
+	Name    string // short description of the migration, e.g. "create_users"
+	Script  string // gocosmos statements, separated by ";"
+}
+
+// Checksum returns the checksum of the migration's script, which is recorded when the migration is applied.
+//
+// @Available since v1.2.0
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Script))
+	return hex.EncodeToString(sum[:])
+}
+
+// AppliedMigration holds the information of a migration that has been applied, as recorded by a Migrator.
+//
+// @Available since v1.2.0
+type AppliedMigration struct {
+	Version   int64     // version of the migration
+	Name      string    // name of the migration
+	Checksum  string    // checksum of the migration's script when it was applied
+	AppliedAt time.Time // time when the migration was applied
+}
+
+var reMigrationFile = regexp.MustCompile(`^(\d+)(?:[_-](.*))?\.sql$`)
+
+// LoadMigrations loads migrations from the *.sql files in the root directory of fsys (e.g. os.DirFS("migrations") or an
+// embed.FS). Each file is a migration, its name is in format "<version>_<name>.sql", e.g. "0001_create_users.sql".
+// The returned migrations are sorted by version.
+//
+// @Available since v1.2.0
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Migration, 0, len(files))
+	for _, file := range files {
+		groups := reMigrationFile.FindStringSubmatch(path.Base(file))
+		if groups == nil {
+			return nil, fmt.Errorf("invalid migration file name <%s>, expected format <version>_<name>.sql", file)
+		}
+		version, err := strconv.ParseInt(groups[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version of migration file <%s>: %s", file, err)
+		}
+		script, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Migration{Version: version, Name: groups[2], Script: string(script)})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Migrator applies migrations to a Cosmos DB account, and records the applied ones in a tracking collection so that
+// each migration is applied only once.
+//
+// @Available since v1.2.0
+type Migrator struct {
+	db       *sql.DB
+	dbName   string // database of the tracking collection
+	collName string // name of the tracking collection
+}
+
+// NewMigrator creates a new Migrator that records applied migrations in the collection collName of database dbName.
+// DefaultMigrationCollection is used if collName is empty. The database and collection are created if they do not
+// exist.
+//
+// @Available since v1.2.0
+func NewMigrator(db *sql.DB, dbName, collName string) *Migrator {
+	if collName == "" {
+		collName = DefaultMigrationCollection
+	}
+	return &Migrator{db: db, dbName: dbName, collName: collName}
+}
+
+// _quoteIdentifier quotes a name with backticks so that it can be used in a statement whatever characters it has.
+func _quoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (m *Migrator) init(ctx context.Context) error {
+	if _, err := m.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+_quoteIdentifier(m.dbName)); err != nil {
+		return err
+	}
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf("CREATE COLLECTION IF NOT EXISTS %s.%s WITH pk=/id",
+		_quoteIdentifier(m.dbName), _quoteIdentifier(m.collName)))
+	return err
+}
+
+// Applied returns the migrations that have been applied, sorted by version.
+//
+// @Available since v1.2.0
+func (m *Migrator) Applied(ctx context.Context) ([]AppliedMigration, error) {
+	if err := m.init(ctx); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf("SELECT CROSS PARTITION c.id, c.name, c.checksum, c.appliedAt FROM c WITH db=%s WITH collection=%s",
+		_quoteIdentifier(m.dbName), _quoteIdentifier(m.collName))
+	rows, err := m.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]AppliedMigration, 0)
+	for rows.Next() {
+		vals := make([]interface{}, len(cols))
+		scanVals := make([]interface{}, len(cols))
+		for i := range vals {
+			scanVals[i] = &vals[i]
+		}
+		if err := rows.Scan(scanVals...); err != nil {
+			return nil, err
+		}
+		row := make(map[string]string, len(cols))
+		for i, col := range cols {
+			if vals[i] != nil {
+				row[col] = fmt.Sprintf("%v", vals[i])
+			}
+		}
+		version, err := strconv.ParseInt(row["id"], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration record <%s> in collection %s: %s", row["id"], m.collName, err)
+		}
+		appliedAt, _ := time.Parse(time.RFC3339, row["appliedAt"])
+		result = append(result, AppliedMigration{Version: version, Name: row["name"], Checksum: row["checksum"], AppliedAt: appliedAt})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// Migrate applies the migrations that have not been applied yet, in ascending order of versions, and returns the
+// migrations applied by this call.
+//
+// Each migration's script is executed via ExecScript, then the migration is recorded in the tracking collection.
+// Migrate stops at the first failed migration; since a failed migration is not recorded, it is executed again (from
+// its first statement) by the next call, hence statements should be idempotent (e.g. CREATE COLLECTION IF NOT EXISTS).
+//
+// An error is returned, before any migration is applied, if versions are duplicated or if the script of an applied
+// migration has been changed since it was applied. Migrators running concurrently are not coordinated: the same
+// migration may be executed by both of them, but only one can record it (the other one fails with ErrConflict).
+//
+// @Available since v1.2.0
+func (m *Migrator) Migrate(ctx context.Context, migrations []Migration) ([]Migration, error) {
+	applied, err := m.Applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	appliedMap := make(map[int64]AppliedMigration, len(applied))
+	for _, a := range applied {
+		appliedMap[a.Version] = a
+	}
+
+	pending := make([]Migration, 0, len(migrations))
+	versions := make(map[int64]bool, len(migrations))
+	for _, migration := range migrations {
+		if versions[migration.Version] {
+			return nil, fmt.Errorf("migration version %d is duplicated", migration.Version)
+		}
+		versions[migration.Version] = true
+		if a, ok := appliedMap[migration.Version]; ok {
+			if a.Checksum != migration.Checksum() {
+				return nil, fmt.Errorf("migration %d (%s) has been changed since it was applied", migration.Version, migration.Name)
+			}
+			continue
+		}
+		pending = append(pending, migration)
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	insertQuery := fmt.Sprintf("INSERT INTO %s.%s (id, name, checksum, appliedAt) VALUES (:1, :2, :3, :4) WITH pk=/id",
+		_quoteIdentifier(m.dbName), _quoteIdentifier(m.collName))
+	result := make([]Migration, 0, len(pending))
+	for _, migration := range pending {
+		if _, err := ExecScript(ctx, m.db, migration.Script); err != nil {
+			return result, fmt.Errorf("migration %d (%s) failed: %w", migration.Version, migration.Name, err)
+		}
+		_, err := m.db.ExecContext(ctx, insertQuery, strconv.FormatInt(migration.Version, 10), migration.Name,
+			migration.Checksum(), time.Now().UTC().Format(time.RFC3339))
+		if err != nil {
+			return result, fmt.Errorf("cannot record migration %d (%s): %w", migration.Version, migration.Name, err)
+		}
+		result = append(result, migration)
+	}
+	return result, nil
+}
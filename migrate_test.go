@@ -0,0 +1,45 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	testName := "TestLoadMigrations"
+	fsys := fstest.MapFS{
+		"0010_add_index.sql":    {Data: []byte("ALTER COLLECTION db.users WITH ru=800")},
+		"0002_create_users.sql": {Data: []byte("CREATE COLLECTION db.users WITH pk=/id")},
+		"1-create_db.sql":       {Data: []byte("CREATE DATABASE db")},
+		"README.md":             {Data: []byte("migrations of db")},
+		"sub/0003_ignored.sql":  {Data: []byte("DROP DATABASE db")},
+	}
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	expected := []Migration{
+		{Version: 1, Name: "create_db", Script: "CREATE DATABASE db"},
+		{Version: 2, Name: "create_users", Script: "CREATE COLLECTION db.users WITH pk=/id"},
+		{Version: 10, Name: "add_index", Script: "ALTER COLLECTION db.users WITH ru=800"},
+	}
+	if !reflect.DeepEqual(migrations, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, migrations)
+	}
+
+	fsys["create_orders.sql"] = &fstest.MapFile{Data: []byte("CREATE COLLECTION db.orders WITH pk=/id")}
+	if _, err := LoadMigrations(fsys); err == nil {
+		t.Fatalf("%s failed: expected error for file without version", testName)
+	}
+}
+
+func TestMigration_Checksum(t *testing.T) {
+	testName := "TestMigration_Checksum"
+	m1 := Migration{Version: 1, Script: "CREATE DATABASE db"}
+	m2 := Migration{Version: 2, Name: "other", Script: "CREATE DATABASE db"}
+	m3 := Migration{Version: 1, Script: "CREATE DATABASE db2"}
+	if m1.Checksum() != m2.Checksum() || m1.Checksum() == m3.Checksum() || len(m1.Checksum()) != 64 {
+		t.Fatalf("%s failed: %s / %s / %s", testName, m1.Checksum(), m2.Checksum(), m3.Checksum())
+	}
+}
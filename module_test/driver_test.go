@@ -3,8 +3,11 @@ package gocosmos_test
 import (
 	"context"
 	"database/sql"
+	"os"
 	"strings"
 	"testing"
+
+	"github.com/microsoft/gocosmos"
 )
 
 func TestDriver_invalidConnectionString(t *testing.T) {
@@ -87,3 +90,72 @@ func TestDriver_Close(t *testing.T) {
 		t.Fatalf("%s failed: %s", testName, err)
 	}
 }
+
+func TestNewConnector_invalidConfig(t *testing.T) {
+	testName := "TestNewConnector_invalidConfig"
+	if _, err := gocosmos.NewConnector(gocosmos.NewConfig("", "ZGVtbw==")); err == nil {
+		t.Fatalf("%s failed: should have error", testName)
+	}
+	if _, err := gocosmos.NewConnector(gocosmos.NewConfig("https://localhost:8081", "demo/invalid_key")); err == nil {
+		t.Fatalf("%s failed: should have error", testName)
+	}
+}
+
+func TestNewConnector(t *testing.T) {
+	testName := "TestNewConnector"
+	url := strings.ReplaceAll(os.Getenv("COSMOSDB_URL"), `"`, "")
+	if url == "" {
+		t.Skipf("%s skipped", testName)
+	}
+	cfg, err := gocosmos.ParseConnectionString(url)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/ParseConnectionString", err)
+	}
+	connector, err := gocosmos.NewConnector(cfg)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/NewConnector", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if _, err := db.Query("LIST DATABASES"); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+}
+
+func TestDriver_Ping_invalidKey(t *testing.T) {
+	testName := "TestDriver_Ping_invalidKey"
+	url := strings.ReplaceAll(os.Getenv("COSMOSDB_URL"), `"`, "")
+	if url == "" {
+		t.Skipf("%s skipped", testName)
+	}
+	cfg, err := gocosmos.ParseConnectionString(url)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/ParseConnectionString", err)
+	}
+	cfg.AccountKey = "ZGVtbw=="
+	connector, err := gocosmos.NewConnector(cfg)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/NewConnector", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	if err := db.Ping(); err == nil {
+		t.Fatalf("%s failed: should have error", testName)
+	}
+}
+
+func TestDriver_Conn_Ping(t *testing.T) {
+	testName := "TestDriver_Conn_Ping"
+	db := _openDefaultDb(t, testName, "db_not_exists")
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer conn.Close()
+	if err := conn.PingContext(context.Background()); err == nil {
+		t.Fatalf("%s failed: default database does not exist, should have error", testName)
+	}
+}
@@ -2,7 +2,9 @@ package gocosmos_test
 
 import (
 	"github.com/microsoft/gocosmos"
+	"strings"
 	"testing"
+	"time"
 )
 
 /*----------------------------------------------------------------------*/
@@ -210,6 +212,41 @@ func TestRestClient_ChangeOfferCollection(t *testing.T) {
 	}
 }
 
+func TestRestClient_MigrateOfferCollection(t *testing.T) {
+	name := "TestRestClient_MigrateOfferCollection"
+	client := _newRestClient(t, name)
+
+	dbname := testDb
+	collname := testTable
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: dbname})
+	_deleteCollection(client, dbname, collname)
+	collspec := gocosmos.CollectionSpec{DbName: dbname, CollName: collname, Ru: 400, PartitionKeyInfo: map[string]interface{}{"paths": []string{"/id"}, "kind": "Hash"}}
+	createResult := client.CreateCollection(collspec)
+	if createResult.Error() != nil {
+		t.Fatalf("%s failed: %s", name, createResult.Error())
+	}
+	rid := createResult.Rid
+
+	for _, toAutoscale := range []bool{true, false} {
+		var result *gocosmos.RespReplaceOffer
+		if toAutoscale {
+			result = client.MigrateOfferToAutoscale(rid)
+		} else {
+			result = client.MigrateOfferToManual(rid)
+		}
+		if err := result.Error(); err != nil {
+			t.Fatalf("%s failed: %s", name+"/migrate", err)
+		}
+		waitResult := client.WaitForOfferReplace(rid, time.Second, time.Minute)
+		if err := waitResult.Error(); err != nil {
+			t.Fatalf("%s failed: %s", name+"/WaitForOfferReplace", err)
+		}
+		if auto := waitResult.IsAutopilot(); auto != toAutoscale {
+			t.Fatalf("%s failed: <auto> expected %#v but received %#v", name, toAutoscale, auto)
+		}
+	}
+}
+
 func TestRestClient_ChangeOfferCollectionInvalid(t *testing.T) {
 	name := "TestRestClient_ChangeOfferCollectionInvalid"
 	client := _newRestClient(t, name)
@@ -260,6 +297,108 @@ func TestRestClient_CreateCollectionIndexingPolicy(t *testing.T) {
 	}
 }
 
+func TestRestClient_CollectionDefaultTtl(t *testing.T) {
+	name := "TestRestClient_CollectionDefaultTtl"
+	client := _newRestClient(t, name)
+
+	dbname := testDb
+	collname := testTable
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: dbname})
+	_deleteCollection(client, dbname, collname)
+	pkInfo := map[string]interface{}{"paths": []string{"/id"}, "kind": "Hash"}
+	if result := client.CreateCollection(gocosmos.CollectionSpec{DbName: dbname, CollName: collname, PartitionKeyInfo: pkInfo, DefaultTtl: 3600}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateCollection", result.Error())
+	} else if result.DefaultTtl != 3600 {
+		t.Fatalf("%s failed: <default-ttl> expected %#v but received %#v", name+"/CreateCollection", 3600, result.DefaultTtl)
+	}
+	if result := client.ReplaceCollection(gocosmos.CollectionSpec{DbName: dbname, CollName: collname, PartitionKeyInfo: pkInfo, DefaultTtl: -1}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/ReplaceCollection", result.Error())
+	} else if result.DefaultTtl != -1 {
+		t.Fatalf("%s failed: <default-ttl> expected %#v but received %#v", name+"/ReplaceCollection", -1, result.DefaultTtl)
+	}
+	if result := client.ReplaceCollection(gocosmos.CollectionSpec{DbName: dbname, CollName: collname, PartitionKeyInfo: pkInfo}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/ReplaceCollection", result.Error())
+	} else if result.DefaultTtl != 0 {
+		t.Fatalf("%s failed: <default-ttl> expected %#v but received %#v", name+"/ReplaceCollection", 0, result.DefaultTtl)
+	}
+}
+
+func TestRestClient_CollectionConflictResolutionPolicy(t *testing.T) {
+	name := "TestRestClient_CollectionConflictResolutionPolicy"
+	client := _newRestClient(t, name)
+
+	dbname := testDb
+	collname := testTable
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: dbname})
+	pkInfo := map[string]interface{}{"paths": []string{"/id"}, "kind": "Hash"}
+	testData := []struct {
+		name     string
+		policy   map[string]interface{}
+		mode     string
+		path     string
+		sprocSfx string
+	}{
+		{name: "lww_default", policy: gocosmos.LwwConflictResolutionPolicy(""), mode: "LastWriterWins", path: "/_ts"},
+		{name: "lww_path", policy: gocosmos.LwwConflictResolutionPolicy("/version"), mode: "LastWriterWins", path: "/version"},
+		{name: "custom_manual", policy: gocosmos.CustomConflictResolutionPolicy(dbname, collname, ""), mode: "Custom"},
+		{name: "custom_sproc", policy: gocosmos.CustomConflictResolutionPolicy(dbname, collname, "resolver"), mode: "Custom", sprocSfx: "/sprocs/resolver"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			_deleteCollection(client, dbname, collname)
+			result := client.CreateCollection(gocosmos.CollectionSpec{DbName: dbname, CollName: collname, PartitionKeyInfo: pkInfo, ConflictResolutionPolicy: testCase.policy})
+			if result.Error() != nil {
+				t.Fatalf("%s failed: %s", name+"/"+testCase.name, result.Error())
+			}
+			policy := result.ConflictResolutionPolicy
+			if policy["mode"] != testCase.mode {
+				t.Fatalf("%s failed: <mode> expected %#v but received %#v", name+"/"+testCase.name, testCase.mode, policy["mode"])
+			}
+			if testCase.path != "" && policy["conflictResolutionPath"] != testCase.path {
+				t.Fatalf("%s failed: <path> expected %#v but received %#v", name+"/"+testCase.name, testCase.path, policy["conflictResolutionPath"])
+			}
+			if sproc, _ := policy["conflictResolutionProcedure"].(string); !strings.HasSuffix(sproc, testCase.sprocSfx) || (testCase.sprocSfx == "" && sproc != "") {
+				t.Fatalf("%s failed: <sproc> expected suffix %#v but received %#v", name+"/"+testCase.name, testCase.sprocSfx, sproc)
+			}
+		})
+	}
+}
+
+func TestRestClient_Conflicts(t *testing.T) {
+	name := "TestRestClient_Conflicts"
+	client := _newRestClient(t, name)
+
+	dbname := testDb
+	collname := testTable
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: dbname})
+	_deleteCollection(client, dbname, collname)
+	pkInfo := map[string]interface{}{"paths": []string{"/id"}, "kind": "Hash"}
+	if result := client.CreateCollection(gocosmos.CollectionSpec{DbName: dbname, CollName: collname, PartitionKeyInfo: pkInfo,
+		ConflictResolutionPolicy: gocosmos.CustomConflictResolutionPolicy(dbname, collname, "")}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateCollection", result.Error())
+	}
+
+	// conflicts only occur with multi-region writes: the feed of a freshly created collection is empty
+	result := client.ListConflicts(gocosmos.ListConflictsReq{DbName: dbname, CollName: collname})
+	if result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/ListConflicts", result.Error())
+	}
+	if result.Count != 0 || len(result.Conflicts) != 0 {
+		t.Fatalf("%s failed: expected empty conflicts feed but received %#v", name+"/ListConflicts", result.Conflicts)
+	}
+	if result := client.ListConflicts(gocosmos.ListConflictsReq{DbName: dbname, CollName: "not_exists"}); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", name+"/ListConflicts", result.StatusCode)
+	}
+
+	req := gocosmos.ConflictReq{DbName: dbname, CollName: collname, ConflictId: "not_exists", PartitionKeyValues: []interface{}{"1"}}
+	if result := client.GetConflict(req); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", name+"/GetConflict", result.StatusCode)
+	}
+	if result := client.DeleteConflict(req); result.StatusCode != 404 {
+		t.Fatalf("%s failed: expected status 404 but received %d", name+"/DeleteConflict", result.StatusCode)
+	}
+}
+
 func TestRestClient_ReplaceCollection(t *testing.T) {
 	name := "TestRestClient_ReplaceCollection"
 	client := _newRestClient(t, name)
@@ -518,3 +518,138 @@ func TestRestClient_DeleteDocument(t *testing.T) {
 		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name, 404, result.StatusCode)
 	}
 }
+
+func TestRestClient_DocumentAttachments(t *testing.T) {
+	name := "TestRestClient_DocumentAttachments"
+	client := _newRestClient(t, name)
+
+	dbname := testDb
+	collname := testTable
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: dbname})
+	_ensureCollection(client, gocosmos.CollectionSpec{
+		DbName:           dbname,
+		CollName:         collname,
+		PartitionKeyInfo: map[string]interface{}{"paths": []string{"/username"}, "kind": "Hash"},
+	})
+	pk := []interface{}{"user"}
+	if result := client.CreateDocument(gocosmos.DocumentSpec{DbName: dbname, CollName: collname, PartitionKeyValues: pk,
+		DocumentData: map[string]interface{}{"id": "1", "username": "user"}}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name, result.Error())
+	}
+
+	var etag string
+	spec := gocosmos.AttachmentSpec{DbName: dbname, CollName: collname, DocId: "1", PartitionKeyValues: pk,
+		Id: "external", ContentType: "image/png", MediaLink: "https://localhost/image.png"}
+	if result := client.CreateAttachment(spec); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateAttachment", result.Error())
+	} else if result.Id != "external" || result.Media != spec.MediaLink || result.IsInlineMedia() || result.Etag == "" {
+		t.Fatalf("%s failed: invalid attachment returned %#v", name+"/CreateAttachment", result.AttachmentInfo)
+	} else {
+		etag = result.Etag
+	}
+	if result := client.CreateAttachment(spec); result.CallErr != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateAttachment", result.CallErr)
+	} else if result.StatusCode != 409 {
+		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name+"/CreateAttachment", 409, result.StatusCode)
+	}
+
+	spec.ContentType, spec.MediaLink = "image/jpeg", "https://localhost/image.jpg"
+	if result := client.ReplaceAttachment(etag+"dummy", spec); result.CallErr != nil {
+		t.Fatalf("%s failed: %s", name+"/ReplaceAttachment", result.CallErr)
+	} else if result.StatusCode != 412 {
+		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name+"/ReplaceAttachment", 412, result.StatusCode)
+	}
+	if result := client.ReplaceAttachment(etag, spec); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/ReplaceAttachment", result.Error())
+	} else if result.ContentType != "image/jpeg" || result.Media != spec.MediaLink {
+		t.Fatalf("%s failed: invalid attachment returned %#v", name+"/ReplaceAttachment", result.AttachmentInfo)
+	}
+
+	req := gocosmos.AttachmentReq{DbName: dbname, CollName: collname, DocId: "1", AttachmentId: "external", PartitionKeyValues: pk}
+	if result := client.GetAttachment(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/GetAttachment", result.Error())
+	} else if result.Id != "external" || result.Media != spec.MediaLink {
+		t.Fatalf("%s failed: invalid attachment returned %#v", name+"/GetAttachment", result.AttachmentInfo)
+	}
+	if result := client.GetAttachmentMedia(req); result.CallErr == nil {
+		t.Fatalf("%s failed: fetching external media must fail", name+"/GetAttachmentMedia")
+	}
+
+	// inline media is not supported by all Cosmos DB deployments (e.g. it has been deprecated on new accounts)
+	media := []byte("gocosmos attachment media")
+	inlineSupported := false
+	if result := client.CreateAttachment(gocosmos.AttachmentSpec{DbName: dbname, CollName: collname, DocId: "1", PartitionKeyValues: pk,
+		Id: "inline", ContentType: "text/plain", Media: media}); result.CallErr != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateAttachment/inline", result.CallErr)
+	} else if result.StatusCode == 400 || result.StatusCode == 403 {
+		t.Logf("%s: inline media not supported, status %d", name, result.StatusCode)
+	} else if result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/CreateAttachment/inline", result.Error())
+	} else if !result.IsInlineMedia() {
+		t.Fatalf("%s failed: invalid attachment returned %#v", name+"/CreateAttachment/inline", result.AttachmentInfo)
+	} else {
+		inlineSupported = true
+		req.AttachmentId = "inline"
+		if result := client.GetAttachmentMedia(req); result.Error() != nil {
+			t.Fatalf("%s failed: %s", name+"/GetAttachmentMedia", result.Error())
+		} else if string(result.Media) != string(media) || !strings.HasPrefix(result.ContentType, "text/plain") {
+			t.Fatalf("%s failed: received %#v / %#v", name+"/GetAttachmentMedia", result.ContentType, string(result.Media))
+		}
+	}
+
+	expectedCount := 1
+	if inlineSupported {
+		expectedCount = 2
+	}
+	if result := client.ListAttachments(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/ListAttachments", result.Error())
+	} else if result.Count != expectedCount || len(result.Attachments) != expectedCount || result.Attachments[0].Id != "external" {
+		t.Fatalf("%s failed: received %#v", name+"/ListAttachments", result.Attachments)
+	}
+
+	req.AttachmentId = "external"
+	if result := client.DeleteAttachment(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", name+"/DeleteAttachment", result.Error())
+	}
+	if result := client.GetAttachment(req); result.CallErr != nil {
+		t.Fatalf("%s failed: %s", name+"/GetAttachment", result.CallErr)
+	} else if result.StatusCode != 404 {
+		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name+"/GetAttachment", 404, result.StatusCode)
+	}
+}
+
+func TestRestClient_ReadMany(t *testing.T) {
+	name := "TestRestClient_ReadMany"
+	client := _newRestClient(t, name)
+	dbname := testDb
+	collname := testTable
+	_initDataLargeRU(t, name, client, dbname, collname, 100)
+
+	items := make([]gocosmos.ItemIdentity, 0)
+	for _, i := range []int{42, 7, 13, 99, 0} {
+		items = append(items, gocosmos.ItemIdentity{Id: dataList[i].Id(), PartitionKeyValues: []interface{}{dataList[i]["username"]}})
+	}
+	items = append(items, gocosmos.ItemIdentity{Id: "not-found", PartitionKeyValues: []interface{}{"user0"}})
+	items = append(items, gocosmos.ItemIdentity{Id: dataList[1].Id(), PartitionKeyValues: []interface{}{"wrong-user"}})
+	result := client.ReadMany(gocosmos.ReadManyReq{DbName: dbname, CollName: collname, Items: items})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if len(result.Documents) != 5 || len(result.NotFound) != 2 || result.RequestCharge <= 0 {
+		t.Fatalf("%s failed: received %d documents, not found %#v, request charge %f", name, len(result.Documents), result.NotFound, result.RequestCharge)
+	}
+	for i, doc := range result.Documents {
+		if doc.Id() != items[i].Id || doc["username"] != items[i].PartitionKeyValues[0] {
+			t.Fatalf("%s failed: document #%d expected id %#v but received %#v", name, i, items[i].Id, doc.Id())
+		}
+	}
+	if result.NotFound[0].Id != "not-found" || result.NotFound[1].PartitionKeyValues[0] != "wrong-user" {
+		t.Fatalf("%s failed: received not found %#v", name, result.NotFound)
+	}
+
+	if result := client.ReadMany(gocosmos.ReadManyReq{DbName: dbname, CollName: "tbl_not_found", Items: items}); result.CallErr != nil {
+		t.Fatalf("%s failed: %s", name, result.CallErr)
+	} else if result.StatusCode != 404 {
+		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", name, 404, result.StatusCode)
+	}
+}
@@ -93,6 +93,48 @@ func _ensureCollection(client *gocosmos.RestClient, spec gocosmos.CollectionSpec
 	_createCollection(client, spec)
 }
 
+func TestRestClient_GetDatabaseAccount(t *testing.T) {
+	name := "TestRestClient_GetDatabaseAccount"
+	client := _newRestClient(t, name)
+	result := client.GetDatabaseAccount()
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name, err)
+	}
+	if result.Id == "" || len(result.WritableLocations) == 0 || len(result.ReadableLocations) == 0 {
+		t.Fatalf("%s failed: account id and locations must be populated, received %#v", name, result.DatabaseAccount)
+	}
+	if result.UserConsistencyPolicy.DefaultConsistencyLevel == "" {
+		t.Fatalf("%s failed: default consistency level must be populated", name)
+	}
+	if result.QueryEngineLimit("maxSqlQueryInputLength") <= 0 {
+		t.Fatalf("%s failed: query engine configuration must be populated, received %#v", name, result.QueryEngineConfiguration)
+	}
+}
+
+func TestRestClient_PreferredRegions(t *testing.T) {
+	name := "TestRestClient_PreferredRegions"
+	cosmosUrl := strings.TrimSpace(strings.ReplaceAll(os.Getenv("COSMOSDB_URL"), `"`, ""))
+	if cosmosUrl == "" {
+		t.Skipf("%s skipped", name)
+	}
+	// the region does not exist: requests are routed to the account's regions in their default order
+	client, err := gocosmos.NewRestClient(nil, cosmosUrl+";PreferredRegions=Nowhere")
+	if err != nil {
+		t.Fatalf("%s failed: %s", name+"/NewRestClient", err)
+	}
+	result := client.ListDatabases()
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", name+"/ListDatabases", err)
+	}
+	if result.Region == "" {
+		t.Fatalf("%s failed: region of the response is not populated", name+"/ListDatabases")
+	}
+	_ensureDatabase(client, gocosmos.DatabaseSpec{Id: testDb})
+	if result := client.GetDatabase(testDb); result.Error() != nil || result.Region == "" {
+		t.Fatalf("%s failed: received response from region %#v (error %s)", name+"/GetDatabase", result.Region, result.Error())
+	}
+}
+
 func TestRestClient_GetPkranges(t *testing.T) {
 	name := "TestRestClient_GetPkranges"
 	client := _newRestClient(t, name)
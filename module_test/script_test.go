@@ -0,0 +1,106 @@
+package gocosmos_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/microsoft/gocosmos"
+)
+
+func TestExecScript(t *testing.T) {
+	testName := "TestExecScript"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+
+	script := fmt.Sprintf(`-- schema
+CREATE DATABASE IF NOT EXISTS %[1]s WITH ru=400;
+CREATE COLLECTION IF NOT EXISTS %[1]s.tbltemp WITH pk=/id;
+/* seed data */
+INSERT INTO %[1]s.tbltemp (id, grade) VALUES ("\"1\"", 1), ("\"2\"", 2);`, dbname)
+	results, err := gocosmos.ExecScript(context.Background(), db, script)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("%s failed: expected 3 results but received %d", testName, len(results))
+	}
+	if affectedRows, err := results[2].RowsAffected(); err != nil || affectedRows != 2 {
+		t.Fatalf("%s failed: expected 2 affected rows but received %d (error %s)", testName, affectedRows, err)
+	}
+
+	// execution stops at the first failed statement
+	script = fmt.Sprintf(`INSERT INTO %[1]s.tbltemp (id, grade) VALUES ("\"3\"", 3);
+INSERT INTO %[1]s.tbltemp (id, grade) VALUES ("\"1\"", 1);
+INSERT INTO %[1]s.tbltemp (id, grade) VALUES ("\"4\"", 4)`, dbname)
+	results, err = gocosmos.ExecScript(context.Background(), db, script)
+	var scriptErr *gocosmos.ScriptError
+	if !errors.As(err, &scriptErr) || !errors.Is(err, gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: expected ScriptError/ErrConflict but received %#v", testName, err)
+	}
+	if scriptErr.Index != 1 || scriptErr.Line != 2 || len(results) != 1 {
+		t.Fatalf("%s failed: received %s with %d results", testName, err, len(results))
+	}
+	dbRows, err := db.Query(fmt.Sprintf("SELECT CROSS PARTITION c.id FROM c WITH db=%s WITH collection=tbltemp", dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%s failed: expected 3 documents but received %d", testName, len(rows))
+	}
+}
+
+func TestMigrator_Migrate(t *testing.T) {
+	testName := "TestMigrator_Migrate"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+
+	migrations := []gocosmos.Migration{
+		{Version: 2, Name: "seed_users", Script: fmt.Sprintf(`INSERT INTO %s.users (id, name) VALUES ("\"1\"", "\"me\"")`, dbname)},
+		{Version: 1, Name: "create_users", Script: fmt.Sprintf("CREATE COLLECTION IF NOT EXISTS %s.users WITH pk=/id", dbname)},
+	}
+	migrator := gocosmos.NewMigrator(db, dbname, "")
+	applied, err := migrator.Migrate(context.Background(), migrations)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(applied) != 2 || applied[0].Version != 1 || applied[1].Version != 2 {
+		t.Fatalf("%s failed: received %#v", testName, applied)
+	}
+
+	// migrations are applied only once
+	migrations = append(migrations, gocosmos.Migration{Version: 3, Name: "seed_more_users", Script: fmt.Sprintf(`INSERT INTO %s.users (id, name) VALUES ("\"2\"", "\"you\"")`, dbname)})
+	applied, err = migrator.Migrate(context.Background(), migrations)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(applied) != 1 || applied[0].Version != 3 {
+		t.Fatalf("%s failed: received %#v", testName, applied)
+	}
+	records, err := migrator.Applied(context.Background())
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(records) != 3 || records[0].Name != "create_users" || records[2].Checksum != migrations[2].Checksum() || records[2].AppliedAt.IsZero() {
+		t.Fatalf("%s failed: received %#v", testName, records)
+	}
+
+	// applied migrations must not be changed
+	migrations[0].Script += ";"
+	if _, err = migrator.Migrate(context.Background(), migrations); err == nil {
+		t.Fatalf("%s failed: expected error for changed migration", testName)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestStmtCreateCollection_Query(t *testing.T) {
@@ -652,3 +654,279 @@ func TestStmtListCollections_Query_DefaultDb(t *testing.T) {
 		t.Fatalf("%s failed: expected ErrNotFound but received %#v", testName, err)
 	}
 }
+
+func TestStmtAlterCollection_Exec_MigrateTo(t *testing.T) {
+	testName := "TestStmtAlterCollection_Exec_MigrateTo"
+	client := _newRestClient(t, testName)
+	db := _openDefaultDb(t, testName, "dbtemp")
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), "CREATE COLLECTION tbltemp WITH pk=/id WITH ru=400"} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	getResult := client.GetCollection(dbname, "tbltemp")
+	if err := getResult.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetCollection", err)
+	}
+
+	for _, mode := range []string{"AUTOSCALE", "MANUAL"} {
+		if _, err := db.Exec("ALTER COLLECTION tbltemp WITH MIGRATE_TO=" + mode); err != nil {
+			t.Fatalf("%s failed: %s", testName+"/"+mode, err)
+		}
+		waitResult := client.WaitForOfferReplace(getResult.Rid, time.Second, time.Minute)
+		if err := waitResult.Error(); err != nil {
+			t.Fatalf("%s failed: %s", testName+"/WaitForOfferReplace", err)
+		}
+		if auto := waitResult.IsAutopilot(); auto != (mode == "AUTOSCALE") {
+			t.Fatalf("%s failed: <auto> expected %#v but received %#v", testName+"/"+mode, mode == "AUTOSCALE", auto)
+		}
+	}
+
+	if _, err := db.Exec("ALTER COLLECTION tblnotexists WITH MIGRATE_TO=AUTOSCALE"); !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound but received %#v", testName+"/not_exists", err)
+	}
+}
+
+func TestStmtCollection_Exec_ConflictResolution(t *testing.T) {
+	testName := "TestStmtCollection_Exec_ConflictResolution"
+	client := _newRestClient(t, testName)
+	db := _openDefaultDb(t, testName, "dbtemp")
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	if _, err := db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/init", err)
+	}
+	if _, err := db.Exec("CREATE COLLECTION tbllww WITH pk=/id WITH CONFLICT_PATH=/version"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_lww", err)
+	}
+	if _, err := db.Exec("CREATE COLLECTION tblcustom WITH pk=/id WITH CONFLICT_SPROC=resolver"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_custom", err)
+	}
+
+	result := client.GetCollection(dbname, "tbllww")
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetCollection", err)
+	}
+	if policy := result.ConflictResolutionPolicy; policy["mode"] != "LastWriterWins" || policy["conflictResolutionPath"] != "/version" {
+		t.Fatalf("%s failed: unexpected conflict resolution policy %#v", testName+"/lww", policy)
+	}
+	result = client.GetCollection(dbname, "tblcustom")
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetCollection", err)
+	}
+	if sproc, _ := result.ConflictResolutionPolicy["conflictResolutionProcedure"].(string); result.ConflictResolutionPolicy["mode"] != "Custom" || !strings.HasSuffix(sproc, "/sprocs/resolver") {
+		t.Fatalf("%s failed: unexpected conflict resolution policy %#v", testName+"/custom", result.ConflictResolutionPolicy)
+	}
+}
+
+func TestStmtCollection_Exec_Ttl(t *testing.T) {
+	testName := "TestStmtCollection_Exec_Ttl"
+	client := _newRestClient(t, testName)
+	db := _openDefaultDb(t, testName, "dbtemp")
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), "CREATE COLLECTION tbltemp WITH pk=/id WITH TTL=3600"} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	getTtl := func() int {
+		result := client.GetCollection(dbname, "tbltemp")
+		if err := result.Error(); err != nil {
+			t.Fatalf("%s failed: %s", testName+"/GetCollection", err)
+		}
+		return result.DefaultTtl
+	}
+	if ttl := getTtl(); ttl != 3600 {
+		t.Fatalf("%s failed: expected default ttl %d but received %d", testName, 3600, ttl)
+	}
+
+	// changing the indexing policy must keep the default ttl
+	if _, err := db.Exec("ALTER COLLECTION tbltemp WITH EXCLUDE=/large/*"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/alter_indexing", err)
+	}
+	if ttl := getTtl(); ttl != 3600 {
+		t.Fatalf("%s failed: expected default ttl %d but received %d", testName, 3600, ttl)
+	}
+
+	if _, err := db.Exec("INSERT INTO tbltemp (id, a) VALUES (:1, :2) WITH TTL=60", "1", "a"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/insert", err)
+	}
+	if _, err := db.Exec("UPSERT INTO tbltemp VALUE :1 WITH TTL=-1", map[string]interface{}{"id": "2"}); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/upsert_value", err)
+	}
+	for id, expected := range map[string]float64{"1": 60, "2": -1} {
+		result := client.GetDocument(gocosmos.DocReq{DbName: dbname, CollName: "tbltemp", DocId: id, PartitionKeyValues: []interface{}{id}})
+		if err := result.Error(); err != nil {
+			t.Fatalf("%s failed: %s", testName+"/GetDocument", err)
+		}
+		if ttl := result.DocInfo["ttl"]; ttl != expected {
+			t.Fatalf("%s failed: expected document ttl %#v but received %#v", testName, expected, ttl)
+		}
+	}
+
+	if _, err := db.Exec("ALTER COLLECTION tbltemp WITH TTL=OFF"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/alter_ttl", err)
+	}
+	if ttl := getTtl(); ttl != 0 {
+		t.Fatalf("%s failed: expected default ttl %d but received %d", testName, 0, ttl)
+	}
+}
+
+func TestStmtDescribeCollection_Exec(t *testing.T) {
+	testName := "TestStmtDescribeCollection_Exec"
+	db := _openDb(t, testName)
+	_, err := db.Exec("DESCRIBE COLLECTION dbtemp.tbltemp")
+	if !errors.Is(err, gocosmos.ErrExecNotSupported) {
+		t.Fatalf("%s failed: expected ErrExecNotSupported, but received %#v", testName, err)
+	}
+}
+
+func TestStmtDescribeCollection_Query(t *testing.T) {
+	testName := "TestStmtDescribeCollection_Query"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/tenant,/user WITH uk=/email WITH TTL=-1", dbname)} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	dbRows, err := db.Query(fmt.Sprintf("DESCRIBE COLLECTION %s.tbltemp", dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/query", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/fetch", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("%s failed: expected 1 row but received %#v", testName, rows)
+	}
+	row := rows[0]
+	for _, col := range []string{"id", "partitionKey", "indexingPolicy", "uniqueKeyPolicy", "defaultTtl", "conflictResolutionPolicy", "geospatialConfig", "_rid", "_etag"} {
+		if _, ok := row[col]; !ok {
+			t.Fatalf("%s failed: column %s is missing in %#v", testName, col, row)
+		}
+	}
+	if row["id"] != "tbltemp" || fmt.Sprintf("%v", row["defaultTtl"]) != "-1" {
+		t.Fatalf("%s failed: received %#v", testName, row)
+	}
+	if pk := fmt.Sprintf("%v", row["partitionKey"]); !strings.Contains(pk, "/tenant") || !strings.Contains(pk, "/user") {
+		t.Fatalf("%s failed: received partition key %s", testName, pk)
+	}
+	if uk := fmt.Sprintf("%v", row["uniqueKeyPolicy"]); !strings.Contains(uk, "/email") {
+		t.Fatalf("%s failed: received unique key policy %s", testName, uk)
+	}
+
+	_, err = db.Query(fmt.Sprintf("DESCRIBE COLLECTION %s.tblnotfound", dbname))
+	if !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound, but received %#v", testName, err)
+	}
+}
+
+func TestStmtShowThroughput_Query(t *testing.T) {
+	testName := "TestStmtShowThroughput_Query"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{
+		fmt.Sprintf("CREATE DATABASE %s WITH ru=400", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tblshared WITH pk=/id", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tblauto WITH pk=/id WITH maxru=4000", dbname),
+	} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	testData := []struct {
+		name         string
+		sql          string
+		resourceType string
+		autopilot    bool
+		throughput   string
+		maxRu        string
+		mustNotFound bool
+	}{
+		{name: "database", sql: fmt.Sprintf("SHOW THROUGHPUT FOR %s", dbname), resourceType: "database", throughput: "400", maxRu: "0"},
+		{name: "collection_autopilot", sql: fmt.Sprintf("SHOW THROUGHPUT FOR %s.tblauto", dbname), resourceType: "collection", autopilot: true, maxRu: "4000"},
+		{name: "collection_shared", sql: fmt.Sprintf("SHOW THROUGHPUT FOR %s.tblshared", dbname), mustNotFound: true},
+		{name: "collection_not_found", sql: fmt.Sprintf("SHOW THROUGHPUT FOR %s.tblnotfound", dbname), mustNotFound: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbRows, err := db.Query(testCase.sql)
+			if testCase.mustNotFound {
+				if !errors.Is(err, gocosmos.ErrNotFound) {
+					t.Fatalf("%s failed: expected ErrNotFound, but received %#v", testName+"/"+testCase.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			rows, err := _fetchAllRows(dbRows)
+			if err != nil || len(rows) != 1 {
+				t.Fatalf("%s failed: {error: %s / rows: %#v}", testName+"/"+testCase.name, err, rows)
+			}
+			row := rows[0]
+			if row["resourceType"] != testCase.resourceType || row["autopilot"] != testCase.autopilot || fmt.Sprintf("%v", row["maxThroughput"]) != testCase.maxRu {
+				t.Fatalf("%s failed: received %#v", testName+"/"+testCase.name, row)
+			}
+			if testCase.throughput != "" && fmt.Sprintf("%v", row["throughput"]) != testCase.throughput {
+				t.Fatalf("%s failed: expected throughput %s but received %#v", testName+"/"+testCase.name, testCase.throughput, row)
+			}
+			if row["replacePending"] != false {
+				t.Fatalf("%s failed: expected no pending throughput change but received %#v", testName+"/"+testCase.name, row)
+			}
+		})
+	}
+}
+
+func TestStmtShowPartitions_Query(t *testing.T) {
+	testName := "TestStmtShowPartitions_Query"
+	db := _openDefaultDb(t, testName, "dbtemp")
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), "CREATE COLLECTION tbltemp WITH pk=/id WITH ru=20000"} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	dbRows, err := db.Query("SHOW PARTITIONS FOR tbltemp")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/query", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/fetch", err)
+	}
+	if len(rows) == 0 {
+		t.Fatalf("%s failed: expected at least 1 partition key range", testName)
+	}
+	for _, row := range rows {
+		if row["id"] == "" || row["maxExclusive"] == "" || row["_rid"] == "" {
+			t.Fatalf("%s failed: received %#v", testName, rows)
+		}
+	}
+}
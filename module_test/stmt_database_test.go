@@ -1,6 +1,7 @@
 package gocosmos_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -309,3 +310,54 @@ func TestStmtListDatabases_Query(t *testing.T) {
 		t.Fatalf("%s failed: database %s not found", testName, "dbtemp2")
 	}
 }
+
+func TestStmtUse_Query(t *testing.T) {
+	testName := "TestStmtUse_Query"
+	db := _openDb(t, testName)
+	_, err := db.Query("USE dbtemp")
+	if !errors.Is(err, gocosmos.ErrQueryNotSupported) {
+		t.Fatalf("%s failed: expected ErrQueryNotSupported, but received %#v", testName, err)
+	}
+}
+
+func TestStmtUse_Exec(t *testing.T) {
+	testName := "TestStmtUse_Exec"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	_, _ = db.Exec(fmt.Sprintf("CREATE DATABASE IF NOT EXISTS %s", dbname))
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+
+	// USE switches the default database of a single connection, so the connection must be pinned
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/conn", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err = conn.ExecContext(context.Background(), "USE dbnotexists"); !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound, but received %#v", testName+"/use_not_exists", err)
+	}
+	if _, err = conn.ExecContext(context.Background(), "CREATE COLLECTION tbltemp WITH pk=/id"); err == nil {
+		t.Fatalf("%s failed: expected error as there is no default database", testName+"/create_collection")
+	}
+
+	if _, err = conn.ExecContext(context.Background(), fmt.Sprintf("USE %s", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/use", err)
+	}
+	if _, err = conn.ExecContext(context.Background(), "CREATE COLLECTION tbltemp WITH pk=/id"); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_collection", err)
+	}
+	dbRows, err := conn.QueryContext(context.Background(), "LIST COLLECTIONS")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/list_collections", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/fetch_rows", err)
+	}
+	if len(rows) != 1 || rows[0]["id"] != "tbltemp" {
+		t.Fatalf("%s failed: expected collection tbltemp in database %s, but received %v", testName, dbname, rows)
+	}
+}
@@ -1,6 +1,7 @@
 package gocosmos_test
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
@@ -651,3 +652,61 @@ func TestStmtDelete_WithPK_SubPartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtDelete_Exec_Predicate(t *testing.T) {
+	testName := "TestStmtDelete_Exec_Predicate"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	initSqls := []string{
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname),
+		fmt.Sprintf("CREATE DATABASE %s", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname),
+	}
+	for _, initSql := range initSqls {
+		if _, err := db.Exec(initSql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName, err, initSql)
+		}
+	}
+	for i := 0; i < 30; i++ {
+		_, err := db.Exec(fmt.Sprintf(`INSERT INTO %s.tbltemp (id,username,grade) VALUES (:1,:2,:3) WITH pk=/username`, dbname),
+			fmt.Sprintf("%02d", i), fmt.Sprintf("user%d", i%4), i)
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName+"/insert", err)
+		}
+	}
+
+	testData := []struct {
+		name         string
+		sql          string
+		args         []interface{}
+		mustError    bool
+		affectedRows int64
+	}{
+		{name: "max_rows_exceeded", sql: `DELETE FROM %s.tbltemp WHERE c.grade < :1 WITH MAX_ROWS=10`, args: []interface{}{20}, mustError: true},
+		{name: "dry_run", sql: `DELETE FROM %s.tbltemp WHERE c.grade < :1 WITH DRY_RUN`, args: []interface{}{20}, affectedRows: 20},
+		{name: "cross_partition", sql: `DELETE FROM %s.tbltemp WHERE c.grade < :1 WITH MAX_ROWS=20`, args: []interface{}{20}, affectedRows: 20},
+		{name: "already_deleted", sql: `DELETE FROM %s.tbltemp WHERE c.grade < :1`, args: []interface{}{20}, affectedRows: 0},
+		{name: "named_param", sql: `DELETE FROM %s.tbltemp WHERE c.username = @username`, args: []interface{}{sql.Named("username", "user1")}, affectedRows: 3},
+		{name: "table_not_exists", sql: `DELETE FROM %s.tbl_not_found WHERE c.grade > 0`, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			execResult, err := db.Exec(fmt.Sprintf(testCase.sql, dbname), testCase.args...)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != testCase.affectedRows {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/"+testCase.name, testCase.affectedRows, affectedRows, err)
+			}
+		})
+	}
+}
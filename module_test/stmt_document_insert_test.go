@@ -1,6 +1,7 @@
 package gocosmos_test
 
 import (
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -652,3 +653,235 @@ func TestStmtInsert_WithPK_SubPartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtInsert_Exec_MultiRows(t *testing.T) {
+	testName := "TestStmtInsert_Exec_MultiRows"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	_, _ = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
+	if _, err := db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_collection", err)
+	}
+
+	execResult, err := db.Exec(fmt.Sprintf(`INSERT INTO %s.tbltemp (id,username,grade) VALUES (:1,:2,1), ("\"2\"",:3,2), (:4,"\"user1\"",:5) WITH pk=/username`, dbname),
+		"1", "user1", "user2", "3", 3)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/insert", err)
+	}
+	if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != 3 {
+		t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/insert", 3, affectedRows, err)
+	}
+
+	// row #1 conflicts with an existing document, other rows are still written
+	_, err = db.Exec(fmt.Sprintf(`INSERT INTO %s.tbltemp (id,username,grade) VALUES ("\"4\"","\"user1\"",4), ("\"2\"","\"user2\"",5), ("\"5\"","\"user2\"",6)`, dbname))
+	var bulkErr *gocosmos.BulkWriteError
+	if !errors.As(err, &bulkErr) {
+		t.Fatalf("%s failed: expected BulkWriteError but received %#v", testName+"/partial_failure", err)
+	}
+	if bulkErr.NumRows != 3 || bulkErr.NumSucceeded != 2 || len(bulkErr.RowErrors) != 1 || !errors.Is(bulkErr.RowErrors[1], gocosmos.ErrConflict) {
+		t.Fatalf("%s failed: unexpected error %s", testName+"/partial_failure", bulkErr)
+	}
+
+	dbRows, err := db.Query(fmt.Sprintf(`SELECT CROSS PARTITION * FROM tbltemp c WITH db=%s`, dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/select", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil || len(rows) != 5 {
+		t.Fatalf("%s failed: expected 5 rows but received %d / %s", testName+"/select", len(rows), err)
+	}
+}
+
+func TestStmtInsertSelect_Exec(t *testing.T) {
+	testName := "TestStmtInsertSelect_Exec"
+	db := _openDb(t, testName)
+	dbname, dbname2 := "dbtemp", "dbtemp2"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname2))
+	}()
+	for _, name := range []string{dbname, dbname2} {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", name))
+		_, _ = db.Exec(fmt.Sprintf("CREATE DATABASE %s", name))
+	}
+	_, _ = db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tblsrc WITH pk=/username", dbname))
+	_, _ = db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tbldest WITH pk=/grade", dbname))
+	_, _ = db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tbldest WITH pk=/username", dbname2))
+	for i := 0; i < 20; i++ {
+		_, err := db.Exec(fmt.Sprintf(`INSERT INTO %s.tblsrc (id,username,grade) VALUES (:1,:2,:3) WITH pk=/username`, dbname),
+			fmt.Sprintf("%02d", i), fmt.Sprintf("user%d", i%4), i)
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName+"/insert", err)
+		}
+	}
+
+	testData := []struct {
+		name         string
+		sql          string
+		args         []interface{}
+		mustError    bool
+		affectedRows int64
+	}{
+		{name: "same_db", sql: fmt.Sprintf(`INSERT INTO %s.tbldest SELECT * FROM c WHERE c.grade < :1 WITH collection=tblsrc WITH cross_partition=true`, dbname), args: []interface{}{10}, affectedRows: 10},
+		{name: "conflict", sql: fmt.Sprintf(`INSERT INTO %s.tbldest SELECT * FROM c WHERE c.grade < :1 WITH collection=tblsrc WITH cross_partition=true`, dbname), args: []interface{}{12}, mustError: true},
+		{name: "upsert", sql: fmt.Sprintf(`UPSERT INTO %s.tbldest SELECT * FROM c WHERE c.grade < :1 WITH collection=tblsrc WITH cross_partition=true`, dbname), args: []interface{}{12}, affectedRows: 12},
+		{name: "cross_db", sql: fmt.Sprintf(`INSERT INTO %s.tbldest SELECT c.id, c.username FROM c WHERE c.username=@username WITH db=%s WITH collection=tblsrc`, dbname2, dbname), args: []interface{}{sql.Named("username", "user1")}, affectedRows: 5},
+		{name: "source_not_exists", sql: fmt.Sprintf(`INSERT INTO %s.tbldest SELECT * FROM tbl_not_found`, dbname), mustError: true},
+		{name: "target_not_exists", sql: fmt.Sprintf(`INSERT INTO %s.tbl_not_found SELECT * FROM tblsrc`, dbname), mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			execResult, err := db.Exec(testCase.sql, testCase.args...)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != testCase.affectedRows {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/"+testCase.name, testCase.affectedRows, affectedRows, err)
+			}
+		})
+	}
+}
+
+func TestStmtInsertValue_Exec(t *testing.T) {
+	testName := "TestStmtInsertValue_Exec"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	_, _ = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
+	if _, err := db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/user/name", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_collection", err)
+	}
+
+	type user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type doc struct {
+		Id   string `json:"id"`
+		User user   `json:"user"`
+	}
+	testData := []struct {
+		name         string
+		sql          string
+		args         []interface{}
+		mustConflict bool
+		mustError    bool
+	}{
+		{name: "json_literal", sql: `INSERT INTO %s.tbltemp VALUE {"id":"1","user":{"name":"user1","email":"user1@domain.com"},"tags":["a","b"]}`},
+		{name: "conflict", sql: `INSERT INTO %s.tbltemp VALUE {"id":"1","user":{"name":"user1"}} WITH pk=/user/name`, mustConflict: true},
+		{name: "upsert_map", sql: `UPSERT INTO %s.tbltemp VALUE :1`, args: []interface{}{map[string]interface{}{"id": "1", "user": map[string]interface{}{"name": "user1"}, "grade": 2}}},
+		{name: "struct", sql: `INSERT INTO %s.tbltemp VALUE $1`, args: []interface{}{doc{Id: "2", User: user{Name: "user2", Email: "user2@domain.com"}}}},
+		{name: "json_string_named", sql: `INSERT INTO %s.tbltemp VALUE @doc`, args: []interface{}{sql.Named("doc", `{"id":"3","user":{"name":"user3"}}`)}},
+		{name: "missing_pk", sql: `INSERT INTO %s.tbltemp VALUE {"id":"4","name":"user4"}`, mustError: true},
+		{name: "invalid_id", sql: `INSERT INTO %s.tbltemp VALUE {"id":5,"user":{"name":"user5"}}`, mustError: true},
+		{name: "not_a_document", sql: `INSERT INTO %s.tbltemp VALUE :1`, args: []interface{}{"[1,2,3]"}, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			execResult, err := db.Exec(fmt.Sprintf(testCase.sql, dbname), testCase.args...)
+			if testCase.mustConflict {
+				if !errors.Is(err, gocosmos.ErrConflict) {
+					t.Fatalf("%s failed: expected ErrConflict but received %#v", testName+"/"+testCase.name, err)
+				}
+				return
+			}
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != 1 {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/"+testCase.name, 1, affectedRows, err)
+			}
+		})
+	}
+
+	dbRows, err := db.Query(fmt.Sprintf(`SELECT * FROM c WHERE c.id=:1 WITH db=%s WITH collection=tbltemp WITH cross_partition=true`, dbname), "1")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/select", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("%s failed: expected 1 row but received %d / %s", testName+"/select", len(rows), err)
+	}
+	if grade, ok := rows[0]["grade"].(float64); !ok || grade != 2 {
+		t.Fatalf("%s failed: expected upserted grade 2 but received %#v", testName+"/select", rows[0]["grade"])
+	}
+}
+
+func TestStmt_Returning(t *testing.T) {
+	testName := "TestStmt_Returning"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	_, _ = db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname))
+	if _, err := db.Exec(fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_collection", err)
+	}
+
+	if _, err := db.Query(fmt.Sprintf(`INSERT INTO %s.tbltemp (id,username) VALUES ("\"0\"","\"user0\"")`, dbname)); !errors.Is(err, gocosmos.ErrQueryNotSupported) {
+		t.Fatalf("%s failed: expected ErrQueryNotSupported but received %#v", testName+"/no_returning", err)
+	}
+
+	testData := []struct {
+		name    string
+		sql     string
+		args    []interface{}
+		numRows int
+		check   func(rows []map[string]interface{}) bool
+	}{
+		{name: "insert", sql: `INSERT INTO %s.tbltemp (id,username,grade) VALUES (:1,:2,1) RETURNING *`, args: []interface{}{"1", "user1"}, numRows: 1,
+			check: func(rows []map[string]interface{}) bool {
+				return rows[0]["id"] == "1" && rows[0]["_etag"] != nil && rows[0]["_ts"] != nil
+			}},
+		{name: "insert_multi_rows", sql: `INSERT INTO %s.tbltemp (id,username,grade) VALUES ("\"2\"","\"user1\"",2), ("\"3\"","\"user2\"",3) RETURNING id, _etag`, numRows: 2,
+			check: func(rows []map[string]interface{}) bool { return len(rows[0]) == 2 && rows[0]["_etag"] != nil }},
+		{name: "insert_value_auto_id", sql: `INSERT INTO %s.tbltemp VALUE {"username":"user3"} RETURNING id`, numRows: 1,
+			check: func(rows []map[string]interface{}) bool { id, _ := rows[0]["id"].(string); return id != "" }},
+		{name: "update", sql: `UPDATE %s.tbltemp SET grade=grade+10 WHERE id="1" AND username="user1" RETURNING grade`, numRows: 1,
+			check: func(rows []map[string]interface{}) bool { return rows[0]["grade"] == 11.0 }},
+		{name: "update_predicate", sql: `UPDATE %s.tbltemp SET active=true WHERE c.username=:1 RETURNING id, active`, args: []interface{}{"user1"}, numRows: 2,
+			check: func(rows []map[string]interface{}) bool {
+				return rows[0]["active"] == true && rows[1]["active"] == true
+			}},
+		{name: "delete", sql: `DELETE FROM %s.tbltemp WHERE id="3" AND username="user2" RETURNING *`, numRows: 1,
+			check: func(rows []map[string]interface{}) bool { return rows[0]["id"] == "3" && rows[0]["grade"] == 3.0 }},
+		{name: "delete_not_found", sql: `DELETE FROM %s.tbltemp WHERE id="3" AND username="user2" RETURNING *`, numRows: 0},
+		{name: "delete_predicate", sql: `DELETE FROM %s.tbltemp WHERE c.username=:1 RETURNING id`, args: []interface{}{"user1"}, numRows: 2},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			dbRows, err := db.Query(fmt.Sprintf(testCase.sql, dbname), testCase.args...)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			rows, err := _fetchAllRows(dbRows)
+			if err != nil || len(rows) != testCase.numRows {
+				t.Fatalf("%s failed: expected %d rows but received %d / %s", testName+"/"+testCase.name, testCase.numRows, len(rows), err)
+			}
+			if testCase.check != nil && !testCase.check(rows) {
+				t.Fatalf("%s failed: unexpected rows %#v", testName+"/"+testCase.name, rows)
+			}
+		})
+	}
+}
@@ -782,3 +782,78 @@ func TestStmtSelect_Query_SelectPkranges(t *testing.T) {
 		}
 	}
 }
+
+/*----------------------------------------------------------------------*/
+
+func TestStmtExplain_Query(t *testing.T) {
+	testName := "TestStmtExplain_Query"
+	dbname := testDb
+	collname := testTable
+	client := _newRestClient(t, testName)
+	_initDataLargeRU(t, testName, client, dbname, collname, 100)
+	db := _openDefaultDb(t, testName, dbname)
+
+	dbRows, err := db.Query(fmt.Sprintf(`EXPLAIN SELECT CROSS PARTITION c.username, COUNT(1) AS total FROM %s c WHERE c.grade > :1 GROUP BY c.username`, collname), 10)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/explain", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/explain", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("%s failed: expected 1 row but received %d", testName+"/explain", len(rows))
+	}
+	plan := rows[0]
+	if plan["rewrittenQuery"] == "" || !reflect.DeepEqual(plan["groupByExpressions"], []string{"c.username"}) {
+		t.Fatalf("%s failed: received %#v", testName+"/explain", plan)
+	}
+	if targets, ok := plan["targetPartitions"].([]string); !ok || len(targets) < 2 {
+		t.Fatalf("%s failed: expected target partitions but received %#v", testName+"/explain", plan["targetPartitions"])
+	}
+
+	dbRows, err = db.Query(fmt.Sprintf(`EXPLAIN ANALYZE SELECT CROSS PARTITION * FROM %s c WHERE c.grade > :1`, collname), 10)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/explain_analyze", err)
+	}
+	rows, err = _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/explain_analyze", err)
+	}
+	if len(rows) < 3 {
+		t.Fatalf("%s failed: expected rows per partition and a total row but received %#v", testName+"/explain_analyze", rows)
+	}
+	total := rows[len(rows)-1]
+	if total["partition"] != "total" || total["outputDocumentCount"] != int64(95) || total["requestCharge"].(float64) <= 0 {
+		t.Fatalf("%s failed: received %#v", testName+"/explain_analyze", total)
+	}
+}
+
+func TestStmtSelect_Query_ReadMany(t *testing.T) {
+	testName := "TestStmtSelect_Query_ReadMany"
+	dbname := testDb
+	collname := testTable
+	client := _newRestClient(t, testName)
+	_initDataLargeRU(t, testName, client, dbname, collname, 100)
+	db := _openDefaultDb(t, testName, dbname)
+
+	query := fmt.Sprintf(`SELECT * FROM %s WHERE (id, username) IN ((:1, :2), (@id, @username), ('not-found', 'user0'))`, collname)
+	dbRows, err := db.Query(query, dataList[42].Id(), dataList[42]["username"], sql.Named("id", dataList[7].Id()), sql.Named("username", dataList[7]["username"]))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if len(rows) != 2 || rows[0]["id"] != dataList[42].Id() || rows[1]["id"] != dataList[7].Id() {
+		t.Fatalf("%s failed: received %#v", testName, rows)
+	}
+	if _, ok := rows[0]["_rid"]; ok {
+		t.Fatalf("%s failed: system attributes must be removed, received %#v", testName, rows[0])
+	}
+
+	if _, err := db.Query(query, 42, "user0", sql.Named("id", "1"), sql.Named("username", "user0")); err == nil {
+		t.Fatalf("%s failed: id must be a string", testName)
+	}
+}
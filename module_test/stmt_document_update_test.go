@@ -1,9 +1,11 @@
 package gocosmos_test
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/microsoft/gocosmos"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -884,3 +886,187 @@ func TestStmtUpdate_WithPK_SubPartitions(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtUpdate_Exec_Predicate(t *testing.T) {
+	testName := "TestStmtUpdate_Exec_Predicate"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	initSqls := []string{
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname),
+		fmt.Sprintf("CREATE DATABASE %s", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname),
+	}
+	for _, initSql := range initSqls {
+		if _, err := db.Exec(initSql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName, err, initSql)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		_, err := db.Exec(fmt.Sprintf(`INSERT INTO %s.tbltemp (id,username,grade,status) VALUES (:1,:2,:3,"\"active\"") WITH pk=/username`, dbname),
+			fmt.Sprintf("%02d", i), fmt.Sprintf("user%d", i%3), i)
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName+"/insert", err)
+		}
+	}
+
+	testData := []struct {
+		name         string
+		sql          string
+		args         []interface{}
+		mustError    bool
+		affectedRows int64
+	}{
+		{name: "max_rows_exceeded", sql: `UPDATE %s.tbltemp SET status="\"archived\"" WHERE c.grade < :1 WITH MAX_ROWS=3`, args: []interface{}{5}, mustError: true},
+		{name: "cross_partition", sql: `UPDATE %s.tbltemp SET status="\"archived\"" WHERE c.grade < :1 WITH MAX_ROWS=5`, args: []interface{}{5}, affectedRows: 5},
+		{name: "named_param", sql: `UPDATE %s.tbltemp SET level=:1 WHERE c.status = @status AND c.grade >= 8`, args: []interface{}{"high", sql.Named("status", "active")}, affectedRows: 2},
+		{name: "no_match", sql: `UPDATE %s.tbltemp SET status="\"archived\"" WHERE c.grade > 100`, affectedRows: 0},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			execResult, err := db.Exec(fmt.Sprintf(testCase.sql, dbname), testCase.args...)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != testCase.affectedRows {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/"+testCase.name, testCase.affectedRows, affectedRows, err)
+			}
+		})
+	}
+
+	dbRows, err := db.Query(fmt.Sprintf(`SELECT COUNT(1) AS num FROM c WHERE c.status="archived" WITH db=%s WITH collection=tbltemp WITH cross_partition=true`, dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/count", err)
+	}
+	rows, _ := _fetchAllRows(dbRows)
+	if len(rows) != 1 || rows[0]["num"] != 5.0 {
+		t.Fatalf("%s failed: expected 5 archived documents but received %#v", testName+"/count", rows)
+	}
+}
+
+func TestStmtUpdate_Exec_Expressions(t *testing.T) {
+	testName := "TestStmtUpdate_Exec_Expressions"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	initSqls := []string{
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname),
+		fmt.Sprintf("CREATE DATABASE %s", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname),
+		fmt.Sprintf(`INSERT INTO %s.tbltemp VALUE {"id":"1","username":"user1","views":1,"tags":["a"],"address":{"city":"HCM","zip":"70000"}}`, dbname),
+	}
+	for _, initSql := range initSqls {
+		if _, err := db.Exec(initSql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName, err, initSql)
+		}
+	}
+
+	sqls := []string{
+		`UPDATE %s.tbltemp SET views=views+:1, address.city="\"Hanoi\"", tags=ARRAY_APPEND(tags, "\"b\"") UNSET address.zip WHERE id="1" AND username="user1"`,
+		`UPDATE %s.tbltemp SET views=views-1, tags[0]="\"z\"" WHERE c.username=:1`,
+	}
+	args := [][]interface{}{{10}, {"user1"}}
+	for i, query := range sqls {
+		execResult, err := db.Exec(fmt.Sprintf(query, dbname), args[i]...)
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName+"/update", err)
+		}
+		if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != 1 {
+			t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/update", 1, affectedRows, err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf(`UPDATE %s.tbltemp SET tags=tags+1 WHERE id="1" AND username="user1"`, dbname)); err == nil {
+		t.Fatalf("%s failed: expected error when incrementing a non-numeric field", testName)
+	}
+
+	dbRows, err := db.Query(fmt.Sprintf(`SELECT c.views, c.tags, c.address FROM c WHERE c.id="1" WITH db=%s WITH collection=tbltemp WITH cross_partition=true`, dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/select", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("%s failed: expected 1 row but received %d / %s", testName+"/select", len(rows), err)
+	}
+	expected := map[string]interface{}{"views": 10.0, "tags": []interface{}{"z", "b"}, "address": map[string]interface{}{"city": "Hanoi"}}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, rows[0])
+	}
+}
+
+func TestStmt_EtagConditions(t *testing.T) {
+	testName := "TestStmt_EtagConditions"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	initSqls := []string{
+		fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname),
+		fmt.Sprintf("CREATE DATABASE %s", dbname),
+		fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/username", dbname),
+		fmt.Sprintf(`INSERT INTO %s.tbltemp VALUE {"id":"1","username":"user1","grade":1}`, dbname),
+	}
+	for _, initSql := range initSqls {
+		if _, err := db.Exec(initSql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName, err, initSql)
+		}
+	}
+	fetchEtag := func() string {
+		dbRows, err := db.Query(fmt.Sprintf(`SELECT c._etag FROM c WHERE c.id="1" WITH db=%s WITH collection=tbltemp WITH cross_partition=true`, dbname))
+		if err != nil {
+			t.Fatalf("%s failed: %s", testName+"/select", err)
+		}
+		rows, err := _fetchAllRows(dbRows)
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("%s failed: expected 1 row but received %d / %s", testName+"/select", len(rows), err)
+		}
+		return rows[0]["_etag"].(string)
+	}
+
+	staleEtag := fetchEtag()
+	testData := []struct {
+		name      string
+		sql       string
+		args      []interface{}
+		etag      string
+		mustMatch bool
+	}{
+		{name: "update_match", sql: `UPDATE %s.tbltemp SET grade=2 WHERE id="1" AND username="user1" AND _etag=:1`, mustMatch: true},
+		{name: "update_stale", sql: `UPDATE %s.tbltemp SET grade=3 WHERE id="1" AND username="user1" AND _etag=:1`, etag: staleEtag},
+		{name: "upsert_match", sql: `UPSERT INTO %s.tbltemp (id,username,grade) VALUES ("1","user1",4) WITH IF_MATCH=:1`, mustMatch: true},
+		{name: "upsert_stale", sql: `UPSERT INTO %s.tbltemp (id,username,grade) VALUES ("1","user1",5) WITH IF_MATCH=:1`, etag: staleEtag},
+		{name: "delete_stale", sql: `DELETE FROM %s.tbltemp WHERE id="1" AND username="user1" WITH IF_MATCH=:1`, etag: staleEtag},
+		{name: "delete_match", sql: `DELETE FROM %s.tbltemp WHERE id="1" AND username="user1" WITH IF_MATCH=:1`, mustMatch: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			etag := testCase.etag
+			if testCase.mustMatch {
+				etag = fetchEtag()
+			}
+			execResult, err := db.Exec(fmt.Sprintf(testCase.sql, dbname), etag)
+			if !testCase.mustMatch {
+				if !errors.Is(err, gocosmos.ErrPreconditionFailure) {
+					t.Fatalf("%s failed: expected ErrPreconditionFailure but received %#v", testName+"/"+testCase.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if affectedRows, err := execResult.RowsAffected(); err != nil || affectedRows != 1 {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v / %s", testName+"/"+testCase.name, 1, affectedRows, err)
+			}
+		})
+	}
+}
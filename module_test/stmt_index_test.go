@@ -0,0 +1,190 @@
+package gocosmos_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/microsoft/gocosmos"
+	"testing"
+)
+
+func _getIndexingPolicy(t *testing.T, testName string, client *gocosmos.RestClient, db, coll string) map[string]interface{} {
+	result := client.GetCollection(db, coll)
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetCollection", err)
+	}
+	return result.IndexingPolicy
+}
+
+func _policyHasPath(policy map[string]interface{}, key, path string) bool {
+	list, _ := policy[key].([]interface{})
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok && m["path"] == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestStmtCreateCollection_Exec_IndexingPolicy(t *testing.T) {
+	testName := "TestStmtCreateCollection_Exec_IndexingPolicy"
+	client := _newRestClient(t, testName)
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	if _, err := db.Exec(fmt.Sprintf("CREATE DATABASE %s", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_db", err)
+	}
+	sql := fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/id WITH include=/name/?,/age/? WITH exclude=/* WITH composite=/name ASC,/age DESC WITH spatial=/location/*", dbname)
+	if _, err := db.Exec(sql); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/create_collection", err)
+	}
+	policy := _getIndexingPolicy(t, testName, client, dbname, "tbltemp")
+	if !_policyHasPath(policy, "includedPaths", "/name/?") || !_policyHasPath(policy, "excludedPaths", "/*") || !_policyHasPath(policy, "spatialIndexes", "/location/*") {
+		t.Fatalf("%s failed: received indexing policy %#v", testName, policy)
+	}
+	if composite, _ := policy["compositeIndexes"].([]interface{}); len(composite) != 1 {
+		t.Fatalf("%s failed: received composite indexes %#v", testName, policy["compositeIndexes"])
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("ALTER COLLECTION %s.tbltemp WITH INDEXING=none", dbname)); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/alter_collection", err)
+	}
+	if policy := _getIndexingPolicy(t, testName, client, dbname, "tbltemp"); policy["indexingMode"] != "none" {
+		t.Fatalf("%s failed: received indexing policy %#v", testName, policy)
+	}
+}
+
+func TestStmtCreateIndex_Query(t *testing.T) {
+	testName := "TestStmtCreateIndex_Query"
+	db := _openDb(t, testName)
+	_, err := db.Query("CREATE INDEX ON dbtemp.tbltemp (/name/?)")
+	if !errors.Is(err, gocosmos.ErrQueryNotSupported) {
+		t.Fatalf("%s failed: expected ErrQueryNotSupported, but received %#v", testName, err)
+	}
+}
+
+func TestStmtCreateDropIndex_Exec(t *testing.T) {
+	testName := "TestStmtCreateDropIndex_Exec"
+	client := _newRestClient(t, testName)
+	db := _openDefaultDb(t, testName, "dbtemp")
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), "CREATE COLLECTION tbltemp WITH pk=/id"} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	testData := []struct {
+		name         string
+		sql          string
+		affectedRows int64
+		mustConflict bool
+		mustNotFound bool
+		verify       func(policy map[string]interface{}) bool
+	}{
+		{name: "drop_index_covered_by_wildcard", sql: "DROP INDEX ON tbltemp (/large/*)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool { return _policyHasPath(policy, "excludedPaths", "/large/*") }},
+		{name: "drop_index_not_exists", sql: "DROP INDEX ON tbltemp (/large/*)", mustNotFound: true},
+		{name: "drop_index_if_exists", sql: "DROP INDEX IF EXISTS ON tbltemp (/large/*)", affectedRows: 0},
+		{name: "create_index", sql: "CREATE INDEX ON tbltemp (/large/*)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool {
+				return _policyHasPath(policy, "includedPaths", "/large/*") && !_policyHasPath(policy, "excludedPaths", "/large/*")
+			}},
+		{name: "create_index_exists", sql: "CREATE INDEX ON tbltemp (/large/*)", mustConflict: true},
+		{name: "create_index_if_not_exists", sql: "CREATE INDEX IF NOT EXISTS ON tbltemp (/large/*)", affectedRows: 0},
+		{name: "create_composite_index", sql: "CREATE COMPOSITE INDEX ON tbltemp (/name, /age DESC)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool {
+				composite, _ := policy["compositeIndexes"].([]interface{})
+				return len(composite) == 1
+			}},
+		{name: "drop_composite_index_other_order", sql: "DROP COMPOSITE INDEX ON tbltemp (/name, /age)", mustNotFound: true},
+		{name: "drop_composite_index", sql: "DROP COMPOSITE INDEX ON tbltemp (/name, /age DESC)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool {
+				composite, _ := policy["compositeIndexes"].([]interface{})
+				return len(composite) == 0
+			}},
+		{name: "create_spatial_index", sql: "CREATE SPATIAL INDEX ON tbltemp (/location/*)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool {
+				return _policyHasPath(policy, "spatialIndexes", "/location/*")
+			}},
+		{name: "drop_spatial_index", sql: "DROP SPATIAL INDEX ON tbltemp (/location/*)", affectedRows: 1,
+			verify: func(policy map[string]interface{}) bool {
+				return !_policyHasPath(policy, "spatialIndexes", "/location/*")
+			}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			execResult, err := db.Exec(testCase.sql)
+			if testCase.mustConflict && !errors.Is(err, gocosmos.ErrConflict) {
+				t.Fatalf("%s failed: expect ErrConflict but received %#v", testName+"/"+testCase.name+"/exec", err)
+			}
+			if testCase.mustNotFound && !errors.Is(err, gocosmos.ErrNotFound) {
+				t.Fatalf("%s failed: expect ErrNotFound but received %#v", testName+"/"+testCase.name+"/exec", err)
+			}
+			if testCase.mustConflict || testCase.mustNotFound {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name+"/exec", err)
+			}
+			affectedRows, err := execResult.RowsAffected()
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name+"/rows_affected", err)
+			}
+			if affectedRows != testCase.affectedRows {
+				t.Fatalf("%s failed: expected %#v affected-rows but received %#v", testName+"/"+testCase.name, testCase.affectedRows, affectedRows)
+			}
+			if testCase.verify != nil {
+				if policy := _getIndexingPolicy(t, testName, client, dbname, "tbltemp"); !testCase.verify(policy) {
+					t.Fatalf("%s failed: received indexing policy %#v", testName+"/"+testCase.name, policy)
+				}
+			}
+		})
+	}
+}
+
+func TestStmtShowIndexProgress_Exec(t *testing.T) {
+	testName := "TestStmtShowIndexProgress_Exec"
+	db := _openDb(t, testName)
+	_, err := db.Exec("SHOW INDEX PROGRESS dbtemp.tbltemp")
+	if !errors.Is(err, gocosmos.ErrExecNotSupported) {
+		t.Fatalf("%s failed: expected ErrExecNotSupported, but received %#v", testName, err)
+	}
+}
+
+func TestStmtShowIndexProgress_Query(t *testing.T) {
+	testName := "TestStmtShowIndexProgress_Query"
+	db := _openDb(t, testName)
+	dbname := "dbtemp"
+	defer func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	}()
+	_, _ = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", dbname))
+	for _, sql := range []string{fmt.Sprintf("CREATE DATABASE %s", dbname), fmt.Sprintf("CREATE COLLECTION %s.tbltemp WITH pk=/id", dbname)} {
+		if _, err := db.Exec(sql); err != nil {
+			t.Fatalf("%s failed: {error: %s / sql: %s}", testName+"/init", err, sql)
+		}
+	}
+	dbRows, err := db.Query(fmt.Sprintf("SHOW INDEX PROGRESS %s.tbltemp", dbname))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/query", err)
+	}
+	rows, err := _fetchAllRows(dbRows)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName+"/fetch", err)
+	}
+	if len(rows) != 1 || rows[0]["id"] != "tbltemp" || rows[0]["indexingMode"] != "consistent" || rows[0]["progress"] == nil {
+		t.Fatalf("%s failed: received %#v", testName, rows)
+	}
+
+	_, err = db.Query(fmt.Sprintf("SHOW INDEX PROGRESS %s.tblnotfound", dbname))
+	if !errors.Is(err, gocosmos.ErrNotFound) {
+		t.Fatalf("%s failed: expected ErrNotFound, but received %#v", testName, err)
+	}
+}
@@ -0,0 +1,287 @@
+package gocosmos
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+	"unicode/utf8"
+)
+
+// Effective partition key (EPK): the hash of the partition key values of a document, which determines the partition key
+// range holding the document. Partition key ranges are delimited by EPK values (see PkrangeInfo.MinInclusive and
+// PkrangeInfo.MaxExclusive), which are upper-case hex strings that sort as strings.
+
+// types of partition key components, written before the component when it is encoded.
+const (
+	pkComponentNull   byte = 0x01
+	pkComponentFalse  byte = 0x02
+	pkComponentTrue   byte = 0x03
+	pkComponentNumber byte = 0x05
+	pkComponentString byte = 0x08
+)
+
+const pkMaxStringChars = 100 // strings are truncated to this number of characters by the hash V1
+
+// _effectivePartitionKey computes the EPK of the partition key values of a document, for the partition key definition
+// of its collection (kind Hash version 1 or 2, or MultiHash for hierarchical partition keys). Values must be strings,
+// numbers, booleans or nil.
+func _effectivePartitionKey(pkInfo PkInfo, pkValues []interface{}) (string, error) {
+	if len(pkValues) == 0 || len(pkValues) != len(pkInfo.Paths()) {
+		return "", fmt.Errorf("expected %d partition key values, got %d", len(pkInfo.Paths()), len(pkValues))
+	}
+	components := make([]interface{}, len(pkValues))
+	for i, v := range pkValues {
+		switch v.(type) {
+		case nil, string, bool:
+			components[i] = v
+		default:
+			number, ok := _toNumber(v)
+			if !ok {
+				return "", fmt.Errorf("unsupported partition key value of type %T", v)
+			}
+			components[i] = number
+		}
+	}
+	switch kind := pkInfo.Kind(); {
+	case kind == "MultiHash":
+		var sb strings.Builder
+		for _, v := range components {
+			sb.WriteString(_epkHashV2([]interface{}{v}))
+		}
+		return sb.String(), nil
+	case kind == "Hash" && pkInfo.Version() == 2:
+		return _epkHashV2(components), nil
+	case kind == "Hash" || kind == "":
+		return _epkHashV1(components), nil
+	default:
+		return "", fmt.Errorf("unsupported partition key kind %s", kind)
+	}
+}
+
+// _epkHashV2 computes the EPK of the hash V2: the 128-bit MurmurHash3 of the components, with the 2 most significant
+// bits cleared.
+func _epkHashV2(components []interface{}) string {
+	var buf bytes.Buffer
+	for _, v := range components {
+		switch v := v.(type) {
+		case nil:
+			buf.WriteByte(pkComponentNull)
+		case bool:
+			buf.WriteByte(_pkBoolComponent(v))
+		case float64:
+			buf.WriteByte(pkComponentNumber)
+			_ = binary.Write(&buf, binary.LittleEndian, v)
+		case string:
+			buf.WriteByte(pkComponentString)
+			buf.WriteString(v)
+			buf.WriteByte(0xFF)
+		}
+	}
+	h1, h2 := _murmur3x64x128(buf.Bytes(), 0)
+	hash := make([]byte, 16)
+	binary.BigEndian.PutUint64(hash, h2)
+	binary.BigEndian.PutUint64(hash[8:], h1)
+	hash[0] &= 0x3F
+	return strings.ToUpper(hex.EncodeToString(hash))
+}
+
+// _epkHashV1 computes the EPK of the hash V1: the binary encoding of the 32-bit MurmurHash3 of the components (strings
+// being truncated to 100 characters), followed by the binary encoding of the components.
+func _epkHashV1(components []interface{}) string {
+	var hashed, encoded bytes.Buffer
+	for _, v := range components {
+		switch v := v.(type) {
+		case nil:
+			hashed.WriteByte(pkComponentNull)
+		case bool:
+			hashed.WriteByte(_pkBoolComponent(v))
+		case float64:
+			hashed.WriteByte(pkComponentNumber)
+			_ = binary.Write(&hashed, binary.LittleEndian, v)
+		case string:
+			if utf8.RuneCountInString(v) > pkMaxStringChars {
+				v = string([]rune(v)[:pkMaxStringChars])
+			}
+			hashed.WriteByte(pkComponentString)
+			hashed.WriteString(v)
+			hashed.WriteByte(0x00)
+		}
+	}
+	_writePkBinaryEncoding(&encoded, float64(_murmur3x86x32(hashed.Bytes(), 0)))
+	for _, v := range components {
+		if s, ok := v.(string); ok && utf8.RuneCountInString(s) > pkMaxStringChars {
+			v = string([]rune(s)[:pkMaxStringChars])
+		}
+		_writePkBinaryEncoding(&encoded, v)
+	}
+	return strings.ToUpper(hex.EncodeToString(encoded.Bytes()))
+}
+
+func _pkBoolComponent(v bool) byte {
+	if v {
+		return pkComponentTrue
+	}
+	return pkComponentFalse
+}
+
+// _writePkBinaryEncoding writes the order-preserving binary encoding of a partition key component, used by the hash V1.
+func _writePkBinaryEncoding(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(pkComponentNull)
+	case bool:
+		buf.WriteByte(_pkBoolComponent(v))
+	case float64:
+		buf.WriteByte(pkComponentNumber)
+		payload := math.Float64bits(v)
+		if payload < 1<<63 {
+			payload ^= 1 << 63
+		} else {
+			payload = ^payload + 1
+		}
+		buf.WriteByte(byte(payload >> 56))
+		payload <<= 8
+		var b byte
+		for first := true; payload != 0; payload <<= 7 {
+			if !first {
+				buf.WriteByte(b)
+			}
+			first = false
+			b = byte(payload>>56) | 0x01
+		}
+		buf.WriteByte(b & 0xFE)
+	case string:
+		buf.WriteByte(pkComponentString)
+		data := []byte(v)
+		short := len(data) <= pkMaxStringChars
+		if !short {
+			data = data[:pkMaxStringChars+1]
+		}
+		for _, b := range data {
+			if b < 0xFF {
+				b++
+			}
+			buf.WriteByte(b)
+		}
+		if short {
+			buf.WriteByte(0x00)
+		}
+	}
+}
+
+// _pkrangeOf returns the id of the partition key range holding the EPK, or "" if no range holds it.
+func _pkrangeOf(pkranges []PkrangeInfo, epk string) string {
+	for _, pkrange := range pkranges {
+		if epk >= pkrange.MinInclusive && (epk < pkrange.MaxExclusive || pkrange.MaxExclusive == "FF") {
+			return pkrange.Id
+		}
+	}
+	return ""
+}
+
+/*----------------------------------------------------------------------*/
+
+// _murmur3x86x32 computes the 32-bit MurmurHash3 (x86 variant) of data.
+func _murmur3x86x32(data []byte, seed uint32) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	h := seed
+	n := len(data) / 4 * 4
+	for i := 0; i < n; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+	var k uint32
+	switch tail := data[n:]; len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// _murmur3x64x128 computes the 128-bit MurmurHash3 (x64 variant) of data, returned as 2 64-bit halves.
+func _murmur3x64x128(data []byte, seed uint64) (uint64, uint64) {
+	const c1, c2 = 0x87c37b91114253d5, 0x4cf5ad432745937f
+	h1, h2 := seed, seed
+	n := len(data) / 16 * 16
+	for i := 0; i < n; i += 16 {
+		k1 := binary.LittleEndian.Uint64(data[i:])
+		k2 := binary.LittleEndian.Uint64(data[i+8:])
+		k1 *= c1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+		h1 = bits.RotateLeft64(h1, 27)
+		h1 += h2
+		h1 = h1*5 + 0x52dce729
+		k2 *= c2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+		h2 = bits.RotateLeft64(h2, 31)
+		h2 += h1
+		h2 = h2*5 + 0x38495ab5
+	}
+	var k1, k2 uint64
+	tail := data[n:]
+	for i := len(tail) - 1; i >= 8; i-- {
+		k2 = k2<<8 | uint64(tail[i])
+	}
+	if len(tail) > 8 {
+		k2 *= c2
+		k2 = bits.RotateLeft64(k2, 33)
+		k2 *= c1
+		h2 ^= k2
+		tail = tail[:8]
+	}
+	for i := len(tail) - 1; i >= 0; i-- {
+		k1 = k1<<8 | uint64(tail[i])
+	}
+	if len(tail) > 0 {
+		k1 *= c1
+		k1 = bits.RotateLeft64(k1, 31)
+		k1 *= c2
+		h1 ^= k1
+	}
+	h1 ^= uint64(len(data))
+	h2 ^= uint64(len(data))
+	h1 += h2
+	h2 += h1
+	h1, h2 = _fmix64(h1), _fmix64(h2)
+	h1 += h2
+	h2 += h1
+	return h1, h2
+}
+
+func _fmix64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xff51afd7ed558ccd
+	k ^= k >> 33
+	k *= 0xc4ceb9fe1a85ec53
+	k ^= k >> 33
+	return k
+}
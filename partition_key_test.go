@@ -0,0 +1,69 @@
+package gocosmos
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestEffectivePartitionKey(t *testing.T) {
+	testName := "TestEffectivePartitionKey"
+	hashV1 := PkInfo{"paths": []interface{}{"/pk"}, "kind": "Hash"}
+	hashV2 := PkInfo{"paths": []interface{}{"/pk"}, "kind": "Hash", "version": 2}
+	testData := []struct {
+		name       string
+		value      interface{}
+		expectedV1 string
+		expectedV2 string
+	}{
+		{name: "empty_string", value: "", expectedV1: "05C1CF33970FF80800", expectedV2: "32E9366E637A71B4E710384B2F4970A0"},
+		{name: "string", value: "partitionKey", expectedV1: "05C1E1B3D9CD2608716273756A756A706F4C667A00", expectedV2: "013AEFCF77FA271571CF665A58C933F1"},
+		{name: "long_string", value: strings.Repeat("a", 1024), expectedV1: "05C1EB5921F70608" + strings.Repeat("62", 100) + "00", expectedV2: "332BDF5512AE49615F32C7D98C2DB86C"},
+		{name: "null", value: nil, expectedV1: "05C1ED45D7475601", expectedV2: "378867E4430E67857ACE5C908374FE16"},
+		{name: "true", value: true, expectedV1: "05C1D7C5A903D803", expectedV2: "0E711127C5B5A8E4726AC6DD306A3E59"},
+		{name: "false", value: false, expectedV1: "05C1DB857D857C02", expectedV2: "2FE1BE91E90A3439635E0E9E37361EF2"},
+		{name: "int8_min", value: int8(-128), expectedV1: "05C1D73349F54C053FA0", expectedV2: "01DAEDABF913540367FE219B2AD06148"},
+		{name: "int8_max", value: 127, expectedV1: "05C1DD539DDFCC05C05FE0", expectedV2: "0C507ACAC853ECA7977BF4CEFB562A25"},
+		{name: "int64_min", value: int64(math.MinInt64), expectedV1: "05C1DB35F33D1C053C20", expectedV2: "23D5C6395512BDFEAFADAD15328AD2BB"},
+		{name: "int64_max", value: int64(math.MaxInt64), expectedV1: "05C1B799AB2DD005C3E0", expectedV2: "2EDB959178DFCCA18983F89384D1629B"},
+		{name: "int32_min", value: int32(math.MinInt32), expectedV1: "05C1DFBF252BCC053E20", expectedV2: "0B1660D5233C3171725B30D4A5F4CC1F"},
+		{name: "int32_max", value: uint32(math.MaxInt32), expectedV1: "05C1E1F503DFB205C1DFFFFFFFFC", expectedV2: "2D9349D64712AEB5EB1406E2F0BE2725"},
+		{name: "double_epsilon", value: math.SmallestNonzeroFloat64, expectedV1: "05C1E5C91F4D3005800101010101010102", expectedV2: "0E6CBA63A280927DE485DEF865800139"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			if epk, err := _effectivePartitionKey(hashV1, []interface{}{testCase.value}); err != nil || epk != testCase.expectedV1 {
+				t.Fatalf("%s failed: <V1> expected %s but received %s (error %v)", testName+"/"+testCase.name, testCase.expectedV1, epk, err)
+			}
+			if epk, err := _effectivePartitionKey(hashV2, []interface{}{testCase.value}); err != nil || epk != testCase.expectedV2 {
+				t.Fatalf("%s failed: <V2> expected %s but received %s (error %v)", testName+"/"+testCase.name, testCase.expectedV2, epk, err)
+			}
+		})
+	}
+}
+
+func TestEffectivePartitionKey_multiHash(t *testing.T) {
+	testName := "TestEffectivePartitionKey_multiHash"
+	pkInfo := PkInfo{"paths": []interface{}{"/tenant", "/user"}, "kind": "MultiHash", "version": 2}
+	epk, err := _effectivePartitionKey(pkInfo, []interface{}{"", "partitionKey"})
+	if expected := "32E9366E637A71B4E710384B2F4970A0" + "013AEFCF77FA271571CF665A58C933F1"; err != nil || epk != expected {
+		t.Fatalf("%s failed: expected %s but received %s (error %v)", testName, expected, epk, err)
+	}
+
+	if _, err := _effectivePartitionKey(pkInfo, []interface{}{"a"}); err == nil {
+		t.Fatalf("%s failed: expected error for missing partition key value", testName)
+	}
+	if _, err := _effectivePartitionKey(pkInfo, []interface{}{"a", []interface{}{1}}); err == nil {
+		t.Fatalf("%s failed: expected error for unsupported partition key value", testName)
+	}
+}
+
+func TestPkrangeOf(t *testing.T) {
+	testName := "TestPkrangeOf"
+	pkranges := []PkrangeInfo{{Id: "0", MinInclusive: "", MaxExclusive: "1F"}, {Id: "1", MinInclusive: "1F", MaxExclusive: "FF"}}
+	for epk, expected := range map[string]string{"": "0", "013AEFCF77FA271571CF665A58C933F1": "0", "1F": "1", "32E9366E637A71B4E710384B2F4970A0": "1"} {
+		if pkrange := _pkrangeOf(pkranges, epk); pkrange != expected {
+			t.Fatalf("%s failed: <%s> expected range %s but received %s", testName, epk, expected, pkrange)
+		}
+	}
+}
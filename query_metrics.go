@@ -0,0 +1,114 @@
+package gocosmos
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QueryMetrics holds the execution metrics of a query, as reported by the server via the response header
+// "x-ms-documentdb-query-metrics". Metrics of multiple responses (e.g. pages of the result) can be aggregated with Add.
+//
+// See: https://learn.microsoft.com/azure/cosmos-db/nosql/query-metrics
+//
+// @Available since v1.2.0
+type QueryMetrics struct {
+	NumRequests            int     // number of query requests the metrics are aggregated from
+	RequestCharge          float64 // number of request units consumed by the query requests
+	RetrievedDocumentCount int64   // number of documents loaded by the query engine
+	RetrievedDocumentSize  int64   // total size, in bytes, of the documents loaded by the query engine
+	OutputDocumentCount    int64   // number of documents returned by the query
+	OutputDocumentSize     int64   // total size, in bytes, of the documents returned by the query
+	IndexHitRatio          float64 // ratio of retrieved documents that matched the filters using the index only (from 0 to 1)
+	TotalExecutionTimeMs   float64 // total time, in milliseconds, spent executing the query on the server
+	QueryPreparationTimeMs float64 // time, in milliseconds, spent compiling and optimizing the query
+	IndexLookupTimeMs      float64 // time, in milliseconds, spent in the index
+	DocumentLoadTimeMs     float64 // time, in milliseconds, spent loading documents
+	RuntimeExecutionTimeMs float64 // time, in milliseconds, spent executing the query in the runtime (VM)
+	DocumentWriteTimeMs    float64 // time, in milliseconds, spent writing the result documents
+}
+
+// _parseQueryMetrics parses the value of the response header "x-ms-documentdb-query-metrics", which is a list of
+// "<name>=<value>" separated by semicolons, e.g. "totalExecutionTimeInMs=0.57;retrievedDocumentCount=1;...".
+// Unknown and malformed metrics are ignored.
+func _parseQueryMetrics(header string) *QueryMetrics {
+	m := &QueryMetrics{NumRequests: 1}
+	for _, pair := range strings.Split(header, ";") {
+		tokens := strings.SplitN(pair, "=", 2)
+		if len(tokens) != 2 {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(tokens[1]), 64)
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(tokens[0]) {
+		case "retrievedDocumentCount":
+			m.RetrievedDocumentCount = int64(v)
+		case "retrievedDocumentSize":
+			m.RetrievedDocumentSize = int64(v)
+		case "outputDocumentCount":
+			m.OutputDocumentCount = int64(v)
+		case "outputDocumentSize":
+			m.OutputDocumentSize = int64(v)
+		case "indexUtilizationRatio":
+			m.IndexHitRatio = v
+		case "totalExecutionTimeInMs":
+			m.TotalExecutionTimeMs = v
+		case "queryCompileTimeInMs", "queryLogicalPlanBuildTimeInMs", "queryPhysicalPlanBuildTimeInMs", "queryOptimizationTimeInMs":
+			m.QueryPreparationTimeMs += v
+		case "indexLookupTimeInMs":
+			m.IndexLookupTimeMs = v
+		case "documentLoadTimeInMs":
+			m.DocumentLoadTimeMs = v
+		case "VMExecutionTimeInMs":
+			m.RuntimeExecutionTimeMs = v
+		case "writeOutputTimeInMs":
+			m.DocumentWriteTimeMs = v
+		}
+	}
+	return m
+}
+
+// Add aggregates the metrics of another query response into this one. Counts, sizes, times and request charges are
+// summed up, the index hit ratio is weighted by the number of retrieved documents.
+//
+// @Available since v1.2.0
+func (m *QueryMetrics) Add(other *QueryMetrics) {
+	if other == nil {
+		return
+	}
+	if total := m.RetrievedDocumentCount + other.RetrievedDocumentCount; total > 0 {
+		m.IndexHitRatio = (m.IndexHitRatio*float64(m.RetrievedDocumentCount) + other.IndexHitRatio*float64(other.RetrievedDocumentCount)) / float64(total)
+	} else if m.NumRequests == 0 {
+		m.IndexHitRatio = other.IndexHitRatio
+	}
+	m.NumRequests += other.NumRequests
+	m.RequestCharge += other.RequestCharge
+	m.RetrievedDocumentCount += other.RetrievedDocumentCount
+	m.RetrievedDocumentSize += other.RetrievedDocumentSize
+	m.OutputDocumentCount += other.OutputDocumentCount
+	m.OutputDocumentSize += other.OutputDocumentSize
+	m.TotalExecutionTimeMs += other.TotalExecutionTimeMs
+	m.QueryPreparationTimeMs += other.QueryPreparationTimeMs
+	m.IndexLookupTimeMs += other.IndexLookupTimeMs
+	m.DocumentLoadTimeMs += other.DocumentLoadTimeMs
+	m.RuntimeExecutionTimeMs += other.RuntimeExecutionTimeMs
+	m.DocumentWriteTimeMs += other.DocumentWriteTimeMs
+}
+
+// _mergeQueryMetrics returns a new map holding the metrics of both maps, aggregated per partition key range.
+func _mergeQueryMetrics(a, b map[string]*QueryMetrics) map[string]*QueryMetrics {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	result := make(map[string]*QueryMetrics, len(a)+len(b))
+	for _, metrics := range []map[string]*QueryMetrics{a, b} {
+		for pkRangeId, m := range metrics {
+			if result[pkRangeId] == nil {
+				result[pkRangeId] = &QueryMetrics{}
+			}
+			result[pkRangeId].Add(m)
+		}
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQueryMetrics_parse(t *testing.T) {
+	testName := "TestQueryMetrics_parse"
+	header := "totalExecutionTimeInMs=1.50;queryCompileTimeInMs=0.10;queryLogicalPlanBuildTimeInMs=0.02;queryPhysicalPlanBuildTimeInMs=0.03;queryOptimizationTimeInMs=0.05;VMExecutionTimeInMs=0.80;indexLookupTimeInMs=0.20;documentLoadTimeInMs=0.40;systemFunctionExecuteTimeInMs=0.00;userFunctionExecuteTimeInMs=0.00;retrievedDocumentCount=10;retrievedDocumentSize=2048;outputDocumentCount=4;outputDocumentSize=512;writeOutputTimeInMs=0.01;indexUtilizationRatio=0.40;unknown=x;malformed"
+	m := _parseQueryMetrics(header)
+	expected := &QueryMetrics{
+		NumRequests:            1,
+		RetrievedDocumentCount: 10,
+		RetrievedDocumentSize:  2048,
+		OutputDocumentCount:    4,
+		OutputDocumentSize:     512,
+		IndexHitRatio:          0.4,
+		TotalExecutionTimeMs:   1.5,
+		QueryPreparationTimeMs: m.QueryPreparationTimeMs,
+		IndexLookupTimeMs:      0.2,
+		DocumentLoadTimeMs:     0.4,
+		RuntimeExecutionTimeMs: 0.8,
+		DocumentWriteTimeMs:    0.01,
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, m)
+	}
+	if m.QueryPreparationTimeMs < 0.1999 || m.QueryPreparationTimeMs > 0.2001 {
+		t.Fatalf("%s failed: expected query preparation time 0.2 but received %f", testName, m.QueryPreparationTimeMs)
+	}
+}
+
+func TestQueryMetrics_Add(t *testing.T) {
+	testName := "TestQueryMetrics_Add"
+	m1 := &QueryMetrics{NumRequests: 1, RequestCharge: 2.5, RetrievedDocumentCount: 30, OutputDocumentCount: 3, IndexHitRatio: 1, TotalExecutionTimeMs: 1}
+	m2 := &QueryMetrics{NumRequests: 1, RequestCharge: 3.5, RetrievedDocumentCount: 10, OutputDocumentCount: 1, IndexHitRatio: 0.2, TotalExecutionTimeMs: 2}
+	merged := _mergeQueryMetrics(map[string]*QueryMetrics{"0": m1}, map[string]*QueryMetrics{"0": m2, "1": m2})
+	if len(merged) != 2 || merged["0"] == m1 {
+		t.Fatalf("%s failed: received %#v", testName, merged)
+	}
+	expected := &QueryMetrics{NumRequests: 2, RequestCharge: 6, RetrievedDocumentCount: 40, OutputDocumentCount: 4, IndexHitRatio: 0.8, TotalExecutionTimeMs: 3}
+	if !reflect.DeepEqual(merged["0"], expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, merged["0"])
+	}
+	if !reflect.DeepEqual(merged["1"], m2) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, m2, merged["1"])
+	}
+	if m1.NumRequests != 1 || m1.RetrievedDocumentCount != 30 {
+		t.Fatalf("%s failed: source metrics must not be modified, received %#v", testName, m1)
+	}
+}
+
+func TestRespQueryPlan_TargetPkranges(t *testing.T) {
+	testName := "TestRespQueryPlan_TargetPkranges"
+	pkranges := []PkrangeInfo{{Id: "0", MinInclusive: "", MaxExclusive: "3F"}, {Id: "1", MinInclusive: "3F", MaxExclusive: "7F"}, {Id: "2", MinInclusive: "7F", MaxExclusive: "FF"}}
+	testData := []struct {
+		name     string
+		ranges   []QueryRange
+		expected []string
+	}{
+		{name: "all", ranges: []QueryRange{{Min: "", Max: "FF", IsMinInclusive: true}}, expected: []string{"0", "1", "2"}},
+		{name: "point", ranges: []QueryRange{{Min: "05C1", Max: "05C1", IsMinInclusive: true, IsMaxInclusive: true}}, expected: []string{"0"}},
+		{name: "boundary", ranges: []QueryRange{{Min: "3F", Max: "3F", IsMinInclusive: true, IsMaxInclusive: true}}, expected: []string{"1"}},
+		{name: "multiple_points", ranges: []QueryRange{{Min: "05", Max: "05", IsMinInclusive: true, IsMaxInclusive: true}, {Min: "A0", Max: "A0", IsMinInclusive: true, IsMaxInclusive: true}}, expected: []string{"0", "2"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			queryPlan := &RespQueryPlan{QueryRanges: testCase.ranges}
+			ids := make([]string, 0)
+			for _, pkrange := range queryPlan.TargetPkranges(pkranges) {
+				ids = append(ids, pkrange.Id)
+			}
+			if !reflect.DeepEqual(ids, testCase.expected) {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, ids)
+			}
+		})
+	}
+}
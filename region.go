@@ -0,0 +1,246 @@
+package gocosmos
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btnguyen2k/consu/gjrc"
+)
+
+const (
+	// defaultRegionRefreshInterval is the default interval at which the regions of the account are re-read.
+	defaultRegionRefreshInterval = 5 * time.Minute
+
+	// regionUnavailableDuration is how long a regional endpoint that failed is tried last.
+	regionUnavailableDuration = 5 * time.Minute
+
+	// subStatusWriteForbidden is the sub-status of a "403 Forbidden" response sent when writes are submitted to a
+	// region that is not (or no longer) a write region.
+	subStatusWriteForbidden = "3"
+)
+
+// _normalizeRegion normalizes a region name for comparison, e.g. "East US" and "eastus" are the same region.
+func _normalizeRegion(name string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(name), " ", ""))
+}
+
+// regionTopology captures the regions of a Cosmos DB account.
+type regionTopology struct {
+	WritableLocations            []AccountLocation
+	ReadableLocations            []AccountLocation
+	EnableMultipleWriteLocations bool
+}
+
+// regionRouter selects the regional endpoint a request is sent to: reads go to the preferred regions (in order of
+// preference), writes go to the write region (or to the preferred write regions if the account has multiple write
+// regions). Regional endpoints that failed are tried last for a while.
+//
+// regionRouter is safe for concurrent use.
+type regionRouter struct {
+	lock             sync.RWMutex
+	defaultEndpoint  string
+	preferredRegions []string // normalized names of the preferred regions, in order of preference
+	refreshInterval  time.Duration
+	lastRefresh      time.Time
+	topology         regionTopology
+	unavailable      map[string]time.Time // regional endpoint -> time it was marked as unavailable
+}
+
+func newRegionRouter(defaultEndpoint string, preferredRegions []string, refreshInterval time.Duration) *regionRouter {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultRegionRefreshInterval
+	}
+	r := &regionRouter{
+		defaultEndpoint: defaultEndpoint,
+		refreshInterval: refreshInterval,
+		unavailable:     make(map[string]time.Time),
+	}
+	for _, region := range preferredRegions {
+		if region = _normalizeRegion(region); region != "" {
+			r.preferredRegions = append(r.preferredRegions, region)
+		}
+	}
+	return r
+}
+
+// claimRefresh returns true if the topology must be refreshed, i.e. it is older than the refresh interval or force is
+// true. The refresh is claimed so that concurrent callers do not refresh the topology at the same time.
+func (r *regionRouter) claimRefresh(force bool) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := time.Now()
+	if !force && now.Sub(r.lastRefresh) < r.refreshInterval {
+		return false
+	}
+	r.lastRefresh = now
+	return true
+}
+
+// update replaces the known topology of the account with the regions of the supplied account.
+func (r *regionRouter) update(account DatabaseAccount) {
+	trimEndpoints := func(locs []AccountLocation) []AccountLocation {
+		result := make([]AccountLocation, len(locs))
+		for i, loc := range locs {
+			result[i] = AccountLocation{Name: loc.Name, Endpoint: strings.TrimSuffix(loc.Endpoint, "/")}
+		}
+		return result
+	}
+	topology := regionTopology{
+		WritableLocations:            trimEndpoints(account.WritableLocations),
+		ReadableLocations:            trimEndpoints(account.ReadableLocations),
+		EnableMultipleWriteLocations: account.EnableMultipleWriteLocations,
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.topology = topology
+}
+
+// markUnavailable marks a regional endpoint as unavailable, it is tried last until regionUnavailableDuration elapses.
+func (r *regionRouter) markUnavailable(endpoint string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.unavailable[endpoint] = time.Now()
+}
+
+// endpoints returns the regional endpoints a request can be sent to, the most suitable first. The default endpoint
+// is returned if the topology of the account is not known.
+func (r *regionRouter) endpoints(isWrite bool) []AccountLocation {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	locs, usePreferences := r.topology.ReadableLocations, true
+	if isWrite {
+		// with a single write region, writes must go to that region regardless of the preferences
+		locs, usePreferences = r.topology.WritableLocations, r.topology.EnableMultipleWriteLocations
+	}
+	if len(locs) == 0 {
+		return []AccountLocation{{Endpoint: r.defaultEndpoint}}
+	}
+
+	ordered := make([]AccountLocation, 0, len(locs))
+	used := make(map[int]bool)
+	if usePreferences {
+		for _, region := range r.preferredRegions {
+			for i, loc := range locs {
+				if !used[i] && _normalizeRegion(loc.Name) == region {
+					ordered = append(ordered, loc)
+					used[i] = true
+				}
+			}
+		}
+	}
+	for i, loc := range locs {
+		if !used[i] {
+			ordered = append(ordered, loc)
+		}
+	}
+
+	available := make([]AccountLocation, 0, len(ordered))
+	var unavailable []AccountLocation
+	now := time.Now()
+	for _, loc := range ordered {
+		if t, ok := r.unavailable[loc.Endpoint]; ok && now.Sub(t) < regionUnavailableDuration {
+			unavailable = append(unavailable, loc)
+		} else {
+			available = append(available, loc)
+		}
+	}
+	return append(available, unavailable...)
+}
+
+// regionOf returns the name of the region served by the supplied host, empty string if the host is not a known
+// regional endpoint.
+func (r *regionRouter) regionOf(host string) string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	for _, locs := range [][]AccountLocation{r.topology.WritableLocations, r.topology.ReadableLocations} {
+		for _, loc := range locs {
+			if u, err := url.Parse(loc.Endpoint); err == nil && strings.EqualFold(u.Host, host) {
+				return loc.Name
+			}
+		}
+	}
+	return ""
+}
+
+// _isWriteRequest returns true if the request modifies resources. Queries are submitted with POST but are reads.
+func _isWriteRequest(req *http.Request) bool {
+	if req.Method == "GET" || req.Method == "HEAD" {
+		return false
+	}
+	return !strings.EqualFold(req.Header.Get(restApiHeaderIsQuery), "true") &&
+		!strings.EqualFold(req.Header.Get(restApiHeaderIsQueryPlanRequest), "true")
+}
+
+// _shouldFailover checks if a request must be retried in another region: the region is unavailable (503), writes
+// are forbidden in the region (403, sub-status 3) or, for reads, the region cannot be reached. refresh is true if
+// the topology of the account has likely changed.
+func _shouldFailover(resp *gjrc.GjrcResponse, isWrite bool) (failover, refresh bool) {
+	httpResp := resp.HttpResponse()
+	if httpResp == nil {
+		// network error: retrying a write in another region could apply it twice
+		return !isWrite, false
+	}
+	switch httpResp.StatusCode {
+	case http.StatusServiceUnavailable:
+		return true, false
+	case http.StatusForbidden:
+		if isWrite && httpResp.Header.Get(respHeaderSubStatus) == subStatusWriteForbidden {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// _routeRequest points the request to the supplied endpoint.
+func _routeRequest(req *http.Request, endpoint string) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return
+	}
+	req.URL.Scheme = u.Scheme
+	req.URL.Host = u.Host
+	req.Host = ""
+}
+
+// refreshRegions re-reads the topology of the account if it is expired (or if force is true). Errors are ignored,
+// the known topology is kept until the next refresh.
+func (c *RestClient) refreshRegions(force bool) {
+	if !c.router.claimRefresh(force) {
+		return
+	}
+	if result := c.GetDatabaseAccount(); result.Error() == nil {
+		c.router.update(result.DatabaseAccount)
+	}
+}
+
+// doRoutedRequest sends the request to the most suitable regional endpoint and fails over to the next one if the
+// region is unavailable.
+func (c *RestClient) doRoutedRequest(req *http.Request) *gjrc.GjrcResponse {
+	c.refreshRegions(false)
+	isWrite := _isWriteRequest(req)
+	maxAttempts, refreshed := len(c.router.endpoints(isWrite)), false
+	for attempt := 1; ; attempt++ {
+		loc := c.router.endpoints(isWrite)[0]
+		_routeRequest(req, loc.Endpoint)
+		resp := c.doRequestWithRetries(req)
+		failover, refresh := _shouldFailover(resp, isWrite)
+		if refresh && !refreshed {
+			// the write region has likely changed: give the new topology a chance
+			c.router.markUnavailable(loc.Endpoint)
+			c.refreshRegions(true)
+			maxAttempts, refreshed = attempt+len(c.router.endpoints(isWrite)), true
+		}
+		if !failover || attempt >= maxAttempts || req.GetBody == nil {
+			return resp
+		}
+		c.router.markUnavailable(loc.Endpoint)
+		body, err := req.GetBody()
+		if err != nil {
+			return resp
+		}
+		req.Body = body
+	}
+}
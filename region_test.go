@@ -0,0 +1,203 @@
+package gocosmos
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+)
+
+func _regionNames(locs []AccountLocation) []string {
+	names := make([]string, 0, len(locs))
+	for _, loc := range locs {
+		names = append(names, loc.Name)
+	}
+	return names
+}
+
+func TestRegionRouter_endpoints(t *testing.T) {
+	testName := "TestRegionRouter_endpoints"
+	topology := DatabaseAccount{
+		WritableLocations: []AccountLocation{{Name: "West US", Endpoint: "https://acc-westus.documents.azure.com:443/"}, {Name: "East US", Endpoint: "https://acc-eastus.documents.azure.com:443/"}},
+		ReadableLocations: []AccountLocation{
+			{Name: "West US", Endpoint: "https://acc-westus.documents.azure.com:443/"},
+			{Name: "East US", Endpoint: "https://acc-eastus.documents.azure.com:443/"},
+			{Name: "North Europe", Endpoint: "https://acc-northeurope.documents.azure.com:443/"},
+		},
+	}
+	testData := []struct {
+		name       string
+		preferred  []string
+		multiWrite bool
+		isWrite    bool
+		expected   []string
+	}{
+		{name: "read_no_preference", isWrite: false, expected: []string{"West US", "East US", "North Europe"}},
+		{name: "read_preferred", preferred: []string{"northeurope", "East US"}, expected: []string{"North Europe", "East US", "West US"}},
+		{name: "read_unknown_preferred", preferred: []string{"Japan East", "East US"}, expected: []string{"East US", "West US", "North Europe"}},
+		{name: "write_single_region", preferred: []string{"East US"}, isWrite: true, expected: []string{"West US", "East US"}},
+		{name: "write_multi_region", preferred: []string{"East US"}, multiWrite: true, isWrite: true, expected: []string{"East US", "West US"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			router := newRegionRouter("https://acc.documents.azure.com", testCase.preferred, 0)
+			topology.EnableMultipleWriteLocations = testCase.multiWrite
+			router.update(topology)
+			if names := _regionNames(router.endpoints(testCase.isWrite)); !reflect.DeepEqual(names, testCase.expected) {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, names)
+			}
+		})
+	}
+}
+
+func TestRegionRouter_markUnavailable(t *testing.T) {
+	testName := "TestRegionRouter_markUnavailable"
+	router := newRegionRouter("https://acc.documents.azure.com", []string{"East US", "West US"}, 0)
+	if locs := router.endpoints(false); len(locs) != 1 || locs[0].Endpoint != "https://acc.documents.azure.com" {
+		t.Fatalf("%s failed: expected the default endpoint but received %#v", testName, locs)
+	}
+	router.update(DatabaseAccount{
+		WritableLocations: []AccountLocation{{Name: "West US", Endpoint: "https://acc-westus.documents.azure.com:443/"}},
+		ReadableLocations: []AccountLocation{{Name: "West US", Endpoint: "https://acc-westus.documents.azure.com:443/"}, {Name: "East US", Endpoint: "https://acc-eastus.documents.azure.com:443/"}},
+	})
+	router.markUnavailable("https://acc-eastus.documents.azure.com:443")
+	if names := _regionNames(router.endpoints(false)); !reflect.DeepEqual(names, []string{"West US", "East US"}) {
+		t.Fatalf("%s failed: received %#v", testName, names)
+	}
+	if region := router.regionOf("acc-eastus.documents.azure.com:443"); region != "East US" {
+		t.Fatalf("%s failed: expected region %#v but received %#v", testName, "East US", region)
+	}
+	if region := router.regionOf("acc.documents.azure.com"); region != "" {
+		t.Fatalf("%s failed: expected no region but received %#v", testName, region)
+	}
+}
+
+func TestIsWriteRequest(t *testing.T) {
+	testName := "TestIsWriteRequest"
+	testData := []struct {
+		name     string
+		method   string
+		headers  map[string]string
+		expected bool
+	}{
+		{name: "get", method: "GET", expected: false},
+		{name: "create", method: "POST", expected: true},
+		{name: "replace", method: "PUT", expected: true},
+		{name: "delete", method: "DELETE", expected: true},
+		{name: "query", method: "POST", headers: map[string]string{restApiHeaderIsQuery: "true"}, expected: false},
+		{name: "query_plan", method: "POST", headers: map[string]string{restApiHeaderIsQueryPlanRequest: "True"}, expected: false},
+	}
+	for _, testCase := range testData {
+		req, _ := http.NewRequest(testCase.method, "https://localhost:8081/dbs", nil)
+		for k, v := range testCase.headers {
+			req.Header.Set(k, v)
+		}
+		if isWrite := _isWriteRequest(req); isWrite != testCase.expected {
+			t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, isWrite)
+		}
+	}
+}
+
+// _newRegionServers starts a global endpoint returning the supplied topology (built from the regional servers'
+// URLs) and regional servers replying with the supplied handlers.
+func _newRegionServers(t *testing.T, handlers map[string]http.HandlerFunc, topology func(urls map[string]string) DatabaseAccount) (*httptest.Server, map[string]*int32) {
+	urls := make(map[string]string)
+	hits := make(map[string]*int32)
+	for region, handler := range handlers {
+		counter, handler := new(int32), handler
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(counter, 1)
+			handler(w, r)
+		}))
+		t.Cleanup(server.Close)
+		urls[region], hits[region] = server.URL+"/", counter
+	}
+	global := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			t.Errorf("request %s %s must be routed to a regional endpoint", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		js, _ := json.Marshal(topology(urls))
+		_, _ = w.Write(js)
+	}))
+	t.Cleanup(global.Close)
+	return global, hits
+}
+
+func _replyDb(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(`{"id":"db1","_rid":"rid1"}`))
+}
+
+func TestRestClient_regionFailover(t *testing.T) {
+	testName := "TestRestClient_regionFailover"
+	global, hits := _newRegionServers(t, map[string]http.HandlerFunc{
+		"East US": func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusServiceUnavailable) },
+		"West US": _replyDb,
+	}, func(urls map[string]string) DatabaseAccount {
+		return DatabaseAccount{
+			WritableLocations: []AccountLocation{{Name: "West US", Endpoint: urls["West US"]}},
+			ReadableLocations: []AccountLocation{{Name: "West US", Endpoint: urls["West US"]}, {Name: "East US", Endpoint: urls["East US"]}},
+		}
+	})
+	cfg := NewConfig(global.URL, "ZGVtbw==")
+	cfg.PreferredRegions = []string{"East US", "West US"}
+	client, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	// the preferred region is unavailable: the read fails over to the next region
+	result := client.GetDatabase("db1")
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetDatabase", err)
+	}
+	if result.Region != "West US" || atomic.LoadInt32(hits["East US"]) != 1 {
+		t.Fatalf("%s failed: expected response from West US after 1 attempt in East US, received response from %#v after %d attempts", testName, result.Region, atomic.LoadInt32(hits["East US"]))
+	}
+	// the unavailable region is now tried last
+	if result = client.GetDatabase("db1"); result.Error() != nil || result.Region != "West US" || atomic.LoadInt32(hits["East US"]) != 1 {
+		t.Fatalf("%s failed: received response from %#v (error %s) after %d attempts in East US", testName, result.Region, result.Error(), atomic.LoadInt32(hits["East US"]))
+	}
+	// writes go to the write region
+	if result := client.CreateDatabase(DatabaseSpec{Id: "db1"}); result.Error() != nil || result.Region != "West US" {
+		t.Fatalf("%s failed: received response from %#v (error %s)", testName+"/CreateDatabase", result.Region, result.Error())
+	}
+}
+
+func TestRestClient_regionWriteForbidden(t *testing.T) {
+	testName := "TestRestClient_regionWriteForbidden"
+	var numTopologyReads int32
+	global, hits := _newRegionServers(t, map[string]http.HandlerFunc{
+		"East US": func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set(respHeaderSubStatus, subStatusWriteForbidden)
+			w.WriteHeader(http.StatusForbidden)
+		},
+		"West US": _replyDb,
+	}, func(urls map[string]string) DatabaseAccount {
+		// the write region moves from East US to West US after the first read of the topology
+		writeRegion := "East US"
+		if atomic.AddInt32(&numTopologyReads, 1) > 1 {
+			writeRegion = "West US"
+		}
+		return DatabaseAccount{
+			WritableLocations: []AccountLocation{{Name: writeRegion, Endpoint: urls[writeRegion]}},
+			ReadableLocations: []AccountLocation{{Name: "West US", Endpoint: urls["West US"]}, {Name: "East US", Endpoint: urls["East US"]}},
+		}
+	})
+	cfg := NewConfig(global.URL, "ZGVtbw==")
+	cfg.PreferredRegions = []string{"West US"}
+	client, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	result := client.CreateDatabase(DatabaseSpec{Id: "db1"})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if result.Region != "West US" || atomic.LoadInt32(hits["East US"]) != 1 || atomic.LoadInt32(&numTopologyReads) != 2 {
+		t.Fatalf("%s failed: received response from %#v after %d attempts in East US and %d topology reads", testName, result.Region, atomic.LoadInt32(hits["East US"]), atomic.LoadInt32(&numTopologyReads))
+	}
+}
@@ -2,15 +2,12 @@ package gocosmos
 
 import (
 	"bytes"
-	"crypto/hmac"
-	"crypto/sha256"
+	"context"
 	"crypto/tls"
-	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"reflect"
 	"sort"
 	"strconv"
@@ -44,76 +41,149 @@ const (
 // httpClient is reused if supplied. Otherwise, a new http.Client instance is created.
 // connStr is expected to be in the following format:
 //
-//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>]
+//	AccountEndpoint=<cosmosdb-restapi-endpoint>;AccountKey=<account-key>[;TimeoutMs=<timeout-in-ms>][;Version=<cosmosdb-api-version>][;AutoId=<true/false>][;InsecureSkipVerify=<true/false>][;MaxRetries=<num-retries>][;ConsistencyLevel=<consistency-level>][;PreferredRegions=<region1>,<region2>...]
 //
-// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, and InsecureSkipVerify is false
+// If not supplied, default value for TimeoutMs is 10 seconds, Version is DefaultApiVersion (which is "2020-07-15"), AutoId is true, InsecureSkipVerify is false and MaxRetries is 0
 //
 // - AutoId is added since v0.1.2
 // - InsecureSkipVerify is added since v0.1.4
+// - MaxRetries is added since v1.2.0
+// - ConsistencyLevel is added since v1.2.0: default consistency level of read and query requests, one of Strong, BoundedStaleness, Session, ConsistentPrefix or Eventual
+// - PreferredRegions is added since v1.2.0: comma-separated list of preferred regions, enables region routing (see Config.PreferredRegions)
 func NewRestClient(httpClient *http.Client, connStr string) (*RestClient, error) {
-	params := make(map[string]string)
-	parts := strings.Split(connStr, ";")
-	for _, part := range parts {
-		tokens := strings.SplitN(part, "=", 2)
-		key := strings.ToUpper(strings.TrimSpace(tokens[0]))
-		if len(tokens) == 2 {
-			params[key] = strings.TrimSpace(tokens[1])
-		} else {
-			params[key] = ""
-		}
-	}
-	endpoint := strings.TrimSuffix(params[settingEndpoint], "/")
-	if endpoint == "" {
-		return nil, errors.New("AccountEndpoint not found in connection string")
+	params := _parseConnStr(connStr)
+	cfg, err := _configFromParams(params)
+	if err != nil {
+		return nil, err
 	}
-	accountKey := params[settingAccountKey]
-	if accountKey == "" {
-		return nil, errors.New("AccountKey not found in connection string")
+	cfg.HttpClient = httpClient
+	restClient, err := NewRestClientWithConfig(cfg)
+	if err != nil {
+		return nil, err
 	}
-	key, err := base64.StdEncoding.DecodeString(accountKey)
+	restClient.params = params
+	return restClient, nil
+}
+
+// NewRestClientWithConfig constructs a new RestClient instance from the supplied Config.
+//
+// @Available since v1.2.0
+func NewRestClientWithConfig(cfg Config) (*RestClient, error) {
+	credential, err := cfg.validate()
 	if err != nil {
-		return nil, fmt.Errorf("cannot base64 decode account key: %s", err)
+		return nil, err
 	}
-	timeoutMs, err := strconv.Atoi(params[settingTimeout])
-	if err != nil || timeoutMs < 0 {
-		timeoutMs = 10000
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
 	}
-	apiVersion := params[settingVersion]
+	apiVersion := cfg.ApiVersion
 	if apiVersion == "" {
 		apiVersion = DefaultApiVersion
 	}
-	autoId, err := strconv.ParseBool(params[settingAutoId])
-	if err != nil {
-		autoId = true
+	consistencyLevel, _ := _normalizeConsistencyLevel(cfg.ConsistencyLevel)
+	var sessions *SessionContainer
+	if cfg.TrackSessionTokens {
+		sessions = NewSessionContainer()
 	}
-	insecureSkipVerify, err := strconv.ParseBool(params[settingInsecureSkipVerify])
-	if err != nil {
-		insecureSkipVerify = false
+	endpoint := strings.TrimSuffix(cfg.Endpoint, "/")
+	var router *regionRouter
+	if len(cfg.PreferredRegions) > 0 {
+		router = newRegionRouter(endpoint, cfg.PreferredRegions, cfg.RegionRefreshInterval)
 	}
-	if httpClient == nil {
-		httpClient = &http.Client{
-			Timeout:   time.Duration(timeoutMs) * time.Millisecond,
-			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: insecureSkipVerify}},
+	var httpClient http.Client
+	if cfg.HttpClient != nil {
+		httpClient = *cfg.HttpClient
+	} else {
+		httpClient = http.Client{
+			Timeout:   timeout,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}},
 		}
 	}
+	if httpClient.Transport == nil {
+		httpClient.Transport = http.DefaultTransport
+	}
+	httpClient.Transport = &restTransport{base: httpClient.Transport, credential: credential, hook: cfg.RequestHook}
 	return &RestClient{
-		client:     gjrc.NewGjrc(httpClient, time.Duration(timeoutMs)*time.Millisecond),
-		endpoint:   endpoint,
-		authKey:    key,
-		apiVersion: apiVersion,
-		autoId:     autoId,
-		params:     params,
+		client:           gjrc.NewGjrc(&httpClient, timeout),
+		endpoint:         endpoint,
+		apiVersion:       apiVersion,
+		autoId:           cfg.AutoId,
+		maxRetries:       cfg.MaxRetries,
+		consistencyLevel: consistencyLevel,
+		preferredRegions: cfg.PreferredRegions,
+		router:           router,
+		sessions:         sessions,
+		params:           make(map[string]string),
 	}, nil
 }
 
 // RestClient is REST-based client for Azure Cosmos DB
 type RestClient struct {
-	client     *gjrc.Gjrc
-	endpoint   string            // Azure Cosmos DB endpoint
-	authKey    []byte            // Account key to authenticate
-	apiVersion string            // Azure Cosmos DB API version
-	autoId     bool              // if true and value for 'id' field is not specified, CreateDocument will automatically generate a new id for document
-	params     map[string]string // parsed parameters
+	client           *gjrc.Gjrc
+	endpoint         string            // Azure Cosmos DB endpoint
+	apiVersion       string            // Azure Cosmos DB API version
+	autoId           bool              // if true and value for 'id' field is not specified, CreateDocument will automatically generate a new id for document
+	maxRetries       int               // number of times a throttled request is retried
+	consistencyLevel string            // default consistency level of read and query requests
+	preferredRegions []string          // preferred regions, in order of preference
+	router           *regionRouter     // if not nil, requests are routed to regional endpoints
+	sessions         *SessionContainer // if not nil, session tokens are tracked and attached to read/query requests
+	params           map[string]string // parsed parameters
+}
+
+// _retryAfter parses the value of the "x-ms-retry-after-ms" header of a throttled response, default value is 100ms.
+func _retryAfter(retryAfterMs string) time.Duration {
+	if v, err := strconv.Atoi(retryAfterMs); err == nil && v > 0 {
+		return time.Duration(v) * time.Millisecond
+	}
+	return 100 * time.Millisecond
+}
+
+// doRequest sends the request to the server, throttled requests (status 429) are retried up to maxRetries times.
+// If region routing is enabled, the request is sent to the most suitable regional endpoint (see regionRouter).
+func (c *RestClient) doRequest(req *http.Request) *gjrc.GjrcResponse {
+	if c.router != nil {
+		return c.doRoutedRequest(req)
+	}
+	return c.doRequestWithRetries(req)
+}
+
+func (c *RestClient) doRequestWithRetries(req *http.Request) *gjrc.GjrcResponse {
+	for numRetries := 0; ; numRetries++ {
+		resp := c.client.Do(req)
+		if numRetries >= c.maxRetries || resp.HttpResponse() == nil || resp.StatusCode() != http.StatusTooManyRequests || req.GetBody == nil {
+			return resp
+		}
+		time.Sleep(_retryAfter(resp.HttpResponse().Header.Get(respHeaderRetryAfterMs)))
+		body, err := req.GetBody()
+		if err != nil {
+			return resp
+		}
+		req.Body = body
+	}
+}
+
+// sessionToken returns the supplied session token if not empty, otherwise the token tracked for the collection (if any).
+func (c *RestClient) sessionToken(dbName, collName, sessionToken string) string {
+	if sessionToken != "" {
+		return sessionToken
+	}
+	return c.sessions.Get(dbName, collName)
+}
+
+// setConsistencyHeaders sets the consistency level and session token headers of a read/query request.
+// The default consistency level of the client is used if consistencyLevel is empty.
+func (c *RestClient) setConsistencyHeaders(req *http.Request, consistencyLevel, sessionToken string) {
+	if consistencyLevel == "" {
+		consistencyLevel = c.consistencyLevel
+	}
+	if consistencyLevel != "" {
+		req.Header.Set(restApiHeaderConsistencyLevel, consistencyLevel)
+	}
+	if sessionToken != "" {
+		req.Header.Set(restApiHeaderSessionToken, sessionToken)
+	}
 }
 
 func (c *RestClient) buildJsonRequest(method, url string, params interface{}) (*http.Request, error) {
@@ -134,27 +204,17 @@ func (c *RestClient) buildJsonRequest(method, url string, params interface{}) (*
 	return req, nil
 }
 
-func (c *RestClient) addAuthHeader(req *http.Request, method, resType, resId string) *http.Request {
-	now := time.Now().In(locGmt)
-	/*
-	 * M.A.I. 2022-02-16
-	 * The original statement had a single ToLower. In the resulting string the resId gets lowered when from MS Docs it should be left unaltered
-	 * I came across an error on a collection with a mixed case name...
-	 * stringToSign := strings.ToLower(fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", method, resType, resId, now.Format(time.RFC1123), ""))
-	 */
-	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(method), strings.ToLower(resType), resId, strings.ToLower(now.Format(time.RFC1123)), "")
-	h := hmac.New(sha256.New, c.authKey)
-	h.Write([]byte(stringToSign))
-	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
-	authHeader := "type=master&ver=1.0&sig=" + signature
-	authHeader = url.QueryEscape(authHeader)
-	req.Header.Set(httpHeaderAuthorization, authHeader)
-	req.Header.Set(restApiHeaderDate, now.Format(time.RFC1123))
-	return req
+// addAuthHeader records the resource accessed by the request: the authorization headers are set by the credential of
+// the client each time the request is sent (see Config.Credential).
+func (c *RestClient) addAuthHeader(req *http.Request, _, resType, resId string) *http.Request {
+	return _withResource(req, resType, resId)
 }
 
 func (c *RestClient) buildRestResponse(resp *gjrc.GjrcResponse) RestResponse {
 	result := RestResponse{CallErr: resp.Error()}
+	if httpResp := resp.HttpResponse(); c.router != nil && httpResp != nil && httpResp.Request != nil {
+		result.Region = c.router.regionOf(httpResp.Request.URL.Host)
+	}
 	if result.CallErr != nil {
 		httpResp := resp.HttpResponse()
 		if httpResp != nil {
@@ -218,8 +278,59 @@ func (c *RestClient) SetAutoId(value bool) *RestClient {
 	return c
 }
 
+// GetSessionContainer returns the SessionContainer used to track session tokens, nil if session tracking is disabled.
+//
+// @Available since v1.2.0
+func (c *RestClient) GetSessionContainer() *SessionContainer {
+	return c.sessions
+}
+
+// SetSessionContainer sets the SessionContainer used to track session tokens. If not nil, session tokens returned by
+// document operations are tracked and attached to subsequent read/query requests that do not specify a session token.
+// Passing nil disables session tracking.
+//
+// @Available since v1.2.0
+func (c *RestClient) SetSessionContainer(sessions *SessionContainer) *RestClient {
+	c.sessions = sessions
+	return c
+}
+
 /*----------------------------------------------------------------------*/
 
+// GetDatabaseAccount invokes Cosmos DB API to get the properties of the database account: regions, consistency
+// policy, replication policies and query limits. The request is always sent to the account endpoint.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/get-database-account.
+//
+// Available since v1.2.0
+func (c *RestClient) GetDatabaseAccount() *RespGetDatabaseAccount {
+	method, urlEndpoint := "GET", c.endpoint+"/"
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetDatabaseAccount{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "", "")
+
+	// the account resource is global, the request is not routed to regional endpoints
+	resp := c.doRequestWithRetries(req)
+	result := &RespGetDatabaseAccount{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.DatabaseAccount))
+	}
+	if result.CallErr == nil {
+		// the query engine configuration is a JSON-encoded string
+		var raw struct {
+			QueryEngineConfiguration string `json:"queryEngineConfiguration"`
+		}
+		if json.Unmarshal(result.RespBody, &raw) == nil && raw.QueryEngineConfiguration != "" {
+			result.CallErr = json.Unmarshal([]byte(raw.QueryEngineConfiguration), &(result.QueryEngineConfiguration))
+		}
+		result.MaxMediaStorageUsageMb, _ = strconv.ParseInt(result.RespHeader[respHeaderMaxMediaStorageUsageMb], 10, 64)
+		result.MediaStorageUsageMb, _ = strconv.ParseInt(result.RespHeader[respHeaderMediaStorageUsageMb], 10, 64)
+	}
+	return result
+}
+
 // DatabaseSpec specifies a Cosmos DB database specifications for creation.
 type DatabaseSpec struct {
 	Id        string
@@ -245,7 +356,7 @@ func (c *RestClient) CreateDatabase(spec DatabaseSpec) *RespCreateDb {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespCreateDb{RestResponse: c.buildRestResponse(resp), DbInfo: DbInfo{Id: spec.Id}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DbInfo))
@@ -264,7 +375,7 @@ func (c *RestClient) GetDatabase(dbName string) *RespGetDb {
 	}
 	req = c.addAuthHeader(req, method, "dbs", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespGetDb{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DbInfo))
@@ -283,7 +394,7 @@ func (c *RestClient) DeleteDatabase(dbName string) *RespDeleteDb {
 	}
 	req = c.addAuthHeader(req, method, "dbs", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespDeleteDb{RestResponse: c.buildRestResponse(resp)}
 	return result
 }
@@ -299,7 +410,7 @@ func (c *RestClient) ListDatabases() *RespListDb {
 	}
 	req = c.addAuthHeader(req, method, "dbs", "")
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespListDb{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -323,6 +434,42 @@ type CollectionSpec struct {
 	PartitionKeyInfo map[string]interface{}
 	IndexingPolicy   map[string]interface{}
 	UniqueKeyPolicy  map[string]interface{}
+	// DefaultTtl (available since v1.2.0) specifies the collection's default time-to-live, in seconds, of documents:
+	// 0 means time-to-live is off, -1 means it is on but documents do not expire unless they have their own "ttl" field.
+	// Note: ReplaceCollection replaces the whole collection's settings, DefaultTtl must be supplied to keep time-to-live on.
+	DefaultTtl int
+	// ConflictResolutionPolicy (available since v1.2.0) specifies how conflicts are resolved when the account has
+	// multiple write regions, see LwwConflictResolutionPolicy and CustomConflictResolutionPolicy.
+	// Note: the conflict resolution policy can only be set when the collection is created.
+	ConflictResolutionPolicy map[string]interface{}
+	// Properties (available since v1.2.0) holds other properties of the collection (e.g. geospatialConfig), sent as-is
+	// by ReplaceCollection so that they are kept: supply the properties returned by GetCollection (RespBody).
+	// Properties specified by the other fields take precedence, system properties (starting with "_") are ignored.
+	Properties map[string]interface{}
+}
+
+// LwwConflictResolutionPolicy builds a "Last Writer Wins" conflict resolution policy: the conflicting write having the
+// highest value at path (numeric) wins. If path is empty, the system property "/_ts" is used.
+//
+// Available since v1.2.0
+func LwwConflictResolutionPolicy(path string) map[string]interface{} {
+	if path == "" {
+		path = "/_ts"
+	}
+	return map[string]interface{}{"mode": "LastWriterWins", "conflictResolutionPath": path}
+}
+
+// CustomConflictResolutionPolicy builds a custom conflict resolution policy: conflicts are resolved by the stored
+// procedure sprocName of collection dbName.collName. If sprocName is empty, conflicts are not resolved automatically
+// but written to the collection's conflicts feed (see RestClient.ListConflicts).
+//
+// Available since v1.2.0
+func CustomConflictResolutionPolicy(dbName, collName, sprocName string) map[string]interface{} {
+	policy := map[string]interface{}{"mode": "Custom"}
+	if sprocName != "" {
+		policy["conflictResolutionProcedure"] = "dbs/" + dbName + "/colls/" + collName + "/sprocs/" + sprocName
+	}
+	return policy
 }
 
 // CreateCollection invokes Cosmos DB API to create a new collection.
@@ -339,6 +486,12 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 	if spec.UniqueKeyPolicy != nil {
 		params[restApiParamUniqueKeyPolicy] = spec.UniqueKeyPolicy
 	}
+	if spec.ConflictResolutionPolicy != nil {
+		params[restApiParamConflictResolutionPolicy] = spec.ConflictResolutionPolicy
+	}
+	if spec.DefaultTtl != 0 {
+		params[restApiParamDefaultTtl] = spec.DefaultTtl
+	}
 	req, err := c.buildJsonRequest(method, urlEndpoint, params)
 	if err != nil {
 		return &RespCreateColl{RestResponse: RestResponse{CallErr: err}, CollInfo: CollInfo{Id: spec.CollName}}
@@ -351,7 +504,7 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespCreateColl{RestResponse: c.buildRestResponse(resp), CollInfo: CollInfo{Id: spec.CollName}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
@@ -366,17 +519,32 @@ func (c *RestClient) CreateCollection(spec CollectionSpec) *RespCreateColl {
 // Note: ru and maxru must not be supplied together!
 func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 	method, urlEndpoint := "PUT", c.endpoint+"/dbs/"+spec.DbName+"/colls/"+spec.CollName
-	params := map[string]interface{}{"id": spec.CollName}
+	params := make(map[string]interface{})
+	for k, v := range spec.Properties {
+		if !strings.HasPrefix(k, "_") {
+			params[k] = v
+		}
+	}
+	params["id"] = spec.CollName
 	if spec.PartitionKeyInfo != nil {
 		params[restApiParamPartitionKey] = spec.PartitionKeyInfo
 	}
 	if spec.IndexingPolicy != nil {
 		params[restApiParamIndexingPolicy] = spec.IndexingPolicy
 	}
-	// The unique index cannot be modified. To change the unique index, remove the collection and re-create a new one.
-	// if spec.UniqueKeyPolicy != nil {
-	// 	params[restApiParamUniqueKeyPolicy] = spec.UniqueKeyPolicy
-	// }
+	// The unique index and the conflict resolution policy cannot be modified, but must be supplied unchanged: a replace
+	// without them would be rejected, or would reset them.
+	if spec.UniqueKeyPolicy != nil {
+		params[restApiParamUniqueKeyPolicy] = spec.UniqueKeyPolicy
+	}
+	if spec.ConflictResolutionPolicy != nil {
+		params[restApiParamConflictResolutionPolicy] = spec.ConflictResolutionPolicy
+	}
+	if spec.DefaultTtl != 0 {
+		params[restApiParamDefaultTtl] = spec.DefaultTtl
+	} else {
+		delete(params, restApiParamDefaultTtl)
+	}
 	req, err := c.buildJsonRequest(method, urlEndpoint, params)
 	if err != nil {
 		return &RespReplaceColl{RestResponse: RestResponse{CallErr: err}, CollInfo: CollInfo{Id: spec.CollName}}
@@ -389,7 +557,7 @@ func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 		req.Header.Set(restApiHeaderOfferAutopilotSettings, fmt.Sprintf(`{"maxThroughput":%d}`, spec.MaxRu))
 	}
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespReplaceColl{RestResponse: c.buildRestResponse(resp), CollInfo: CollInfo{Id: spec.CollName}}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
@@ -401,17 +569,36 @@ func (c *RestClient) ReplaceCollection(spec CollectionSpec) *RespReplaceColl {
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/get-a-collection
 func (c *RestClient) GetCollection(dbName, collName string) *RespGetColl {
+	return c.getCollection(dbName, collName, false)
+}
+
+// GetIndexingProgress invokes Cosmos DB API to get an existing collection, along with the progress of the
+// transformation of its indexes (see RespGetColl.IndexTransformationProgress). The indexes are transformed in the
+// background after the indexing policy of a collection is changed.
+//
+// Available since v1.2.0
+func (c *RestClient) GetIndexingProgress(dbName, collName string) *RespGetColl {
+	return c.getCollection(dbName, collName, true)
+}
+
+func (c *RestClient) getCollection(dbName, collName string, populateQuotaInfo bool) *RespGetColl {
 	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+dbName+"/colls/"+collName
 	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
 	if err != nil {
 		return &RespGetColl{RestResponse: RestResponse{CallErr: err}}
 	}
 	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName+"/colls/"+collName)
+	if populateQuotaInfo {
+		req.Header.Set(restApiHeaderPopulateQuotaInfo, "true")
+	}
 
-	resp := c.client.Do(req)
-	result := &RespGetColl{RestResponse: c.buildRestResponse(resp)}
+	resp := c.doRequest(req)
+	result := &RespGetColl{RestResponse: c.buildRestResponse(resp), IndexTransformationProgress: -1}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.CollInfo))
+		if v, err := strconv.Atoi(result.RespHeader[respHeaderIndexTransformationProgress]); err == nil {
+			result.IndexTransformationProgress = v
+		}
 	}
 	return result
 }
@@ -427,8 +614,11 @@ func (c *RestClient) DeleteCollection(dbName, collName string) *RespDeleteColl {
 	}
 	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName+"/colls/"+collName)
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespDeleteColl{RestResponse: c.buildRestResponse(resp)}
+	if result.Error() == nil {
+		c.sessions.Remove(dbName, collName)
+	}
 	return result
 }
 
@@ -443,7 +633,7 @@ func (c *RestClient) ListCollections(dbName string) *RespListColl {
 	}
 	req = c.addAuthHeader(req, method, "colls", "dbs/"+dbName)
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespListColl{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -470,7 +660,7 @@ func (c *RestClient) GetPkranges(dbName, collName string) *RespGetPkranges {
 	}
 	req = c.addAuthHeader(req, method, "pkranges", "dbs/"+dbName+"/colls/"+collName)
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespGetPkranges{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -478,6 +668,113 @@ func (c *RestClient) GetPkranges(dbName, collName string) *RespGetPkranges {
 	return result
 }
 
+// ListConflictsReq specifies a request to list entries of a collection's conflicts feed.
+//
+// Available since v1.2.0
+type ListConflictsReq struct {
+	DbName, CollName  string
+	MaxItemCount      int    // if positive, only one page of at most MaxItemCount conflicts is fetched; otherwise all conflicts are fetched
+	ContinuationToken string // continuation token returned by the previous call, used to fetch the next page
+}
+
+// ListConflicts invokes Cosmos DB API to read the conflicts feed of a collection. The conflicts feed contains the
+// conflicting writes that were not resolved automatically by the collection's conflict resolution policy.
+//
+// See: https://learn.microsoft.com/en-us/azure/cosmos-db/nosql/how-to-manage-conflicts.
+//
+// Available since v1.2.0
+func (c *RestClient) ListConflicts(r ListConflictsReq) *RespListConflicts {
+	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/conflicts"
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListConflicts{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "conflicts", "dbs/"+r.DbName+"/colls/"+r.CollName)
+	req.Header.Set(restApiHeaderEnableCrossPartitionQuery, "true")
+	if r.MaxItemCount > 0 {
+		req.Header.Set(restApiHeaderPageSize, strconv.Itoa(r.MaxItemCount))
+	} else {
+		req.Header.Set(restApiHeaderPageSize, "100")
+	}
+	if r.ContinuationToken != "" {
+		req.Header.Set(restApiHeaderContinuation, r.ContinuationToken)
+	}
+
+	var result *RespListConflicts
+	for {
+		resp := c.doRequest(req)
+		tempResult := &RespListConflicts{RestResponse: c.buildRestResponse(resp)}
+		if tempResult.CallErr == nil {
+			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
+			tempResult.CallErr = json.Unmarshal(tempResult.RespBody, &tempResult)
+		}
+		if result == nil {
+			result = tempResult
+		} else {
+			result.ContinuationToken = tempResult.ContinuationToken
+			result.RequestCharge += tempResult.RequestCharge
+			result.Count += tempResult.Count
+			result.Conflicts = append(result.Conflicts, tempResult.Conflicts...)
+		}
+		if result.Error() != nil || result.ContinuationToken == "" || r.MaxItemCount > 0 {
+			break
+		}
+		req.Header.Set(restApiHeaderContinuation, result.ContinuationToken)
+	}
+	return result
+}
+
+// ConflictReq specifies a request to read or delete an entry of a collection's conflicts feed.
+//
+// Available since v1.2.0
+type ConflictReq struct {
+	DbName, CollName, ConflictId string
+	PartitionKeyValues           []interface{} // partition key values of the conflicting document
+}
+
+// GetConflict invokes Cosmos DB API to read an entry of a collection's conflicts feed.
+//
+// Available since v1.2.0
+func (c *RestClient) GetConflict(r ConflictReq) *RespGetConflict {
+	method, urlEndpoint := "GET", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/conflicts/"+r.ConflictId
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetConflict{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "conflicts", "dbs/"+r.DbName+"/colls/"+r.CollName+"/conflicts/"+r.ConflictId)
+	if len(r.PartitionKeyValues) > 0 {
+		jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+		req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	}
+
+	resp := c.doRequest(req)
+	result := &RespGetConflict{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.ConflictInfo))
+	}
+	return result
+}
+
+// DeleteConflict invokes Cosmos DB API to delete an entry of a collection's conflicts feed, usually after the
+// conflict has been resolved manually.
+//
+// Available since v1.2.0
+func (c *RestClient) DeleteConflict(r ConflictReq) *RespDeleteConflict {
+	method, urlEndpoint := "DELETE", c.endpoint+"/dbs/"+r.DbName+"/colls/"+r.CollName+"/conflicts/"+r.ConflictId
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteConflict{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "conflicts", "dbs/"+r.DbName+"/colls/"+r.CollName+"/conflicts/"+r.ConflictId)
+	if len(r.PartitionKeyValues) > 0 {
+		jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+		req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	}
+
+	resp := c.doRequest(req)
+	return &RespDeleteConflict{RestResponse: c.buildRestResponse(resp)}
+}
+
 // DocumentSpec specifies a Cosmos DB document specifications for creation.
 type DocumentSpec struct {
 	DbName, CollName   string
@@ -485,6 +782,8 @@ type DocumentSpec struct {
 	IndexingDirective  string // accepted value "", "Include" or "Exclude"
 	PartitionKeyValues []interface{}
 	DocumentData       DocInfo
+	MatchEtag          string // (since v1.2.0) if not empty, add "If-Match" header to request (upsert only: the existing document is replaced only if its ETag matches)
+	Ttl                int    // (since v1.2.0) if not zero, the document's time-to-live in seconds (-1: the document never expires), written as field "ttl" of the document
 }
 
 // CreateDocument invokes Cosmos DB API to create a new document.
@@ -497,6 +796,9 @@ func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
 			spec.DocumentData[docFieldId] = strings.ToLower(idGen.Id128Hex())
 		}
 	}
+	if spec.Ttl != 0 {
+		spec.DocumentData[docFieldTtl] = spec.Ttl
+	}
 	req, err := c.buildJsonRequest(method, urlEndpoint, spec.DocumentData)
 	if err != nil {
 		return &RespCreateDoc{RestResponse: RestResponse{CallErr: err}}
@@ -508,11 +810,15 @@ func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
 	if spec.IndexingDirective != "" {
 		req.Header.Set(restApiHeaderIndexingDirective, spec.IndexingDirective)
 	}
+	if spec.MatchEtag != "" {
+		req.Header.Set(httpHeaderIfMatch, spec.MatchEtag)
+	}
 	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespCreateDoc{RestResponse: c.buildRestResponse(resp)}
+	c.sessions.Update(spec.DbName, spec.CollName, result.SessionToken)
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -525,6 +831,9 @@ func (c *RestClient) CreateDocument(spec DocumentSpec) *RespCreateDoc {
 func (c *RestClient) ReplaceDocument(matchEtag string, spec DocumentSpec) *RespReplaceDoc {
 	id, _ := spec.DocumentData[docFieldId].(string)
 	method, urlEndpoint := "PUT", c.endpoint+"/dbs/"+spec.DbName+"/colls/"+spec.CollName+"/docs/"+id
+	if spec.Ttl != 0 {
+		spec.DocumentData[docFieldTtl] = spec.Ttl
+	}
 	req, err := c.buildJsonRequest(method, urlEndpoint, spec.DocumentData)
 	if err != nil {
 		return &RespReplaceDoc{RestResponse: RestResponse{CallErr: err}}
@@ -536,8 +845,9 @@ func (c *RestClient) ReplaceDocument(matchEtag string, spec DocumentSpec) *RespR
 	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
 	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespReplaceDoc{RestResponse: c.buildRestResponse(resp)}
+	c.sessions.Update(spec.DbName, spec.CollName, result.SessionToken)
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -569,15 +879,11 @@ func (c *RestClient) GetDocument(r DocReq) *RespGetDoc {
 	if r.NotMatchEtag != "" {
 		req.Header.Set(httpHeaderIfNoneMatch, r.NotMatchEtag)
 	}
-	if r.ConsistencyLevel != "" {
-		req.Header.Set(restApiHeaderConsistencyLevel, r.ConsistencyLevel)
-	}
-	if r.SessionToken != "" {
-		req.Header.Set(restApiHeaderSessionToken, r.SessionToken)
-	}
+	c.setConsistencyHeaders(req, r.ConsistencyLevel, c.sessionToken(r.DbName, r.CollName, r.SessionToken))
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespGetDoc{RestResponse: c.buildRestResponse(resp)}
+	c.sessions.Update(r.DbName, r.CollName, result.SessionToken)
 	if result.CallErr == nil && result.StatusCode != 304 {
 		result.CallErr = json.Unmarshal(result.RespBody, &(result.DocInfo))
 	}
@@ -600,8 +906,215 @@ func (c *RestClient) DeleteDocument(r DocReq) *RespDeleteDoc {
 		req.Header.Set(httpHeaderIfMatch, r.MatchEtag)
 	}
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespDeleteDoc{RestResponse: c.buildRestResponse(resp)}
+	c.sessions.Update(r.DbName, r.CollName, result.SessionToken)
+	return result
+}
+
+// AttachmentSpec specifies a Cosmos DB attachment specifications for creation or replacement.
+//
+// An attachment either references external media (MediaLink) or stores its media in Cosmos DB (Media). Inline media
+// can only be supplied on creation: to replace inline media, delete the attachment and create it again.
+//
+// Available since v1.2.0
+type AttachmentSpec struct {
+	DbName, CollName, DocId string
+	PartitionKeyValues      []interface{} // partition key values of the document
+	Id                      string        // id of the attachment
+	ContentType             string        // MIME content type of the media, e.g. "image/png"
+	MediaLink               string        // URL of the external media, ignored if Media is supplied
+	Media                   []byte        // inline media, stored in Cosmos DB
+}
+
+// AttachmentReq specifies a request to read, list or delete attachments of a document.
+//
+// Available since v1.2.0
+type AttachmentReq struct {
+	DbName, CollName, DocId string
+	AttachmentId            string        // id of the attachment, not used when listing attachments
+	PartitionKeyValues      []interface{} // partition key values of the document
+}
+
+func (c *RestClient) buildAttachmentRequest(method, urlEndpoint string, spec AttachmentSpec) (*http.Request, error) {
+	if spec.Media == nil {
+		return c.buildJsonRequest(method, urlEndpoint, map[string]interface{}{
+			"id": spec.Id, "contentType": spec.ContentType, "media": spec.MediaLink,
+		})
+	}
+	req, err := http.NewRequest(method, urlEndpoint, bytes.NewReader(spec.Media))
+	if err != nil {
+		return nil, err
+	}
+	contentType := spec.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set(httpHeaderContentType, contentType)
+	req.Header.Set(httpHeaderAccept, "application/json")
+	req.Header.Set(httpHeaderSlug, spec.Id)
+	req.Header.Set(restApiHeaderVersion, c.apiVersion)
+	return req, nil
+}
+
+// CreateAttachment invokes Cosmos DB API to create a new attachment of a document.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/create-an-attachment.
+//
+// Available since v1.2.0
+func (c *RestClient) CreateAttachment(spec AttachmentSpec) *RespCreateAttachment {
+	docLink := "dbs/" + spec.DbName + "/colls/" + spec.CollName + "/docs/" + spec.DocId
+	method, urlEndpoint := "POST", c.endpoint+"/"+docLink+"/attachments"
+	req, err := c.buildAttachmentRequest(method, urlEndpoint, spec)
+	if err != nil {
+		return &RespCreateAttachment{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "attachments", docLink)
+	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+
+	resp := c.doRequest(req)
+	result := &RespCreateAttachment{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.AttachmentInfo))
+	}
+	return result
+}
+
+// ReplaceAttachment invokes Cosmos DB API to replace an existing attachment of a document. Only attachments
+// referencing external media can be replaced (AttachmentSpec.Media must be nil).
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/replace-an-attachment.
+//
+// Available since v1.2.0
+func (c *RestClient) ReplaceAttachment(matchEtag string, spec AttachmentSpec) *RespReplaceAttachment {
+	if spec.Media != nil {
+		return &RespReplaceAttachment{RestResponse: RestResponse{CallErr: errors.New("inline media cannot be replaced, delete the attachment and create it again")}}
+	}
+	attLink := "dbs/" + spec.DbName + "/colls/" + spec.CollName + "/docs/" + spec.DocId + "/attachments/" + spec.Id
+	method, urlEndpoint := "PUT", c.endpoint+"/"+attLink
+	req, err := c.buildAttachmentRequest(method, urlEndpoint, spec)
+	if err != nil {
+		return &RespReplaceAttachment{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "attachments", attLink)
+	jsPkValues, _ := json.Marshal(spec.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+	if matchEtag != "" {
+		req.Header.Set(httpHeaderIfMatch, matchEtag)
+	}
+
+	resp := c.doRequest(req)
+	result := &RespReplaceAttachment{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.AttachmentInfo))
+	}
+	return result
+}
+
+// GetAttachment invokes Cosmos DB API to get an existing attachment of a document. The media of the attachment is
+// not fetched, see GetAttachmentMedia.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/get-an-attachment.
+//
+// Available since v1.2.0
+func (c *RestClient) GetAttachment(r AttachmentReq) *RespGetAttachment {
+	attLink := "dbs/" + r.DbName + "/colls/" + r.CollName + "/docs/" + r.DocId + "/attachments/" + r.AttachmentId
+	method, urlEndpoint := "GET", c.endpoint+"/"+attLink
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetAttachment{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "attachments", attLink)
+	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+
+	resp := c.doRequest(req)
+	result := &RespGetAttachment{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &(result.AttachmentInfo))
+	}
+	return result
+}
+
+// GetAttachmentMedia fetches the media of an attachment stored in Cosmos DB (inline media). An error is returned
+// if the attachment references external media: the media must be fetched from AttachmentInfo.Media instead.
+//
+// Available since v1.2.0
+func (c *RestClient) GetAttachmentMedia(r AttachmentReq) *RespGetAttachmentMedia {
+	getResult := c.GetAttachment(r)
+	if getResult.Error() != nil {
+		return &RespGetAttachmentMedia{RestResponse: getResult.RestResponse}
+	}
+	if !getResult.IsInlineMedia() {
+		return &RespGetAttachmentMedia{RestResponse: RestResponse{CallErr: fmt.Errorf("attachment %s references external media %s", r.AttachmentId, getResult.Media)}}
+	}
+	mediaId := strings.TrimPrefix(getResult.Media, "/media/")
+	method, urlEndpoint := "GET", c.endpoint+getResult.Media
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetAttachmentMedia{RestResponse: RestResponse{CallErr: err}}
+	}
+	req.Header.Set(httpHeaderAccept, "*/*")
+	// media is addressed by its rid, which must be lower-cased when signing the request (same as offers)
+	req = c.addAuthHeader(req, method, "media", strings.ToLower(mediaId))
+
+	resp := c.doRequest(req)
+	result := &RespGetAttachmentMedia{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.ContentType = result.RespHeader[strings.ToUpper(httpHeaderContentType)]
+		result.Media = result.RespBody
+	}
+	return result
+}
+
+// DeleteAttachment invokes Cosmos DB API to delete an existing attachment of a document, along with its inline media.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/delete-an-attachment.
+//
+// Available since v1.2.0
+func (c *RestClient) DeleteAttachment(r AttachmentReq) *RespDeleteAttachment {
+	attLink := "dbs/" + r.DbName + "/colls/" + r.CollName + "/docs/" + r.DocId + "/attachments/" + r.AttachmentId
+	method, urlEndpoint := "DELETE", c.endpoint+"/"+attLink
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespDeleteAttachment{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "attachments", attLink)
+	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+
+	resp := c.doRequest(req)
+	return &RespDeleteAttachment{RestResponse: c.buildRestResponse(resp)}
+}
+
+// ListAttachments invokes Cosmos DB API to list all attachments of a document.
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/list-attachments.
+//
+// Available since v1.2.0
+func (c *RestClient) ListAttachments(r AttachmentReq) *RespListAttachments {
+	docLink := "dbs/" + r.DbName + "/colls/" + r.CollName + "/docs/" + r.DocId
+	method, urlEndpoint := "GET", c.endpoint+"/"+docLink+"/attachments"
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespListAttachments{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "attachments", docLink)
+	jsPkValues, _ := json.Marshal(r.PartitionKeyValues)
+	req.Header.Set(restApiHeaderPartitionKey, string(jsPkValues))
+
+	resp := c.doRequest(req)
+	result := &RespListAttachments{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		if result.CallErr == nil {
+			sort.Slice(result.Attachments, func(i, j int) bool {
+				// sort attachments by id
+				return result.Attachments[i].Id < result.Attachments[j].Id
+			})
+		}
+	}
 	return result
 }
 
@@ -641,12 +1154,7 @@ func (c *RestClient) buildQueryRequest(query QueryReq) (*http.Request, error) {
 	if query.ContinuationToken != "" {
 		req.Header.Set(restApiHeaderContinuation, query.ContinuationToken)
 	}
-	if query.ConsistencyLevel != "" {
-		req.Header.Set(restApiHeaderConsistencyLevel, query.ConsistencyLevel)
-	}
-	if query.SessionToken != "" {
-		req.Header.Set(restApiHeaderSessionToken, query.SessionToken)
-	}
+	c.setConsistencyHeaders(req, query.ConsistencyLevel, c.sessionToken(query.DbName, query.CollName, query.SessionToken))
 	if query.PkRangeId != "" {
 		req.Header.Set(restApiHeaderPartitionKeyRangeId, query.PkRangeId)
 	} else if query.PkValue != "" {
@@ -663,6 +1171,7 @@ func (c *RestClient) mergeQueryResults(existingResp, newResp *RespQueryDocs, que
 	result := &temp
 	if existingResp != nil {
 		result.RequestCharge += existingResp.RequestCharge
+		result.QueryMetrics = _mergeQueryMetrics(existingResp.QueryMetrics, newResp.QueryMetrics)
 		if newResp.Error() == nil {
 			result = result.merge(queryPlan, existingResp)
 		}
@@ -819,16 +1328,18 @@ func (c *RestClient) queryDocumentsSimple(query QueryReq, queryPlan *RespQueryPl
 		req.Header.Set(restApiHeaderPageSize, "100")
 	}
 	for {
-		resp := c.client.Do(req)
+		resp := c.doRequest(req)
 		tempResult := &RespQueryDocs{RestResponse: c.buildRestResponse(resp)}
 		if tempResult.CallErr == nil {
 			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
 			tempResult.CallErr = json.Unmarshal(tempResult.RespBody, &tempResult)
+			tempResult.populateQueryMetrics(query.PkRangeId)
 		}
 		if result != nil {
 			// append returned document list
 			tempResult.Count += result.Count
 			tempResult.RequestCharge += result.RequestCharge
+			tempResult.QueryMetrics = _mergeQueryMetrics(result.QueryMetrics, tempResult.QueryMetrics)
 			tempResult.Documents = append(result.Documents, tempResult.Documents...)
 		}
 		result = tempResult
@@ -848,11 +1359,12 @@ func (c *RestClient) queryDocumentsCall(query QueryReq) *RespQueryDocs {
 	if err != nil {
 		return &RespQueryDocs{RestResponse: RestResponse{CallErr: err}}
 	}
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespQueryDocs{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.ContinuationToken = result.RespHeader[respHeaderContinuation]
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
+		result.populateQueryMetrics(query.PkRangeId)
 	}
 	return result
 }
@@ -953,17 +1465,12 @@ func (c *RestClient) QueryPlan(query QueryReq) *RespQueryPlan {
 	if query.ContinuationToken != "" {
 		req.Header.Set(restApiHeaderContinuation, query.ContinuationToken)
 	}
-	if query.ConsistencyLevel != "" {
-		req.Header.Set(restApiHeaderConsistencyLevel, query.ConsistencyLevel)
-	}
-	if query.SessionToken != "" {
-		req.Header.Set(restApiHeaderSessionToken, query.SessionToken)
-	}
+	c.setConsistencyHeaders(req, query.ConsistencyLevel, c.sessionToken(query.DbName, query.CollName, query.SessionToken))
 	req.Header.Set(restApiHeaderIsQueryPlanRequest, "True") // Caution: as of Dec-2022 "true" (lower-cased "t") does not work
 	req.Header.Set(restApiHeaderSupportedQueryFeatures, "NonValueAggregate, Aggregate, Distinct, MultipleOrderBy, OffsetAndLimit, OrderBy, Top, CompositeAggregate, GroupBy, MultipleAggregates")
 	req.Header.Set(restApiHeaderEnableCrossPartitionQuery, "true")
 	req.Header.Set(restApiHeaderParallelizeCrossPartitionQuery, "true")
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespQueryPlan{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.CallErr = json.Unmarshal(result.RespBody, &result)
@@ -986,7 +1493,7 @@ type ListDocsReq struct {
 func (c *RestClient) getChangeFeed(r ListDocsReq, req *http.Request) *RespListDocs {
 	var result *RespListDocs
 	for {
-		resp := c.client.Do(req)
+		resp := c.doRequest(req)
 		tempResult := &RespListDocs{RestResponse: c.buildRestResponse(resp)}
 		if 300 <= tempResult.StatusCode && tempResult.StatusCode < 400 {
 			// not an error, the status code 3xx indicates that there is currently no item from the change feed
@@ -1041,12 +1548,7 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 	if r.ContinuationToken != "" {
 		req.Header.Set(restApiHeaderContinuation, r.ContinuationToken)
 	}
-	if r.ConsistencyLevel != "" {
-		req.Header.Set(restApiHeaderConsistencyLevel, r.ConsistencyLevel)
-	}
-	if r.SessionToken != "" {
-		req.Header.Set(restApiHeaderSessionToken, r.SessionToken)
-	}
+	c.setConsistencyHeaders(req, r.ConsistencyLevel, c.sessionToken(r.DbName, r.CollName, r.SessionToken))
 	if r.NotMatchEtag != "" {
 		req.Header.Set(httpHeaderIfNoneMatch, r.NotMatchEtag)
 	}
@@ -1061,7 +1563,7 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 	// fetch documents from table/collection
 	var result *RespListDocs
 	for {
-		resp := c.client.Do(req)
+		resp := c.doRequest(req)
 		tempResult := &RespListDocs{RestResponse: c.buildRestResponse(resp)}
 		if tempResult.CallErr == nil {
 			tempResult.ContinuationToken = tempResult.RespHeader[respHeaderContinuation]
@@ -1086,6 +1588,211 @@ func (c *RestClient) ListDocuments(r ListDocsReq) *RespListDocs {
 	return result
 }
 
+// ItemIdentity identifies a document by its id and the values of its partition key.
+//
+// Available since v1.2.0
+type ItemIdentity struct {
+	Id                 string
+	PartitionKeyValues []interface{}
+}
+
+// ReadManyReq specifies a request to read a list of documents identified by (id, partition key) pairs.
+//
+// Available since v1.2.0
+type ReadManyReq struct {
+	DbName, CollName string
+	Items            []ItemIdentity
+	MaxConcurrency   int    // max number of parallel requests, default value is 8
+	ConsistencyLevel string // accepted values: "", "Strong", "Bounded", "Session" or "Eventual"
+	SessionToken     string // string token used with session level consistency
+}
+
+// readManyGroup is a group of items of a ReadMany request that belong to the same partition key range.
+type readManyGroup struct {
+	pkrangeId string // empty if the partition key range of the item is not known, the group has a single item then
+	items     []ItemIdentity
+	docs      []DocInfo
+	resp      RestResponse
+}
+
+// _readManyKey identifies an item of a ReadMany request by its id and partition key values.
+func _readManyKey(id string, pkValues []interface{}) string {
+	jsPkValues, _ := json.Marshal(pkValues)
+	return string(jsPkValues) + "/" + id
+}
+
+// ReadMany reads a list of documents identified by (id, partition key) pairs.
+//
+// Items are grouped by partition key range, which is resolved from the effective partition key (the hash of the
+// partition key values) of each item: a group of a single item is fetched with a point read, the other groups are
+// fetched with one query per group, each query being served by a single partition. Groups are fetched in parallel.
+// Items that do not exist are reported in RespReadMany.NotFound, and RespReadMany.RequestCharge is the total request
+// charge of all requests. If a request fails, its response is returned.
+//
+// Available since v1.2.0
+func (c *RestClient) ReadMany(r ReadManyReq) *RespReadMany {
+	items := make([]ItemIdentity, 0, len(r.Items))
+	seen := make(map[string]bool)
+	for _, item := range r.Items {
+		if key := _readManyKey(item.Id, item.PartitionKeyValues); !seen[key] {
+			seen[key] = true
+			items = append(items, item)
+		}
+	}
+
+	result := &RespReadMany{RestResponse: RestResponse{StatusCode: 200}, Documents: make([]DocInfo, 0), NotFound: make([]ItemIdentity, 0)}
+	var pkInfo PkInfo
+	var pkranges []PkrangeInfo
+	if len(items) > 1 {
+		collResult := c.GetCollection(r.DbName, r.CollName)
+		if err := collResult.Error(); err != nil {
+			return &RespReadMany{RestResponse: collResult.RestResponse}
+		}
+		pkrangesResult := c.GetPkranges(r.DbName, r.CollName)
+		if err := pkrangesResult.Error(); err != nil {
+			return &RespReadMany{RestResponse: pkrangesResult.RestResponse}
+		}
+		pkInfo, pkranges = collResult.PartitionKey, pkrangesResult.Pkranges
+		for _, requestCharge := range []float64{collResult.RequestCharge, pkrangesResult.RequestCharge} {
+			if requestCharge > 0 {
+				result.RequestCharge += requestCharge
+			}
+		}
+	}
+	groups := make([]*readManyGroup, 0)
+	groupIndex := make(map[string]*readManyGroup)
+	for _, item := range items {
+		pkrangeId := ""
+		if epk, err := _effectivePartitionKey(pkInfo, item.PartitionKeyValues); err == nil {
+			pkrangeId = _pkrangeOf(pkranges, epk)
+		}
+		group, ok := groupIndex[pkrangeId]
+		if !ok || pkrangeId == "" {
+			group = &readManyGroup{pkrangeId: pkrangeId}
+			groupIndex[pkrangeId] = group
+			groups = append(groups, group)
+		}
+		group.items = append(group.items, item)
+	}
+
+	concurrency := r.MaxConcurrency
+	if concurrency <= 0 {
+		concurrency = bulkConcurrency
+	}
+	_parallelDo(context.Background(), len(groups), concurrency, true, func(i int) error {
+		c.readManyGroup(r, pkInfo.Paths(), groups[i])
+		return groups[i].resp.Error()
+	})
+
+	found := make(map[string]DocInfo)
+	for _, group := range groups {
+		if group.resp.RequestCharge > 0 {
+			result.RequestCharge += group.resp.RequestCharge
+		}
+		if group.resp.StatusCode == 0 && group.resp.CallErr == nil {
+			// the group was skipped because another group failed
+			continue
+		}
+		if err := group.resp.Error(); err != nil {
+			requestCharge := result.RequestCharge
+			result.RestResponse = group.resp
+			result.RequestCharge = requestCharge
+			result.Documents, result.NotFound = nil, nil
+			return result
+		}
+		for _, doc := range group.docs {
+			if len(group.items) == 1 {
+				found[_readManyKey(group.items[0].Id, group.items[0].PartitionKeyValues)] = doc
+			} else {
+				found[_readManyKey(doc.Id(), _extractPkValues(doc, pkInfo.Paths()))] = doc
+			}
+		}
+	}
+	for _, item := range items {
+		if doc, ok := found[_readManyKey(item.Id, item.PartitionKeyValues)]; ok {
+			result.Documents = append(result.Documents, doc)
+		} else {
+			result.NotFound = append(result.NotFound, item)
+		}
+	}
+	return result
+}
+
+// readManyGroup fetches the documents of a group of items belonging to the same partition key range.
+func (c *RestClient) readManyGroup(r ReadManyReq, pkPaths []string, group *readManyGroup) {
+	if len(group.items) == 1 {
+		item := group.items[0]
+		getResult := c.GetDocument(DocReq{DbName: r.DbName, CollName: r.CollName, DocId: item.Id, PartitionKeyValues: item.PartitionKeyValues,
+			ConsistencyLevel: r.ConsistencyLevel, SessionToken: r.SessionToken})
+		group.resp = getResult.RestResponse
+		if subStatus := getResult.RespHeader[respHeaderSubStatus]; getResult.StatusCode == 404 && (subStatus == "" || subStatus == "0") {
+			// not found is not an error, the item is reported in RespReadMany.NotFound (a non-zero sub-status
+			// means that the database or collection does not exist)
+			group.resp.CallErr, group.resp.ApiErr = nil, nil
+		} else if getResult.Error() == nil {
+			group.docs = []DocInfo{getResult.DocInfo}
+		}
+		return
+	}
+
+	// SELECT * FROM c WHERE (c.id=@id0 AND c["pk"]=@pk0_0) OR (c.id=@id1 AND c["pk"]=@pk1_0) OR ...
+	pkFields := make([]string, len(pkPaths))
+	for i, path := range pkPaths {
+		for _, name := range strings.Split(strings.TrimPrefix(path, "/"), "/") {
+			jsName, _ := json.Marshal(name)
+			pkFields[i] += "[" + string(jsName) + "]"
+		}
+	}
+	conditions := make([]string, len(group.items))
+	params := make([]interface{}, 0, len(group.items)*(1+len(pkPaths)))
+	for i, item := range group.items {
+		idParam := "@id" + strconv.Itoa(i)
+		condition := "c.id=" + idParam
+		params = append(params, map[string]interface{}{"name": idParam, "value": item.Id})
+		for j, pkField := range pkFields {
+			pkParam := fmt.Sprintf("@pk%d_%d", i, j)
+			condition += " AND c" + pkField + "=" + pkParam
+			params = append(params, map[string]interface{}{"name": pkParam, "value": item.PartitionKeyValues[j]})
+		}
+		conditions[i] = "(" + condition + ")"
+	}
+	query := QueryReq{
+		DbName:                r.DbName,
+		CollName:              r.CollName,
+		Query:                 "SELECT * FROM c WHERE " + strings.Join(conditions, " OR "),
+		Params:                params,
+		PkRangeId:             group.pkrangeId,
+		CrossPartitionEnabled: true,
+		ConsistencyLevel:      r.ConsistencyLevel,
+		SessionToken:          r.SessionToken,
+	}
+	var requestCharge float64
+	for {
+		req, err := c.buildQueryRequest(query)
+		if err != nil {
+			group.resp = RestResponse{CallErr: err}
+			return
+		}
+		resp := c.doRequest(req)
+		queryResult := &RespQueryDocs{RestResponse: c.buildRestResponse(resp)}
+		if queryResult.CallErr == nil {
+			queryResult.CallErr = json.Unmarshal(queryResult.RespBody, &queryResult)
+		}
+		if queryResult.RequestCharge > 0 {
+			requestCharge += queryResult.RequestCharge
+		}
+		group.resp = queryResult.RestResponse
+		group.resp.RequestCharge = requestCharge
+		if queryResult.Error() != nil {
+			return
+		}
+		group.docs = append(group.docs, queryResult.Documents.AsDocInfoSlice()...)
+		if query.ContinuationToken = queryResult.RespHeader[respHeaderContinuation]; query.ContinuationToken == "" {
+			return
+		}
+	}
+}
+
 // GetOfferForResource invokes Cosmos DB API to get offer info of a resource.
 //
 // Available since v0.1.1
@@ -1118,7 +1825,7 @@ func (c *RestClient) QueryOffers(query string) *RespQueryOffers {
 	req.Header.Set(httpHeaderContentType, "application/query+json")
 	req.Header.Set(restApiHeaderIsQuery, "true")
 
-	resp := c.client.Do(req)
+	resp := c.doRequest(req)
 	result := &RespQueryOffers{RestResponse: c.buildRestResponse(resp)}
 	if result.CallErr == nil {
 		result.ContinuationToken = result.RespHeader[respHeaderContinuation]
@@ -1177,46 +1884,147 @@ func (c *RestClient) ReplaceOfferForResource(rid string, ru, maxru int) *RespRep
 
 	getResult := c.GetOfferForResource(rid)
 	if getResult.Error() == nil {
-		method, urlEndpoint := "PUT", c.endpoint+"/offers/"+getResult.OfferInfo.Rid
-		params := map[string]interface{}{
-			"offerVersion": "V2", "offerType": "Invalid",
-			"resource":        getResult.OfferInfo.Resource,
-			"offerResourceId": getResult.OfferInfo.OfferResourceId,
-			"id":              getResult.OfferInfo.Rid,
-			"_rid":            getResult.OfferInfo.Rid,
-		}
 		content, headers := c.buildReplaceOfferContentAndHeaders(getResult.OfferInfo, ru, maxru)
 		if content == nil {
 			return &RespReplaceOffer{RestResponse: getResult.RestResponse, OfferInfo: getResult.OfferInfo}
 		}
-		params[restApiParamContent] = content
-		req, err := c.buildJsonRequest(method, urlEndpoint, params)
-		if err != nil {
-			return &RespReplaceOffer{RestResponse: RestResponse{CallErr: err}}
-		}
-		/*
-		 * [btnguyen2k] 2022-02-16
-		 * OfferInfo.Rid is returned from the server, but it _must_ be lower-cased when we send back to the server for
-		 * issuing the 'replace-offer' request.
-		 * Not sure if this is intended or a bug of Cosmos DB.
-		 */
-		req = c.addAuthHeader(req, method, "offers", strings.ToLower(getResult.OfferInfo.Rid))
-		for k, v := range headers {
-			req.Header.Set(k, v)
-		}
-		resp := c.client.Do(req)
-		result := &RespReplaceOffer{RestResponse: c.buildRestResponse(resp)}
+		result := c.replaceOffer(getResult.OfferInfo, content, headers)
 		if result.CallErr == nil {
 			if (headers[restApiHeaderMigrateToAutopilotThroughput] == "true" && maxru > 0) || (headers[restApiHeaderMigrateToManualThroughput] == "true" && ru > 0) {
 				return c.ReplaceOfferForResource(rid, ru, maxru)
 			}
-			result.CallErr = json.Unmarshal(result.RespBody, &result.OfferInfo)
 		}
 		return result
 	}
 	return &RespReplaceOffer{RestResponse: getResult.RestResponse}
 }
 
+// replaceOffer sends the request to replace the content of an offer, with the supplied extra headers.
+func (c *RestClient) replaceOffer(offer OfferInfo, content map[string]interface{}, headers map[string]string) *RespReplaceOffer {
+	method, urlEndpoint := "PUT", c.endpoint+"/offers/"+offer.Rid
+	params := map[string]interface{}{
+		"offerVersion": "V2", "offerType": "Invalid",
+		"resource":          offer.Resource,
+		"offerResourceId":   offer.OfferResourceId,
+		"id":                offer.Rid,
+		"_rid":              offer.Rid,
+		restApiParamContent: content,
+	}
+	req, err := c.buildJsonRequest(method, urlEndpoint, params)
+	if err != nil {
+		return &RespReplaceOffer{RestResponse: RestResponse{CallErr: err}}
+	}
+	/*
+	 * [btnguyen2k] 2022-02-16
+	 * OfferInfo.Rid is returned from the server, but it _must_ be lower-cased when we send back to the server for
+	 * issuing the 'replace-offer' request.
+	 * Not sure if this is intended or a bug of Cosmos DB.
+	 */
+	req = c.addAuthHeader(req, method, "offers", strings.ToLower(offer.Rid))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp := c.doRequest(req)
+	result := &RespReplaceOffer{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.ReplacePending = strings.EqualFold(result.RespHeader[respHeaderOfferReplacePending], "true")
+		result.CallErr = json.Unmarshal(result.RespBody, &result.OfferInfo)
+	}
+	return result
+}
+
+// MigrateOfferToAutoscale invokes Cosmos DB API to switch the throughput of a resource (database or collection,
+// identified by its rid) from manual to autoscale provisioning. The maximum throughput is computed by Cosmos DB from
+// the current throughput. Nothing is changed if the throughput is already autoscale.
+//
+// The migration may complete asynchronously: RespReplaceOffer.ReplacePending is true in this case, use
+// WaitForOfferReplace to wait for the completion.
+//
+// Available since v1.2.0
+func (c *RestClient) MigrateOfferToAutoscale(rid string) *RespReplaceOffer {
+	return c.migrateOffer(rid, true)
+}
+
+// MigrateOfferToManual invokes Cosmos DB API to switch the throughput of a resource (database or collection,
+// identified by its rid) from autoscale to manual provisioning. The throughput is computed by Cosmos DB from the
+// current maximum throughput. Nothing is changed if the throughput is already manual.
+//
+// The migration may complete asynchronously: RespReplaceOffer.ReplacePending is true in this case, use
+// WaitForOfferReplace to wait for the completion.
+//
+// Available since v1.2.0
+func (c *RestClient) MigrateOfferToManual(rid string) *RespReplaceOffer {
+	return c.migrateOffer(rid, false)
+}
+
+func (c *RestClient) migrateOffer(rid string, toAutoscale bool) *RespReplaceOffer {
+	getResult := c.GetOfferForResource(rid)
+	if getResult.Error() != nil {
+		return &RespReplaceOffer{RestResponse: getResult.RestResponse}
+	}
+	if getResult.IsAutopilot() == toAutoscale {
+		return &RespReplaceOffer{RestResponse: getResult.RestResponse, OfferInfo: getResult.OfferInfo}
+	}
+	if toAutoscale {
+		return c.replaceOffer(getResult.OfferInfo, map[string]interface{}{"offerThroughput": -1},
+			map[string]string{restApiHeaderMigrateToAutopilotThroughput: "true"})
+	}
+	return c.replaceOffer(getResult.OfferInfo, map[string]interface{}{"offerAutopilotSettings": map[string]interface{}{"maxThroughput": -1}},
+		map[string]string{restApiHeaderMigrateToManualThroughput: "true"})
+}
+
+// GetOffer invokes Cosmos DB API to get an offer by its rid. Unlike GetOfferForResource, the response reports
+// whether a replacement of the offer is pending (see RespGetOffer.ReplacePending).
+//
+// See: https://learn.microsoft.com/en-us/rest/api/cosmos-db/get-an-offer.
+//
+// Available since v1.2.0
+func (c *RestClient) GetOffer(offerRid string) *RespGetOffer {
+	method, urlEndpoint := "GET", c.endpoint+"/offers/"+offerRid
+	req, err := c.buildJsonRequest(method, urlEndpoint, nil)
+	if err != nil {
+		return &RespGetOffer{RestResponse: RestResponse{CallErr: err}}
+	}
+	req = c.addAuthHeader(req, method, "offers", strings.ToLower(offerRid))
+
+	resp := c.doRequest(req)
+	result := &RespGetOffer{RestResponse: c.buildRestResponse(resp)}
+	if result.CallErr == nil {
+		result.ReplacePending = strings.EqualFold(result.RespHeader[respHeaderOfferReplacePending], "true")
+		result.CallErr = json.Unmarshal(result.RespBody, &result.OfferInfo)
+	}
+	return result
+}
+
+// WaitForOfferReplace polls the offer of a resource (database or collection, identified by its rid) every
+// pollInterval until its pending replacement (e.g. a throughput migration) completes, or until timeout elapses.
+// The last read offer is returned; if the replacement is still pending after timeout, the response's CallErr is set
+// and ReplacePending is true.
+//
+// Available since v1.2.0
+func (c *RestClient) WaitForOfferReplace(rid string, pollInterval, timeout time.Duration) *RespGetOffer {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	result := c.GetOfferForResource(rid)
+	if result.Error() != nil {
+		return result
+	}
+	offerRid := result.Rid
+	deadline := time.Now().Add(timeout)
+	for {
+		result = c.GetOffer(offerRid)
+		if result.Error() != nil || !result.ReplacePending {
+			return result
+		}
+		if !time.Now().Add(pollInterval).Before(deadline) {
+			result.CallErr = fmt.Errorf("replacement of offer %s is still pending after %s", offerRid, timeout)
+			return result
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
 /*----------------------------------------------------------------------*/
 
 // RestResponse captures the response from REST API call.
@@ -1235,6 +2043,9 @@ type RestResponse struct {
 	RequestCharge float64
 	// SessionToken is used with session level consistency. Clients must save this value and set it for subsequent read requests for session consistency.
 	SessionToken string
+	// Region (available since v1.2.0) is the name of the region that served the request. It is populated only if
+	// region routing is enabled (see Config.PreferredRegions).
+	Region string
 }
 
 // Error returns CallErr if not nil, ApiErr otherwise.
@@ -1245,6 +2056,64 @@ func (r RestResponse) Error() error {
 	return r.ApiErr
 }
 
+// AccountLocation captures a region of a Cosmos DB account.
+//
+// Available since v1.2.0
+type AccountLocation struct {
+	Name     string `json:"name"`                    // name of the region, e.g. "East US"
+	Endpoint string `json:"databaseAccountEndpoint"` // regional endpoint of the account
+}
+
+// ConsistencyPolicy captures the default consistency settings of a Cosmos DB account.
+//
+// Available since v1.2.0
+type ConsistencyPolicy struct {
+	DefaultConsistencyLevel string `json:"defaultConsistencyLevel"` // "Strong", "BoundedStaleness", "Session", "ConsistentPrefix" or "Eventual"
+	MaxStalenessPrefix      int64  `json:"maxStalenessPrefix"`      // max lag (number of writes) of reads, used with BoundedStaleness
+	MaxIntervalInSeconds    int    `json:"maxIntervalInSeconds"`    // max lag (in seconds) of reads, used with BoundedStaleness
+}
+
+// DatabaseAccount captures the properties of a Cosmos DB account.
+//
+// Available since v1.2.0
+type DatabaseAccount struct {
+	Id                           string                 `json:"id"`                           // name of the account
+	Rid                          string                 `json:"_rid"`                         // (system generated property) _rid attribute of the account
+	Self                         string                 `json:"_self"`                        // (system-generated property) _self attribute of the account
+	Media                        string                 `json:"media"`                        // (system-generated property) media link of the account
+	Addresses                    string                 `json:"addresses"`                    // (system-generated property) addresses link of the account
+	Dbs                          string                 `json:"_dbs"`                         // (system-generated property) _dbs attribute of the account
+	WritableLocations            []AccountLocation      `json:"writableLocations"`            // regions accepting writes, the first one is the write region of a single-write account
+	ReadableLocations            []AccountLocation      `json:"readableLocations"`            // regions accepting reads
+	EnableMultipleWriteLocations bool                   `json:"enableMultipleWriteLocations"` // true if the account accepts writes in all of its regions
+	UserConsistencyPolicy        ConsistencyPolicy      `json:"userConsistencyPolicy"`        // default consistency settings of the account
+	UserReplicationPolicy        map[string]interface{} `json:"userReplicationPolicy"`        // replication settings of the account
+	SystemReplicationPolicy      map[string]interface{} `json:"systemReplicationPolicy"`      // replication settings of the system databases
+	ReadPolicy                   map[string]interface{} `json:"readPolicy"`                   // read settings of the account
+	QueryEngineConfiguration     map[string]interface{} `json:"-"`                            // limits of the query engine, e.g. "maxSqlQueryInputLength" or "maxJoinsPerSqlQuery"
+	MaxMediaStorageUsageMb       int64                  `json:"-"`                            // max storage of attachments' media, in MB
+	MediaStorageUsageMb          int64                  `json:"-"`                            // storage used by attachments' media, in MB
+}
+
+// QueryEngineLimit returns the value of a setting of the query engine configuration, e.g. "maxSqlQueryInputLength",
+// -1 if the setting is not available.
+func (a DatabaseAccount) QueryEngineLimit(name string) int64 {
+	if v, ok := a.QueryEngineConfiguration[name]; ok && v != nil {
+		if limit, err := reddo.ToInt(v); err == nil {
+			return limit
+		}
+	}
+	return -1
+}
+
+// RespGetDatabaseAccount captures the response from RestClient.GetDatabaseAccount call.
+//
+// Available since v1.2.0
+type RespGetDatabaseAccount struct {
+	RestResponse
+	DatabaseAccount
+}
+
 // DbInfo captures info of a Cosmos DB database.
 type DbInfo struct {
 	Id    string `json:"id"`     // user-generated unique name for the database
@@ -1315,7 +2184,7 @@ func (pk PkInfo) Version() int {
 
 func (pk PkInfo) Paths() []string {
 	paths, err := reddo.ToSlice(pk["paths"], reddo.TypeString)
-	if err == nil {
+	if err == nil && paths != nil {
 		return paths.([]string)
 	}
 	return nil
@@ -1337,6 +2206,8 @@ type CollInfo struct {
 	PartitionKey             PkInfo                 `json:"partitionKey"`             // partitioning configuration settings for collection
 	ConflictResolutionPolicy map[string]interface{} `json:"conflictResolutionPolicy"` // conflict resolution policy settings for collection
 	GeospatialConfig         map[string]interface{} `json:"geospatialConfig"`         // Geo-spatial configuration settings for collection
+	DefaultTtl               int                    `json:"defaultTtl"`               // (available since v1.2.0) default time-to-live of documents in seconds: 0 if off, -1 if on without default expiry
+	UniqueKeyPolicy          map[string]interface{} `json:"uniqueKeyPolicy"`          // (available since v1.2.0) unique keys of the collection
 }
 
 func (c *CollInfo) toMap() map[string]interface{} {
@@ -1355,6 +2226,8 @@ func (c *CollInfo) toMap() map[string]interface{} {
 		"partitionKey":             c.PartitionKey,
 		"conflictResolutionPolicy": c.ConflictResolutionPolicy,
 		"geospatialConfig":         c.GeospatialConfig,
+		"defaultTtl":               c.DefaultTtl,
+		"uniqueKeyPolicy":          c.UniqueKeyPolicy,
 	}
 }
 
@@ -1374,6 +2247,10 @@ type RespReplaceColl struct {
 type RespGetColl struct {
 	RestResponse
 	CollInfo
+
+	// (available since v1.2.0) progress (from 0 to 100) of the transformation of the collection's indexes, populated
+	// by RestClient.GetIndexingProgress (-1 if not available).
+	IndexTransformationProgress int
 }
 
 // RespDeleteColl captures the response from RestClient.DeleteCollection call.
@@ -1798,6 +2675,24 @@ type RespQueryDocs struct {
 	ContinuationToken  string         `json:"-"`
 	QueryPlan          *RespQueryPlan `json:"-"` // (available since v0.2.0) the query plan used to execute the query
 	RewrittenDocuments QueriedDocs    `json:"-"` // (available since v0.2.0) the original returned documents from the execution of RespQueryPlan.QueryInfo.RewrittenQuery
+
+	// (available since v1.2.0) execution metrics of the query, keyed by the id of the partition key range the metrics are
+	// reported for
+	QueryMetrics map[string]*QueryMetrics `json:"-"`
+}
+
+// populateQueryMetrics parses the query metrics reported by the server for a single query request.
+func (r *RespQueryDocs) populateQueryMetrics(pkRangeId string) {
+	header, ok := r.RespHeader[respHeaderQueryMetrics]
+	if !ok {
+		return
+	}
+	if pkRangeId == "" {
+		pkRangeId = r.RespHeader[respHeaderPkRangeId]
+	}
+	metrics := _parseQueryMetrics(header)
+	metrics.RequestCharge = r.RequestCharge
+	r.QueryMetrics = map[string]*QueryMetrics{pkRangeId: metrics}
 }
 
 // Available since v0.2.0
@@ -1844,6 +2739,17 @@ type RespQueryPlan struct {
 		HasSelectValue              bool              `json:"hasSelectValue"`
 		DCountInfo                  typDCountInfo     `json:"dCountInfo"`
 	} `json:"queryInfo"`
+	QueryRanges []QueryRange `json:"queryRanges"` // (available since v1.2.0) ranges of effective partition key values targeted by the query
+}
+
+// QueryRange is a range of effective partition key values targeted by a query, as reported by the query plan.
+//
+// Available since v1.2.0
+type QueryRange struct {
+	Min            string `json:"min"`
+	Max            string `json:"max"`
+	IsMinInclusive bool   `json:"isMinInclusive"`
+	IsMaxInclusive bool   `json:"isMaxInclusive"`
 }
 
 // IsDistinctQuery tests if duplicates are eliminated in the query's projection.
@@ -1867,6 +2773,23 @@ func (qp *RespQueryPlan) IsOrderByQuery() bool {
 	return len(qp.QueryInfo.OrderByExpressions) > 0
 }
 
+// TargetPkranges returns the partition key ranges, among the supplied ones, that overlap the ranges targeted by the query.
+//
+// Available since v1.2.0
+func (qp *RespQueryPlan) TargetPkranges(pkranges []PkrangeInfo) []PkrangeInfo {
+	result := make([]PkrangeInfo, 0)
+	for _, pkrange := range pkranges {
+		for _, r := range qp.QueryRanges {
+			// effective partition key values are hex strings, hence they are compared as strings
+			if r.Min < pkrange.MaxExclusive && (r.Max > pkrange.MinInclusive || (r.IsMaxInclusive && r.Max == pkrange.MinInclusive)) {
+				result = append(result, pkrange)
+				break
+			}
+		}
+	}
+	return result
+}
+
 // RespListDocs captures the response from RestClient.ListDocuments call.
 type RespListDocs struct {
 	RestResponse      `json:"-"`
@@ -1876,6 +2799,15 @@ type RespListDocs struct {
 	Etag              string    `json:"-"` // logical sequence number (LSN) of last document returned in the response
 }
 
+// RespReadMany captures the response from RestClient.ReadMany call.
+//
+// Available since v1.2.0
+type RespReadMany struct {
+	RestResponse
+	Documents []DocInfo      // documents that have been found, in the order of the requested items
+	NotFound  []ItemIdentity // requested items that do not exist, in the order of the requested items
+}
+
 // OfferInfo captures info of a Cosmos DB offer.
 //
 // See: https://docs.microsoft.com/en-us/rest/api/cosmos-db/offers.
@@ -1922,6 +2854,20 @@ func (o OfferInfo) MaxThroughputEverProvisioned() int {
 	return 0
 }
 
+// AutopilotMaxThroughput returns value of field 'offerAutopilotSettings.maxThroughput', 0 if autopilot is not enabled.
+//
+// Available since v1.2.0
+func (o OfferInfo) AutopilotMaxThroughput() int {
+	if o._s == nil {
+		o._s = semita.NewSemita(o.Content)
+	}
+	v, err := o._s.GetValueOfType("offerAutopilotSettings.maxThroughput", reddo.TypeInt)
+	if err == nil {
+		return int(v.(int64))
+	}
+	return 0
+}
+
 // IsAutopilot returns true if autopilot is enabled, false otherwise.
 func (o OfferInfo) IsAutopilot() bool {
 	//o._lock.Lock()
@@ -1937,6 +2883,10 @@ func (o OfferInfo) IsAutopilot() bool {
 type RespGetOffer struct {
 	RestResponse
 	OfferInfo
+
+	// (available since v1.2.0) true if a replacement of the offer (e.g. a throughput migration) is still in
+	// progress, populated by RestClient.GetOffer and RestClient.WaitForOfferReplace.
+	ReplacePending bool
 }
 
 // RespQueryOffers captures the response from RestClient.QueryOffers call.
@@ -1951,6 +2901,127 @@ type RespQueryOffers struct {
 type RespReplaceOffer struct {
 	RestResponse
 	OfferInfo
+
+	// (available since v1.2.0) true if the replacement of the offer completes asynchronously, see
+	// RestClient.WaitForOfferReplace.
+	ReplacePending bool
+}
+
+// ConflictInfo captures info of an entry of a collection's conflicts feed.
+//
+// Available since v1.2.0
+type ConflictInfo struct {
+	Id            string `json:"id"`            // unique id of the conflict
+	ResourceId    string `json:"resourceId"`    // _rid of the conflicting resource
+	ResourceType  string `json:"resourceType"`  // type of the conflicting resource, e.g. "document"
+	OperationType string `json:"operationType"` // the conflicting operation: "create", "replace" or "delete"
+	Content       string `json:"content"`       // JSON-encoded content of the conflicting resource
+	Rid           string `json:"_rid"`          // (system generated property) _rid attribute of the conflict
+	Ts            int64  `json:"_ts"`           // (system-generated property) _ts attribute of the conflict
+	Self          string `json:"_self"`         // (system-generated property) _self attribute of the conflict
+	Etag          string `json:"_etag"`         // (system-generated property) _etag attribute of the conflict
+}
+
+// ContentAsDoc decodes the content of the conflicting resource as a document.
+func (ci ConflictInfo) ContentAsDoc() (DocInfo, error) {
+	if ci.Content == "" {
+		return nil, nil
+	}
+	var doc DocInfo
+	err := json.Unmarshal([]byte(ci.Content), &doc)
+	return doc, err
+}
+
+// RespListConflicts captures the response from RestClient.ListConflicts call.
+//
+// Available since v1.2.0
+type RespListConflicts struct {
+	RestResponse      `json:"-"`
+	Count             int            `json:"_count"` // number of conflicts returned from the operation
+	Conflicts         []ConflictInfo `json:"Conflicts"`
+	ContinuationToken string         `json:"-"`
+}
+
+// RespGetConflict captures the response from RestClient.GetConflict call.
+//
+// Available since v1.2.0
+type RespGetConflict struct {
+	RestResponse
+	ConflictInfo
+}
+
+// RespDeleteConflict captures the response from RestClient.DeleteConflict call.
+//
+// Available since v1.2.0
+type RespDeleteConflict struct {
+	RestResponse
+}
+
+// AttachmentInfo captures info of a Cosmos DB attachment.
+//
+// Available since v1.2.0
+type AttachmentInfo struct {
+	Id          string `json:"id"`          // user-generated unique name for the attachment
+	ContentType string `json:"contentType"` // MIME content type of the media
+	Media       string `json:"media"`       // URL of the external media, or link to the media stored in Cosmos DB ("/media/...")
+	Rid         string `json:"_rid"`        // (system generated property) _rid attribute of the attachment
+	Ts          int64  `json:"_ts"`         // (system-generated property) _ts attribute of the attachment
+	Self        string `json:"_self"`       // (system-generated property) _self attribute of the attachment
+	Etag        string `json:"_etag"`       // (system-generated property) _etag attribute of the attachment
+}
+
+// IsInlineMedia returns true if the media of the attachment is stored in Cosmos DB (see RestClient.GetAttachmentMedia).
+func (a AttachmentInfo) IsInlineMedia() bool {
+	return strings.HasPrefix(a.Media, "/media/")
+}
+
+// RespCreateAttachment captures the response from RestClient.CreateAttachment call.
+//
+// Available since v1.2.0
+type RespCreateAttachment struct {
+	RestResponse
+	AttachmentInfo
+}
+
+// RespReplaceAttachment captures the response from RestClient.ReplaceAttachment call.
+//
+// Available since v1.2.0
+type RespReplaceAttachment struct {
+	RestResponse
+	AttachmentInfo
+}
+
+// RespGetAttachment captures the response from RestClient.GetAttachment call.
+//
+// Available since v1.2.0
+type RespGetAttachment struct {
+	RestResponse
+	AttachmentInfo
+}
+
+// RespGetAttachmentMedia captures the response from RestClient.GetAttachmentMedia call.
+//
+// Available since v1.2.0
+type RespGetAttachmentMedia struct {
+	RestResponse
+	ContentType string // MIME content type of the media
+	Media       []byte // content of the media
+}
+
+// RespDeleteAttachment captures the response from RestClient.DeleteAttachment call.
+//
+// Available since v1.2.0
+type RespDeleteAttachment struct {
+	RestResponse
+}
+
+// RespListAttachments captures the response from RestClient.ListAttachments call.
+//
+// Available since v1.2.0
+type RespListAttachments struct {
+	RestResponse `json:"-"`
+	Count        int              `json:"_count"` // number of attachments returned from the list operation
+	Attachments  []AttachmentInfo `json:"Attachments"`
 }
 
 // PkrangeInfo captures info of a collection's partition key range.
@@ -1959,13 +3030,14 @@ type RespReplaceOffer struct {
 //
 // Available since v0.1.3.
 type PkrangeInfo struct {
-	Id           string `json:"id"`           // the stable and unique ID for the partition key range within each collection
-	MaxExclusive string `json:"maxExclusive"` // (internal use) the maximum partition key hash value for the partition key range
-	MinInclusive string `json:"minInclusive"` // (minimum use) the maximum partition key hash value for the partition key range
-	Rid          string `json:"_rid"`         // (system generated property) _rid attribute of the pkrange
-	Ts           int64  `json:"_ts"`          // (system-generated property) _ts attribute of the pkrange
-	Self         string `json:"_self"`        // (system-generated property) _self attribute of the pkrange
-	Etag         string `json:"_etag"`        // (system-generated property) _etag attribute of the pkrange
+	Id           string   `json:"id"`           // the stable and unique ID for the partition key range within each collection
+	MaxExclusive string   `json:"maxExclusive"` // (internal use) the maximum partition key hash value for the partition key range
+	MinInclusive string   `json:"minInclusive"` // (minimum use) the maximum partition key hash value for the partition key range
+	Rid          string   `json:"_rid"`         // (system generated property) _rid attribute of the pkrange
+	Ts           int64    `json:"_ts"`          // (system-generated property) _ts attribute of the pkrange
+	Self         string   `json:"_self"`        // (system-generated property) _self attribute of the pkrange
+	Etag         string   `json:"_etag"`        // (system-generated property) _etag attribute of the pkrange
+	Parents      []string `json:"parents"`      // (available since v1.2.0) ids of the partition key ranges this range was split from
 }
 
 // RespGetPkranges captures the response from GetPkranges call.
@@ -0,0 +1,361 @@
+package gocosmos
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestClient_GetDatabaseAccount(t *testing.T) {
+	testName := "TestRestClient_GetDatabaseAccount"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" || r.URL.Path != "/" || r.Header.Get(httpHeaderAuthorization) == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(respHeaderMaxMediaStorageUsageMb, "10240")
+		w.Header().Set(respHeaderMediaStorageUsageMb, "12")
+		_, _ = w.Write([]byte(`{"id":"myaccount","_rid":"myaccount.documents.azure.com","media":"//media/","addresses":"//addresses/","_dbs":"//dbs/",
+"writableLocations":[{"name":"West US","databaseAccountEndpoint":"https://myaccount-westus.documents.azure.com:443/"}],
+"readableLocations":[{"name":"West US","databaseAccountEndpoint":"https://myaccount-westus.documents.azure.com:443/"},{"name":"East US","databaseAccountEndpoint":"https://myaccount-eastus.documents.azure.com:443/"}],
+"enableMultipleWriteLocations":false,
+"userConsistencyPolicy":{"defaultConsistencyLevel":"BoundedStaleness","maxStalenessPrefix":100,"maxIntervalInSeconds":5},
+"userReplicationPolicy":{"asyncReplication":false,"minReplicaSetSize":3,"maxReplicasetSize":4},
+"queryEngineConfiguration":"{\"maxSqlQueryInputLength\":262144,\"maxJoinsPerSqlQuery\":5}"}`))
+	}))
+	defer server.Close()
+	client, err := NewRestClientWithConfig(NewConfig(server.URL, "ZGVtbw=="))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	result := client.GetDatabaseAccount()
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if result.Id != "myaccount" || result.EnableMultipleWriteLocations || result.MaxMediaStorageUsageMb != 10240 || result.MediaStorageUsageMb != 12 {
+		t.Fatalf("%s failed: received %#v", testName, result.DatabaseAccount)
+	}
+	expectedReadable := []AccountLocation{{Name: "West US", Endpoint: "https://myaccount-westus.documents.azure.com:443/"}, {Name: "East US", Endpoint: "https://myaccount-eastus.documents.azure.com:443/"}}
+	if !reflect.DeepEqual(result.ReadableLocations, expectedReadable) || len(result.WritableLocations) != 1 || result.WritableLocations[0].Name != "West US" {
+		t.Fatalf("%s failed: unexpected locations %#v / %#v", testName, result.WritableLocations, result.ReadableLocations)
+	}
+	expectedPolicy := ConsistencyPolicy{DefaultConsistencyLevel: "BoundedStaleness", MaxStalenessPrefix: 100, MaxIntervalInSeconds: 5}
+	if result.UserConsistencyPolicy != expectedPolicy {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, expectedPolicy, result.UserConsistencyPolicy)
+	}
+	if v := result.QueryEngineLimit("maxSqlQueryInputLength"); v != 262144 {
+		t.Fatalf("%s failed: <maxSqlQueryInputLength> expected %#v but received %#v", testName, 262144, v)
+	}
+	if v := result.QueryEngineLimit("notExists"); v != -1 {
+		t.Fatalf("%s failed: <notExists> expected %#v but received %#v", testName, -1, v)
+	}
+}
+
+func TestRestClient_MigrateOffer(t *testing.T) {
+	testName := "TestRestClient_MigrateOffer"
+	var numGets, numReplaces int32
+	var replaceHeaders http.Header
+	offer := `{"id":"offer1","_rid":"Offer1","offerVersion":"V2","offerResourceId":"rid1","resource":"dbs/rid1/","content":{"offerThroughput":400}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/offers":
+			_, _ = w.Write([]byte(`{"_count":1,"Offers":[` + offer + `]}`))
+		case r.Method == "PUT" && r.URL.Path == "/offers/Offer1":
+			atomic.AddInt32(&numReplaces, 1)
+			replaceHeaders = r.Header.Clone()
+			w.Header().Set(respHeaderOfferReplacePending, "true")
+			_, _ = w.Write([]byte(offer))
+		case r.Method == "GET" && r.URL.Path == "/offers/Offer1":
+			// the replacement completes at the 3rd read
+			w.Header().Set(respHeaderOfferReplacePending, strconv.FormatBool(atomic.AddInt32(&numGets, 1) < 3))
+			_, _ = w.Write([]byte(offer))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewRestClientWithConfig(NewConfig(server.URL, "ZGVtbw=="))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	// already manual: nothing to do
+	if result := client.MigrateOfferToManual("rid1"); result.Error() != nil || result.ReplacePending || atomic.LoadInt32(&numReplaces) != 0 {
+		t.Fatalf("%s failed: received error %s, pending %#v and %d replace requests", testName+"/MigrateOfferToManual", result.Error(), result.ReplacePending, numReplaces)
+	}
+	result := client.MigrateOfferToAutoscale("rid1")
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/MigrateOfferToAutoscale", err)
+	}
+	if !result.ReplacePending || replaceHeaders.Get(restApiHeaderMigrateToAutopilotThroughput) != "true" {
+		t.Fatalf("%s failed: received pending %#v and headers %#v", testName+"/MigrateOfferToAutoscale", result.ReplacePending, replaceHeaders)
+	}
+
+	if result := client.WaitForOfferReplace("rid1", time.Millisecond, time.Millisecond); result.Error() == nil || !result.ReplacePending {
+		t.Fatalf("%s failed: expected the replacement to be still pending", testName+"/WaitForOfferReplace")
+	}
+	if result := client.WaitForOfferReplace("rid1", time.Millisecond, time.Second); result.Error() != nil || result.ReplacePending || atomic.LoadInt32(&numGets) != 3 {
+		t.Fatalf("%s failed: received error %s, pending %#v after %d reads", testName+"/WaitForOfferReplace", result.Error(), result.ReplacePending, numGets)
+	}
+}
+
+func TestRestClient_ReplaceCollection_keepsProperties(t *testing.T) {
+	testName := "TestRestClient_ReplaceCollection_keepsProperties"
+	coll := `{"id":"table","_rid":"Coll1","_ts":1700000000,"_self":"dbs/Db1/colls/Coll1/","_etag":"\"e1\"",
+"partitionKey":{"paths":["/pk"],"kind":"Hash","version":2},
+"indexingPolicy":{"indexingMode":"consistent","automatic":true,"includedPaths":[{"path":"/*"}],"excludedPaths":[{"path":"/\"_etag\"/?"}]},
+"uniqueKeyPolicy":{"uniqueKeys":[{"paths":["/email"]},{"paths":["/name","/phone"]}]},
+"conflictResolutionPolicy":{"mode":"LastWriterWins","conflictResolutionPath":"/version","conflictResolutionProcedure":""},
+"geospatialConfig":{"type":"Geometry"},"computedProperties":[{"name":"cp_lower","query":"SELECT VALUE LOWER(c.name) FROM c"}],
+"defaultTtl":3600}`
+	var replaced map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/dbs/db/colls/table":
+			_, _ = w.Write([]byte(coll))
+		case r.Method == "PUT" && r.URL.Path == "/dbs/db/colls/table":
+			replaced = nil
+			_ = json.NewDecoder(r.Body).Decode(&replaced)
+			_, _ = w.Write([]byte(coll))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewRestClientWithConfig(NewConfig(server.URL, "ZGVtbw=="))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	_, err = _modifyIndexingPolicy(newConn(client, ""), "db", "table", func(policy map[string]interface{}) (bool, error) {
+		policy["excludedPaths"] = append(policy["excludedPaths"].([]interface{}), map[string]interface{}{"path": "/notes/*"})
+		return true, nil
+	})
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	var expected map[string]interface{}
+	_ = json.Unmarshal([]byte(coll), &expected)
+	for k := range expected {
+		if strings.HasPrefix(k, "_") {
+			delete(expected, k)
+		}
+	}
+	expected["indexingPolicy"].(map[string]interface{})["excludedPaths"] = []interface{}{
+		map[string]interface{}{"path": `/"_etag"/?`}, map[string]interface{}{"path": "/notes/*"}}
+	if !reflect.DeepEqual(replaced, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, replaced)
+	}
+}
+
+func TestRestClient_Attachments(t *testing.T) {
+	testName := "TestRestClient_Attachments"
+	media := []byte{0x89, 'P', 'N', 'G'}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(httpHeaderAuthorization) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/dbs/db1/colls/coll1/docs/doc1/attachments":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get(restApiHeaderPartitionKey) != `["user"]` {
+				w.WriteHeader(http.StatusBadRequest)
+			} else if r.Header.Get(httpHeaderContentType) == "image/png" {
+				// inline media: raw bytes, id in the Slug header
+				if !bytes.Equal(body, media) || r.Header.Get(httpHeaderSlug) != "inline" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"inline","contentType":"image/png","media":"/media/AbCdEf==","_rid":"AbCdEf==","_etag":"\"1\""}`))
+			} else {
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write(append([]byte(`{"_rid":"GhIjKl==","_etag":"\"2\"",`), body[1:]...))
+			}
+		case r.Method == "PUT" && r.URL.Path == "/dbs/db1/colls/coll1/docs/doc1/attachments/external":
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get(httpHeaderIfMatch) != `"2"` {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				return
+			}
+			_, _ = w.Write(append([]byte(`{"_rid":"GhIjKl==","_etag":"\"3\"",`), body[1:]...))
+		case r.Method == "GET" && r.URL.Path == "/dbs/db1/colls/coll1/docs/doc1/attachments/inline":
+			_, _ = w.Write([]byte(`{"id":"inline","contentType":"image/png","media":"/media/AbCdEf==","_rid":"AbCdEf==","_etag":"\"1\""}`))
+		case r.Method == "GET" && r.URL.Path == "/media/AbCdEf==":
+			w.Header().Set(httpHeaderContentType, "image/png")
+			_, _ = w.Write(media)
+		case r.Method == "GET" && r.URL.Path == "/dbs/db1/colls/coll1/docs/doc1/attachments":
+			_, _ = w.Write([]byte(`{"_count":2,"Attachments":[{"id":"inline","media":"/media/AbCdEf=="},{"id":"external","media":"https://localhost/image.png"}]}`))
+		case r.Method == "DELETE" && r.URL.Path == "/dbs/db1/colls/coll1/docs/doc1/attachments/inline":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client, err := NewRestClientWithConfig(NewConfig(server.URL, "ZGVtbw=="))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	pk := []interface{}{"user"}
+
+	if result := client.CreateAttachment(AttachmentSpec{DbName: "db1", CollName: "coll1", DocId: "doc1", PartitionKeyValues: pk,
+		Id: "external", ContentType: "image/png", MediaLink: "https://localhost/image.png"}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/CreateAttachment/external", result.Error())
+	} else if result.Id != "external" || result.Media != "https://localhost/image.png" || result.IsInlineMedia() || result.Etag != `"2"` {
+		t.Fatalf("%s failed: received %#v", testName+"/CreateAttachment/external", result.AttachmentInfo)
+	}
+	if result := client.CreateAttachment(AttachmentSpec{DbName: "db1", CollName: "coll1", DocId: "doc1", PartitionKeyValues: pk,
+		Id: "inline", ContentType: "image/png", Media: media}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/CreateAttachment/inline", result.Error())
+	} else if result.Id != "inline" || !result.IsInlineMedia() {
+		t.Fatalf("%s failed: received %#v", testName+"/CreateAttachment/inline", result.AttachmentInfo)
+	}
+
+	if result := client.ReplaceAttachment(`"2"`, AttachmentSpec{DbName: "db1", CollName: "coll1", DocId: "doc1", PartitionKeyValues: pk,
+		Id: "external", ContentType: "image/jpeg", MediaLink: "https://localhost/image.jpg"}); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/ReplaceAttachment", result.Error())
+	} else if result.ContentType != "image/jpeg" || result.Media != "https://localhost/image.jpg" || result.Etag != `"3"` {
+		t.Fatalf("%s failed: received %#v", testName+"/ReplaceAttachment", result.AttachmentInfo)
+	}
+	if result := client.ReplaceAttachment("", AttachmentSpec{DbName: "db1", CollName: "coll1", DocId: "doc1", Id: "inline", Media: media}); result.CallErr == nil {
+		t.Fatalf("%s failed: replacing inline media must fail", testName+"/ReplaceAttachment/inline")
+	}
+
+	req := AttachmentReq{DbName: "db1", CollName: "coll1", DocId: "doc1", AttachmentId: "inline", PartitionKeyValues: pk}
+	if result := client.GetAttachmentMedia(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/GetAttachmentMedia", result.Error())
+	} else if result.ContentType != "image/png" || !bytes.Equal(result.Media, media) {
+		t.Fatalf("%s failed: received %#v / %#v", testName+"/GetAttachmentMedia", result.ContentType, result.Media)
+	}
+	if result := client.ListAttachments(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/ListAttachments", result.Error())
+	} else if result.Count != 2 || len(result.Attachments) != 2 || result.Attachments[0].Id != "external" || result.Attachments[1].Id != "inline" {
+		t.Fatalf("%s failed: received %#v", testName+"/ListAttachments", result.Attachments)
+	}
+	if result := client.DeleteAttachment(req); result.Error() != nil {
+		t.Fatalf("%s failed: %s", testName+"/DeleteAttachment", result.Error())
+	}
+	req.AttachmentId = "notfound"
+	if result := client.GetAttachment(req); result.StatusCode != 404 {
+		t.Fatalf("%s failed: <status-code> expected %#v but received %#v", testName+"/GetAttachment", 404, result.StatusCode)
+	}
+}
+
+func TestRestClient_ReadMany(t *testing.T) {
+	testName := "TestRestClient_ReadMany"
+	docs := map[string]map[string]string{
+		`["user1"]`: {"1": `{"id":"1","username":"user1"}`, "2": `{"id":"2","username":"user1"}`},
+		`["user2"]`: {"1": `{"id":"1","username":"user2"}`, "3": `{"id":"3","username":"user2"}`},
+	}
+	// effective partition keys: user1 = 236A..., user2 = 32E4..., user3 = 02EA...
+	pkranges := `{"_count":2,"PartitionKeyRanges":[{"id":"0","minInclusive":"","maxExclusive":"20"},{"id":"1","minInclusive":"20","maxExclusive":"FF"}]}`
+	var numPointReads, numQueries int32
+	var queriedRange string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pkDocs := docs[r.Header.Get(restApiHeaderPartitionKey)]
+		w.Header().Set(respHeaderRequestCharge, "1.5")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/dbs/db1/colls/notfound"):
+			w.Header().Set(respHeaderSubStatus, "1003")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"NotFound"}`))
+		case r.Method == "GET" && r.URL.Path == "/dbs/db1/colls/coll1":
+			_, _ = w.Write([]byte(`{"id":"coll1","partitionKey":{"paths":["/username"],"kind":"Hash","version":2}}`))
+		case r.Method == "GET" && r.URL.Path == "/dbs/db1/colls/coll1/pkranges":
+			_, _ = w.Write([]byte(pkranges))
+		case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/dbs/db1/colls/coll1/docs/"):
+			atomic.AddInt32(&numPointReads, 1)
+			doc, ok := pkDocs[strings.TrimPrefix(r.URL.Path, "/dbs/db1/colls/coll1/docs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(doc))
+		case r.Method == "POST" && r.URL.Path == "/dbs/db1/colls/coll1/docs" && r.Header.Get(restApiHeaderIsQuery) == "true":
+			atomic.AddInt32(&numQueries, 1)
+			queriedRange = r.Header.Get(restApiHeaderPartitionKeyRangeId)
+			var body struct {
+				Query      string `json:"query"`
+				Parameters []struct {
+					Name  string `json:"name"`
+					Value string `json:"value"`
+				} `json:"parameters"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || !strings.Contains(body.Query, `(c.id=@id0 AND c["username"]=@pk0_0) OR`) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			params := make(map[string]string)
+			for _, param := range body.Parameters {
+				params[param.Name] = param.Value
+			}
+			found := make([]string, 0)
+			for i := 0; params["@id"+strconv.Itoa(i)] != ""; i++ {
+				if doc, ok := docs[`["`+params["@pk"+strconv.Itoa(i)+"_0"]+`"]`][params["@id"+strconv.Itoa(i)]]; ok {
+					found = append(found, doc)
+				}
+			}
+			_, _ = w.Write([]byte(`{"_count":` + strconv.Itoa(len(found)) + `,"Documents":[` + strings.Join(found, ",") + `]}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	client, err := NewRestClientWithConfig(NewConfig(server.URL, "ZGVtbw=="))
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+
+	result := client.ReadMany(ReadManyReq{DbName: "db1", CollName: "coll1", Items: []ItemIdentity{
+		{Id: "2", PartitionKeyValues: []interface{}{"user1"}},
+		{Id: "3", PartitionKeyValues: []interface{}{"user2"}},
+		{Id: "9", PartitionKeyValues: []interface{}{"user1"}},
+		{Id: "1", PartitionKeyValues: []interface{}{"user1"}},
+		{Id: "1", PartitionKeyValues: []interface{}{"user1"}},
+		{Id: "1", PartitionKeyValues: []interface{}{"user3"}},
+	}})
+	if err := result.Error(); err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	ids := make([]string, 0)
+	for _, doc := range result.Documents {
+		ids = append(ids, doc.Id()+"/"+doc["username"].(string))
+	}
+	if expected := []string{"2/user1", "3/user2", "1/user1"}; !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("%s failed: expected documents %#v but received %#v", testName, expected, ids)
+	}
+	expectedNotFound := []ItemIdentity{{Id: "9", PartitionKeyValues: []interface{}{"user1"}}, {Id: "1", PartitionKeyValues: []interface{}{"user3"}}}
+	if !reflect.DeepEqual(result.NotFound, expectedNotFound) {
+		t.Fatalf("%s failed: expected not-found %#v but received %#v", testName, expectedNotFound, result.NotFound)
+	}
+	// one query for range "1" (user1 and user2), a point read for range "0" (user3), plus reads of collection and ranges
+	if atomic.LoadInt32(&numQueries) != 1 || queriedRange != "1" || atomic.LoadInt32(&numPointReads) != 1 || result.RequestCharge != 6 {
+		t.Fatalf("%s failed: %d queries (range %s), %d point reads, request charge %f", testName, numQueries, queriedRange, numPointReads, result.RequestCharge)
+	}
+
+	if result := client.ReadMany(ReadManyReq{DbName: "db1", CollName: "coll1"}); result.Error() != nil || len(result.Documents) != 0 || len(result.NotFound) != 0 {
+		t.Fatalf("%s failed: empty request returned %#v (error %s)", testName, result, result.Error())
+	}
+	if result := client.ReadMany(ReadManyReq{DbName: "db1", CollName: "notfound", Items: []ItemIdentity{
+		{Id: "1", PartitionKeyValues: []interface{}{"user1"}}, {Id: "2", PartitionKeyValues: []interface{}{"user1"}},
+	}}); result.StatusCode != 404 || result.Error() == nil {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+	// a missing collection is an error, not a missing document
+	if result := client.ReadMany(ReadManyReq{DbName: "db1", CollName: "notfound", Items: []ItemIdentity{
+		{Id: "1", PartitionKeyValues: []interface{}{"user1"}},
+	}}); result.StatusCode != 404 || result.Error() == nil {
+		t.Fatalf("%s failed: expected status 404 but received %d", testName, result.StatusCode)
+	}
+}
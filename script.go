@@ -0,0 +1,164 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ScriptError is returned by ExecScript when a statement of the script fails. Statements before the failed one have
+// been executed successfully, statements after it have not been executed.
+//
+// @Available since v1.2.0
+type ScriptError struct {
+	Index int    // index (0-based) of the failed statement in the script
+	Line  int    // line number (starting from 1) where the failed statement starts in the script
+	Query string // text of the failed statement
+	Err   error  // error returned by the failed statement
+}
+
+// Error implements error/Error.
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("statement #%d at line %d failed: %s", e.Index, e.Line, e.Err)
+}
+
+// Unwrap returns the error returned by the failed statement.
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// scriptStatement is a statement of a script, along with its position in the script.
+type scriptStatement struct {
+	query     string
+	line, col int
+}
+
+// _splitScript splits a script into statements separated by ";". Empty statements are skipped.
+func _splitScript(script string) ([]scriptStatement, error) {
+	p := newSqlParser(script)
+	result := make([]scriptStatement, 0)
+	for p.peek().kind != tokEOF {
+		if p.isTerminator(p.pos) {
+			p.next()
+			continue
+		}
+		start := p.pos
+		if err := p.skipStatement(); err != nil {
+			return nil, err
+		}
+		token := p.tokens[start]
+		result = append(result, scriptStatement{query: p.sourceText(start, p.pos), line: token.line, col: token.col})
+	}
+	return result, p.err
+}
+
+// skipStatement advances to the end of the current statement of a script. Paths that may have wildcards (index paths
+// and the values of path options, e.g. WITH include=/*) are parsed as paths, so that a "/*" is not taken as a comment.
+func (p *sqlParser) skipStatement() error {
+	if (p.peek().is("CREATE") || p.peek().is("DROP")) &&
+		(p.peekAt(1).is("INDEX") || (p.peekAt(1).is("COMPOSITE") || p.peekAt(1).is("SPATIAL")) && p.peekAt(2).is("INDEX")) {
+		for !p.atEnd() && !p.peek().is("(") {
+			p.next()
+		}
+		if _, err := p.parseIndexPaths(); err != nil {
+			return err
+		}
+	}
+	for {
+		if err := p.skipClause("WITH"); err != nil {
+			return err
+		}
+		if !p.peek().is("WITH") {
+			return nil
+		}
+		if err := p.parseWithClause(map[string]string{}); err != nil {
+			return err
+		}
+	}
+}
+
+// SplitScript splits a script of gocosmos statements into individual statements.
+//
+// Statements are separated by ";", which are recognized outside of string literals, quoted identifiers, comments and
+// brackets (a ";" inside an option value such as WITH uk=/a;/b is not a separator). Empty statements are skipped.
+// Only the structure of the script is checked, use ParseQueryWithDefaultDb to validate each statement.
+//
+// @Available since v1.2.0
+func SplitScript(script string) ([]string, error) {
+	stmts, err := _splitScript(script)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, len(stmts))
+	for i, stmt := range stmts {
+		result[i] = stmt.query
+	}
+	return result, nil
+}
+
+// ExecScript executes a script of gocosmos statements, separated by ";", in order and returns the results of the
+// executed statements.
+//
+// All statements are executed on the same connection, and all of them are parsed before the first one is executed:
+// a script with a syntax error does not execute any statement. Syntax errors are reported as *SyntaxError with the
+// position in the script. Execution stops at the first failed statement, the error is a *ScriptError reporting the
+// failed statement, and the results of the statements executed before it are returned. Statements must be executable
+// via Exec (e.g. SELECT and LIST statements are not), and must not contain placeholders. A USE statement changes the
+// default database of the statements following it.
+//
+// Cosmos DB does not support transactions across statements, statements executed before a failure are not rolled back.
+//
+// @Available since v1.2.0
+func ExecScript(ctx context.Context, db *sql.DB, script string) ([]sql.Result, error) {
+	stmts, err := _splitScript(script)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	err = conn.Raw(func(driverConn interface{}) error {
+		c, ok := driverConn.(*Conn)
+		if !ok {
+			return fmt.Errorf("expect connection of type %T but received %T", c, driverConn)
+		}
+		defaultDb := c.defaultDb
+		for i, stmt := range stmts {
+			parsed, err := ParseQueryWithDefaultDb(c, defaultDb, stmt.query)
+			if err != nil {
+				var syntaxErr *SyntaxError
+				if errors.As(err, &syntaxErr) {
+					// report the position in the script instead of in the statement
+					if syntaxErr.Line == 1 {
+						syntaxErr.Column += stmt.col - 1
+					}
+					syntaxErr.Line += stmt.line - 1
+					return syntaxErr
+				}
+				return &ScriptError{Index: i, Line: stmt.line, Query: stmt.query, Err: err}
+			}
+			if use, ok := parsed.(*StmtUse); ok {
+				// statements following USE are executed with the new default database
+				defaultDb = use.dbName
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]sql.Result, 0, len(stmts))
+	for i, stmt := range stmts {
+		result, err := conn.ExecContext(ctx, stmt.query)
+		if err != nil {
+			return results, &ScriptError{Index: i, Line: stmt.line, Query: stmt.query, Err: err}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package gocosmos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitScript(t *testing.T) {
+	testName := "TestSplitScript"
+	testData := []struct {
+		name      string
+		script    string
+		expected  []string
+		mustError bool
+	}{
+		{name: "empty", script: " -- nothing\n;;", expected: []string{}},
+		{name: "single_no_terminator", script: "CREATE DATABASE db", expected: []string{"CREATE DATABASE db"}},
+		{
+			name:     "multiple",
+			script:   "-- schema\nCREATE DATABASE IF NOT EXISTS db WITH ru=400;\n\nCREATE COLLECTION db.table WITH pk=/id WITH uk=/a;/b;\n/* seed */ INSERT INTO db.table (id, note) VALUES (\"\\\"1\\\"\", \"\\\"a;b\\\"\");",
+			expected: []string{"CREATE DATABASE IF NOT EXISTS db WITH ru=400", "CREATE COLLECTION db.table WITH pk=/id WITH uk=/a;/b", `INSERT INTO db.table (id, note) VALUES ("\"1\"", "\"a;b\"")`},
+		},
+		{
+			name:     "json_document",
+			script:   "INSERT INTO db.table VALUE {\"id\":\"1\", \"tags\":[\"a;\", \"b\"]};DROP DATABASE db",
+			expected: []string{`INSERT INTO db.table VALUE {"id":"1", "tags":["a;", "b"]}`, "DROP DATABASE db"},
+		},
+		{
+			name:     "wildcard_paths",
+			script:   "CREATE COLLECTION db.table WITH pk=/id WITH include=/*,/tags/*;CREATE SPATIAL INDEX ON db.table (/loc/*); /* done */",
+			expected: []string{"CREATE COLLECTION db.table WITH pk=/id WITH include=/*,/tags/*", "CREATE SPATIAL INDEX ON db.table (/loc/*)"},
+		},
+		{name: "unterminated_comment", script: "CREATE DATABASE db; /* comment", mustError: true},
+		{name: "unterminated_string", script: "CREATE DATABASE db; INSERT INTO db.table (a) VALUES (\"abc)", mustError: true},
+		{name: "unbalanced_parenthesis", script: "DELETE FROM db.table WHERE (c.a > 1; DROP DATABASE db", mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			stmts, err := SplitScript(testCase.script)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if !reflect.DeepEqual(stmts, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmts)
+			}
+		})
+	}
+}
+
+func TestExecScript_parseBeforeExec(t *testing.T) {
+	testName := "TestExecScript_parseBeforeExec"
+	// no statement is executed, hence the server is never contacted
+	db, err := sql.Open("gocosmos", "AccountEndpoint=https://localhost:1/;AccountKey=dGVzdA==;Db=mydb")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	_, err = ExecScript(context.Background(), db, "CREATE COLLECTION table1 WITH pk=/id;\n  DROP DATABASE db garbage;")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("%s failed: expected SyntaxError but received %#v", testName, err)
+	}
+	if syntaxErr.Line != 2 || syntaxErr.Column != 20 {
+		t.Fatalf("%s failed: expected error at 2:20 but received %s", testName, err)
+	}
+
+	_, err = ExecScript(context.Background(), db, "CREATE COLLECTION table1 WITH pk=/id;\nCREATE COLLECTION table2")
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("%s failed: expected ScriptError but received %#v", testName, err)
+	}
+	if scriptErr.Index != 1 || scriptErr.Line != 2 || scriptErr.Query != "CREATE COLLECTION table2" {
+		t.Fatalf("%s failed: received %#v", testName, scriptErr)
+	}
+}
+
+func TestExecScript_use(t *testing.T) {
+	testName := "TestExecScript_use"
+	db, err := sql.Open("gocosmos", "AccountEndpoint=https://localhost:1/;AccountKey=dGVzdA==")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	defer func() { _ = db.Close() }()
+
+	// without default database, table2 can be resolved only after USE
+	_, err = ExecScript(context.Background(), db, "CREATE COLLECTION mydb.table1 WITH pk=/id;\nUSE mydb;\nCREATE COLLECTION table2 WITH pk=/id")
+	var scriptErr *ScriptError
+	if !errors.As(err, &scriptErr) {
+		t.Fatalf("%s failed: expected ScriptError but received %#v", testName, err)
+	}
+	// the server is not reachable: the script passes the parsing phase and fails at the first statement
+	if scriptErr.Index != 0 {
+		t.Fatalf("%s failed: received %#v", testName, scriptErr)
+	}
+
+	_, err = ExecScript(context.Background(), db, "CREATE COLLECTION table1 WITH pk=/id;\nUSE mydb")
+	if !errors.As(err, &scriptErr) || scriptErr.Index != 0 || !strings.Contains(scriptErr.Error(), "database/collection is missing") {
+		t.Fatalf("%s failed: received %#v", testName, err)
+	}
+}
@@ -0,0 +1,114 @@
+package gocosmos
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SessionContainer keeps track of the latest session tokens returned by Cosmos DB, per collection and partition key range.
+//
+// Session tokens are returned by the server in the "x-ms-session-token" response header, in the format
+// <pkrange-id>:<token>[,<pkrange-id>:<token>...]. Sending the latest token of a collection with subsequent read and
+// query requests guarantees read-your-writes at Session consistency level, even if requests are served by different replicas.
+//
+// SessionContainer is safe for concurrent use.
+//
+// @Available since v1.2.0
+type SessionContainer struct {
+	lock   sync.RWMutex
+	tokens map[string]map[string]string // collection link -> pkrange-id -> token
+}
+
+// NewSessionContainer creates a new empty SessionContainer.
+//
+// @Available since v1.2.0
+func NewSessionContainer() *SessionContainer {
+	return &SessionContainer{tokens: make(map[string]map[string]string)}
+}
+
+func _sessionCollLink(dbName, collName string) string {
+	return "dbs/" + dbName + "/colls/" + collName
+}
+
+// _sessionTokenLsn extracts the global LSN from a partition's session token.
+//
+// The token is either "<lsn>" (simple format) or "<version>#<global-lsn>[#<region-id>=<lsn>...]" (vector format).
+func _sessionTokenLsn(token string) (int64, bool) {
+	tokens := strings.Split(token, "#")
+	lsnStr := tokens[0]
+	if len(tokens) > 1 {
+		lsnStr = tokens[1]
+	}
+	lsn, err := strconv.ParseInt(lsnStr, 10, 64)
+	return lsn, err == nil
+}
+
+// Update merges the session token returned by the server into the tokens tracked for the specified collection.
+// For each partition key range, the token with the highest LSN is kept.
+func (sc *SessionContainer) Update(dbName, collName, sessionToken string) {
+	if sc == nil || strings.TrimSpace(sessionToken) == "" {
+		return
+	}
+	collLink := _sessionCollLink(dbName, collName)
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	pkrangeTokens, ok := sc.tokens[collLink]
+	if !ok {
+		pkrangeTokens = make(map[string]string)
+		sc.tokens[collLink] = pkrangeTokens
+	}
+	for _, part := range strings.Split(sessionToken, ",") {
+		tokens := strings.SplitN(strings.TrimSpace(part), ":", 2)
+		if len(tokens) != 2 || tokens[0] == "" || tokens[1] == "" {
+			continue
+		}
+		pkrangeId, token := tokens[0], tokens[1]
+		if existing, ok := pkrangeTokens[pkrangeId]; ok {
+			existingLsn, ok1 := _sessionTokenLsn(existing)
+			newLsn, ok2 := _sessionTokenLsn(token)
+			if ok1 && ok2 && newLsn < existingLsn {
+				continue
+			}
+		}
+		pkrangeTokens[pkrangeId] = token
+	}
+}
+
+// Get returns the compound session token (all tracked partition key ranges) of the specified collection,
+// or empty string if no token has been tracked.
+func (sc *SessionContainer) Get(dbName, collName string) string {
+	if sc == nil {
+		return ""
+	}
+	sc.lock.RLock()
+	defer sc.lock.RUnlock()
+	pkrangeTokens := sc.tokens[_sessionCollLink(dbName, collName)]
+	parts := make([]string, 0, len(pkrangeTokens))
+	for pkrangeId, token := range pkrangeTokens {
+		parts = append(parts, pkrangeId+":"+token)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+// Remove removes the tracked session tokens of the specified collection, e.g. when the collection is dropped.
+func (sc *SessionContainer) Remove(dbName, collName string) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	delete(sc.tokens, _sessionCollLink(dbName, collName))
+}
+
+// Clear removes all tracked session tokens.
+func (sc *SessionContainer) Clear() {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.tokens = make(map[string]map[string]string)
+}
@@ -0,0 +1,63 @@
+package gocosmos
+
+import (
+	"testing"
+)
+
+func TestSessionContainer_Update(t *testing.T) {
+	testName := "TestSessionContainer_Update"
+	testData := []struct {
+		name     string
+		tokens   []string
+		expected string
+	}{
+		{name: "empty", tokens: []string{""}, expected: ""},
+		{name: "invalid", tokens: []string{"invalid", ":-1#1", "0:"}, expected: ""},
+		{name: "simple", tokens: []string{"0:-1#12"}, expected: "0:-1#12"},
+		{name: "newer", tokens: []string{"0:-1#12", "0:-1#15"}, expected: "0:-1#15"},
+		{name: "older", tokens: []string{"0:-1#15", "0:-1#12"}, expected: "0:-1#15"},
+		{name: "simple_format", tokens: []string{"0:20", "0:10"}, expected: "0:20"},
+		{name: "multi_pkranges", tokens: []string{"1:-1#3", "0:-1#12", "1:-1#2"}, expected: "0:-1#12,1:-1#3"},
+		{name: "compound", tokens: []string{"0:-1#12, 1:-1#3", "1:-1#7#1=5"}, expected: "0:-1#12,1:-1#7#1=5"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			sc := NewSessionContainer()
+			for _, token := range testCase.tokens {
+				sc.Update("db", "coll", token)
+			}
+			if token := sc.Get("db", "coll"); token != testCase.expected {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, testCase.expected, token)
+			}
+			if token := sc.Get("db", "another_coll"); token != "" {
+				t.Fatalf("%s failed: expected empty token but received %#v", testName+"/"+testCase.name, token)
+			}
+		})
+	}
+}
+
+func TestSessionContainer_RemoveClear(t *testing.T) {
+	testName := "TestSessionContainer_RemoveClear"
+	sc := NewSessionContainer()
+	sc.Update("db", "coll1", "0:-1#1")
+	sc.Update("db", "coll2", "0:-1#2")
+	sc.Remove("db", "coll1")
+	if token := sc.Get("db", "coll1"); token != "" {
+		t.Fatalf("%s failed: expected empty token but received %#v", testName, token)
+	}
+	if token := sc.Get("db", "coll2"); token != "0:-1#2" {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, "0:-1#2", token)
+	}
+	sc.Clear()
+	if token := sc.Get("db", "coll2"); token != "" {
+		t.Fatalf("%s failed: expected empty token but received %#v", testName, token)
+	}
+
+	var nilSc *SessionContainer
+	nilSc.Update("db", "coll", "0:-1#1")
+	nilSc.Remove("db", "coll")
+	nilSc.Clear()
+	if token := nilSc.Get("db", "coll"); token != "" {
+		t.Fatalf("%s failed: expected empty token but received %#v", testName, token)
+	}
+}
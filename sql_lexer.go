@@ -0,0 +1,231 @@
+package gocosmos
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SyntaxError is returned when a statement cannot be parsed, it reports the position where the error occurs.
+//
+// @Available since v1.2.0
+type SyntaxError struct {
+	Line   int    // line number (starting from 1) where the error occurs
+	Column int    // column number (starting from 1, counted in characters) where the error occurs
+	Msg    string // description of the error
+}
+
+// Error implements interface error.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+type sqlTokenKind int
+
+const (
+	tokEOF              sqlTokenKind = iota
+	tokIdent                         // identifier or keyword, e.g. SELECT, mytable
+	tokQuotedIdent                   // quoted identifier, e.g. `my table`
+	tokString                        // string literal, e.g. "a string" or 'a string'
+	tokNumber                        // number literal, e.g. 123, 1.5 or 1e3
+	tokPlaceholder                   // positional placeholder, e.g. :1, @2 or $3
+	tokNamedPlaceholder              // named placeholder, e.g. @name
+	tokPunct                         // punctuation or operator, e.g. ( ) , . = != <=
+	tokPath                          // path with wildcards, scanned by the path parsers only, e.g. /* or /tags/*
+)
+
+// sqlToken is a token of a gocosmos statement.
+type sqlToken struct {
+	kind       sqlTokenKind
+	text       string // text of the token as in the statement
+	value      string // name of a quoted identifier (unquoted) or of a named placeholder (without the leading '@')
+	start, end int    // byte offsets of the token in the statement
+	line, col  int    // position of the token in the statement, starting from 1
+}
+
+// is returns true if the token is the keyword (case-insensitive) or the punctuation specified by text.
+func (t sqlToken) is(text string) bool {
+	switch t.kind {
+	case tokIdent:
+		return strings.EqualFold(t.text, text)
+	case tokPunct:
+		return t.text == text
+	}
+	return false
+}
+
+// describe returns a short description of the token to be used in error messages.
+func (t sqlToken) describe() string {
+	if t.kind == tokEOF {
+		return "end of statement"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+var sqlPunct2 = []string{"!=", "<>", "<=", ">=", "||", "??"}
+
+// _tokenize splits a statement into tokens. Whitespaces and comments ("-- comment" till end of line and "/* comment */")
+// are skipped. The returned list always ends with a tokEOF token: in case of error, it is placed where the error occurs,
+// after the tokens preceding the error.
+func _tokenize(query string) ([]sqlToken, error) {
+	return _tokenizeFrom(query, 0)
+}
+
+// _tokenizeFrom is like _tokenize, but starts at byte offset from of the statement.
+func _tokenizeFrom(query string, from int) ([]sqlToken, error) {
+	tokens := make([]sqlToken, 0)
+	line, lineStart := 1+strings.Count(query[:from], "\n"), strings.LastIndexByte(query[:from], '\n')+1
+	eof := func(pos int) sqlToken {
+		return sqlToken{kind: tokEOF, start: pos, end: pos, line: line, col: utf8.RuneCountInString(query[lineStart:pos]) + 1}
+	}
+	newToken := func(kind sqlTokenKind, start, end int) sqlToken {
+		return sqlToken{kind: kind, text: query[start:end], start: start, end: end,
+			line: line, col: utf8.RuneCountInString(query[lineStart:start]) + 1}
+	}
+	syntaxError := func(pos int, msg string) ([]sqlToken, error) {
+		return append(tokens, eof(pos)), &SyntaxError{Line: line, Column: utf8.RuneCountInString(query[lineStart:pos]) + 1, Msg: msg}
+	}
+	// advance moves to position end, keeping track of line numbers
+	advance := func(pos, end int) int {
+		for ; pos < end; pos++ {
+			if query[pos] == '\n' {
+				line, lineStart = line+1, pos+1
+			}
+		}
+		return end
+	}
+
+	for pos := from; pos < len(query); {
+		ch, size := utf8.DecodeRuneInString(query[pos:])
+		switch {
+		case unicode.IsSpace(ch):
+			pos = advance(pos, pos+size)
+		case strings.HasPrefix(query[pos:], "--"):
+			end := strings.IndexByte(query[pos:], '\n')
+			if end < 0 {
+				end = len(query) - pos
+			}
+			pos += end
+		case strings.HasPrefix(query[pos:], "/*"):
+			end := strings.Index(query[pos+2:], "*/")
+			if end < 0 {
+				return syntaxError(pos, "comment is not terminated")
+			}
+			pos = advance(pos, pos+2+end+2)
+		case ch == '_' || unicode.IsLetter(ch):
+			end := pos + size
+			for end < len(query) {
+				r, n := utf8.DecodeRuneInString(query[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += n
+			}
+			tokens = append(tokens, newToken(tokIdent, pos, end))
+			pos = end
+		case ch >= '0' && ch <= '9':
+			end := _scanNumber(query, pos)
+			tokens = append(tokens, newToken(tokNumber, pos, end))
+			pos = end
+		case ch == '"' || ch == '\'':
+			end := pos + 1
+			for ; end < len(query) && query[end] != byte(ch); end++ {
+				if query[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(query) {
+				return syntaxError(pos, "string literal is not terminated")
+			}
+			token := newToken(tokString, pos, end+1)
+			pos = advance(pos, end+1)
+			tokens = append(tokens, token)
+		case ch == '`':
+			var name strings.Builder
+			end := pos + 1
+			for ; end < len(query); end++ {
+				if query[end] == '`' {
+					if end+1 < len(query) && query[end+1] == '`' {
+						// a doubled backtick stands for a literal backtick
+						end++
+					} else {
+						break
+					}
+				}
+				name.WriteByte(query[end])
+			}
+			if end >= len(query) {
+				return syntaxError(pos, "quoted identifier is not terminated")
+			}
+			if name.Len() == 0 {
+				return syntaxError(pos, "quoted identifier must not be empty")
+			}
+			token := newToken(tokQuotedIdent, pos, end+1)
+			token.value = name.String()
+			pos = advance(pos, end+1)
+			tokens = append(tokens, token)
+		case (ch == ':' || ch == '@' || ch == '$') && pos+1 < len(query) && query[pos+1] >= '0' && query[pos+1] <= '9':
+			end := pos + 1
+			for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+				end++
+			}
+			tokens = append(tokens, newToken(tokPlaceholder, pos, end))
+			pos = end
+		case ch == '@' && _startsWithLetter(query[pos+1:]):
+			end := pos + 1
+			for end < len(query) {
+				r, n := utf8.DecodeRuneInString(query[end:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				end += n
+			}
+			token := newToken(tokNamedPlaceholder, pos, end)
+			token.value = query[pos+1 : end]
+			tokens = append(tokens, token)
+			pos = end
+		default:
+			end := pos + size
+			for _, p := range sqlPunct2 {
+				if strings.HasPrefix(query[pos:], p) {
+					end = pos + len(p)
+					break
+				}
+			}
+			tokens = append(tokens, newToken(tokPunct, pos, end))
+			pos = end
+		}
+	}
+	return append(tokens, eof(len(query))), nil
+}
+
+// _startsWithLetter reports whether s starts with a (possibly multi-byte) letter.
+func _startsWithLetter(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsLetter(r)
+}
+
+// _scanNumber returns the end position of the number literal starting at pos, e.g. 123, 1.5, 1e3 or 2.5E-3.
+func _scanNumber(query string, pos int) int {
+	digits := func(pos int) int {
+		for pos < len(query) && query[pos] >= '0' && query[pos] <= '9' {
+			pos++
+		}
+		return pos
+	}
+	end := digits(pos)
+	if end+1 < len(query) && query[end] == '.' && query[end+1] >= '0' && query[end+1] <= '9' {
+		end = digits(end + 1)
+	}
+	if end < len(query) && (query[end] == 'e' || query[end] == 'E') {
+		exp := end + 1
+		if exp < len(query) && (query[exp] == '+' || query[exp] == '-') {
+			exp++
+		}
+		if exp < len(query) && query[exp] >= '0' && query[exp] <= '9' {
+			end = digits(exp)
+		}
+	}
+	return end
+}
@@ -0,0 +1,63 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize_comments(t *testing.T) {
+	testName := "TestTokenize_comments"
+	testData := []struct {
+		name     string
+		sql      string
+		expected []string
+	}{
+		{name: "trailing_comment", sql: "WHERE c.a=1/* note */", expected: []string{"WHERE", "c", ".", "a", "=", "1", ""}},
+		{name: "comment_after_path", sql: "WITH pk=/id/* note */", expected: []string{"WITH", "pk", "=", "/", "id", ""}},
+		{name: "line_comment", sql: "c.a > 1 -- note\nAND c.b < 2", expected: []string{"c", ".", "a", ">", "1", "AND", "c", ".", "b", "<", "2", ""}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			tokens, err := _tokenize(testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			texts := make([]string, len(tokens))
+			for i, token := range tokens {
+				texts[i] = token.text
+			}
+			if !reflect.DeepEqual(texts, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, texts)
+			}
+		})
+	}
+}
+
+func TestTokenize_unterminatedComment(t *testing.T) {
+	testName := "TestTokenize_unterminatedComment"
+	tokens, err := _tokenize("SELECT * FROM c\nWHERE c.a=1/* note")
+	if err == nil {
+		t.Fatalf("%s failed: expected error", testName)
+	}
+	// tokens preceding the error are returned, followed by tokEOF at the position of the error
+	if n := len(tokens); n != 11 || tokens[n-1].kind != tokEOF || tokens[n-1].line != 2 || tokens[n-1].col != 12 {
+		t.Fatalf("%s failed: received %#v", testName, tokens)
+	}
+}
+
+func TestTokenize_namedPlaceholders(t *testing.T) {
+	testName := "TestTokenize_namedPlaceholders"
+	tokens, err := _tokenize("c.a=@nämе AND c.b=@über_1, @x2")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	names := make([]string, 0)
+	for _, token := range tokens {
+		if token.kind == tokNamedPlaceholder {
+			names = append(names, token.value)
+		}
+	}
+	if expected := []string{"nämе", "über_1", "x2"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName, expected, names)
+	}
+}
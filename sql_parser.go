@@ -0,0 +1,1369 @@
+package gocosmos
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// sqlParser is a recursive-descent parser of gocosmos statements.
+//
+// The parser builds the statement nodes (StmtCreateDatabase, StmtInsert, StmtSelect, etc.) which form the AST of
+// gocosmos statements: names, value lists, SET clauses and point-form WHERE clauses are parsed into their fields,
+// whereas Cosmos DB SQL parts (SELECT queries and WHERE predicates) are kept as text, and sent as-is to the server.
+type sqlParser struct {
+	query  string
+	tokens []sqlToken
+	pos    int
+	err    error // lexer error, reported once the parser reaches the last (tokEOF) token
+}
+
+// newSqlParser creates a parser of the statement. A lexer error is not returned right away: the error may occur in a
+// wildcard path such as /*, which is re-scanned by the path parsers (see scanPath).
+func newSqlParser(query string) *sqlParser {
+	tokens, err := _tokenize(query)
+	return &sqlParser{query: query, tokens: tokens, err: err}
+}
+
+// sqlFieldValue is a "<field>=<value>" condition of a point-form WHERE clause (e.g. WHERE id=:1 AND pk=:2).
+type sqlFieldValue struct {
+	field string
+	value interface{}
+}
+
+func (p *sqlParser) peek() sqlToken {
+	return p.tokens[p.pos]
+}
+
+func (p *sqlParser) peekAt(offset int) sqlToken {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *sqlParser) next() sqlToken {
+	token := p.tokens[p.pos]
+	if token.kind != tokEOF {
+		p.pos++
+	}
+	return token
+}
+
+// accept consumes the next token if it is the keyword/punctuation specified by text.
+func (p *sqlParser) accept(text string) bool {
+	if p.peek().is(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the next token, which must be the keyword/punctuation specified by text.
+func (p *sqlParser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(strconv.Quote(text))
+	}
+	return nil
+}
+
+// acceptKeywords consumes the keyword sequence (e.g. IF NOT EXISTS) if the next token is its first keyword.
+func (p *sqlParser) acceptKeywords(keywords ...string) (bool, error) {
+	if !p.accept(keywords[0]) {
+		return false, nil
+	}
+	for _, keyword := range keywords[1:] {
+		if err := p.expect(keyword); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *sqlParser) errorAt(token sqlToken, format string, args ...interface{}) error {
+	if token.kind == tokEOF && p.err != nil {
+		return p.err
+	}
+	return &SyntaxError{Line: token.line, Column: token.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *sqlParser) unexpected(expected string) error {
+	return p.errorAt(p.peek(), "expect %s but found %s", expected, p.peek().describe())
+}
+
+// atEnd returns true if the current statement has no more tokens.
+func (p *sqlParser) atEnd() bool {
+	return p.peek().kind == tokEOF || p.isTerminator(p.pos)
+}
+
+// isTerminator returns true if the token at index i is a ";" terminating the statement. A ";" directly between two
+// paths is not a terminator, it is part of a value (e.g. WITH uk=/a;/b).
+func (p *sqlParser) isTerminator(i int) bool {
+	return p.tokens[i].is(";") && !(p.contiguous(i) && p.tokens[i+1].is("/") && p.contiguous(i+1))
+}
+
+// expectEnd consumes the optional ";" terminating the statement, there must be no more tokens after it.
+func (p *sqlParser) expectEnd() error {
+	if p.isTerminator(p.pos) {
+		p.next()
+	}
+	if p.peek().kind != tokEOF {
+		return p.unexpected("end of statement")
+	}
+	return p.err
+}
+
+// contiguous returns true if there is no whitespace (nor comment) between the token at index i and its previous token.
+func (p *sqlParser) contiguous(i int) bool {
+	return i > 0 && p.tokens[i-1].end == p.tokens[i].start
+}
+
+// sourceText returns the text of tokens [from, to) exactly as in the statement.
+func (p *sqlParser) sourceText(from, to int) string {
+	if from >= to {
+		return ""
+	}
+	return p.query[p.tokens[from].start:p.tokens[to-1].end]
+}
+
+// rawText returns the text of tokens [from, to), comments are replaced by a single space.
+// Tokens whose index is in the replace map are replaced by the associated text.
+func (p *sqlParser) rawText(from, to int, replace map[int]string) string {
+	var sb strings.Builder
+	for i := from; i < to; i++ {
+		if i > from {
+			gap := p.query[p.tokens[i-1].end:p.tokens[i].start]
+			if strings.Contains(gap, "--") || strings.Contains(gap, "/*") {
+				gap = " "
+			}
+			sb.WriteString(gap)
+		}
+		if text, ok := replace[i]; ok {
+			sb.WriteString(text)
+		} else {
+			sb.WriteString(p.tokens[i].text)
+		}
+	}
+	return sb.String()
+}
+
+// pathStart returns the byte offset of the path following the current token, and false if no path follows it.
+func (p *sqlParser) pathStart() (int, bool) {
+	offset := p.tokens[p.pos-1].end
+	for offset < len(p.query) && unicode.IsSpace(rune(p.query[offset])) {
+		offset++
+	}
+	if strings.HasPrefix(p.query[offset:], "/") {
+		return offset, true
+	}
+	// the path may follow a comment
+	return p.peek().start, p.peek().is("/")
+}
+
+// scanPath consumes the path starting at byte offset and returns it. A path has no whitespace and ends at a ",", ")"
+// or ";", e.g. /name/?, /tags/[]/*, /* or /"_etag"/?: a "/*" in a path is a wildcard, not the start of a comment.
+// Tokens following the path are re-scanned.
+func (p *sqlParser) scanPath(offset int) string {
+	lineStart := strings.LastIndexByte(p.query[:offset], '\n') + 1
+	end := offset
+	for end < len(p.query) {
+		ch, size := utf8.DecodeRuneInString(p.query[end:])
+		if unicode.IsSpace(ch) || ch == ',' || ch == ')' || ch == ';' {
+			break
+		}
+		if i := strings.IndexByte(p.query[end+1:], '"'); ch == '"' && i >= 0 {
+			size = i + 2
+		}
+		end += size
+	}
+	path := sqlToken{kind: tokPath, text: p.query[offset:end], start: offset, end: end,
+		line: 1 + strings.Count(p.query[:offset], "\n"), col: utf8.RuneCountInString(p.query[lineStart:offset]) + 1}
+	tokens, err := _tokenizeFrom(p.query, end)
+	p.tokens, p.err = append(append(p.tokens[:p.pos:p.pos], path), tokens...), err
+	return p.next().text
+}
+
+// parseName parses a name (e.g. database, collection or field name): either a quoted identifier, or a sequence of
+// letters, digits, underscores and dashes (e.g. my-db_1).
+func (p *sqlParser) parseName(what string) (string, error) {
+	token := p.peek()
+	if token.kind == tokQuotedIdent {
+		p.next()
+		return token.value, nil
+	}
+	if token.kind != tokIdent && token.kind != tokNumber {
+		return "", p.unexpected(what)
+	}
+	start := p.pos
+	for p.next(); p.contiguous(p.pos); p.next() {
+		if token := p.peek(); token.kind != tokIdent && token.kind != tokNumber && !token.is("-") {
+			break
+		}
+	}
+	return p.sourceText(start, p.pos), nil
+}
+
+// parseQualifiedName parses "[<db-name>.]<collection-name>".
+func (p *sqlParser) parseQualifiedName() (dbName, collName string, err error) {
+	if collName, err = p.parseName("collection name"); err != nil {
+		return "", "", err
+	}
+	if p.accept(".") {
+		dbName = collName
+		collName, err = p.parseName("collection name")
+	}
+	return dbName, collName, err
+}
+
+// skipClause advances to the end of the current clause: the first token, outside of parentheses/brackets/braces, that
+// ends the statement or is one of the stop keywords (a stop keyword must be preceded by whitespace).
+func (p *sqlParser) skipClause(stopKeywords ...string) error {
+	closers := make([]string, 0)
+	for {
+		token := p.peek()
+		if token.kind == tokEOF {
+			if len(closers) > 0 {
+				return p.unexpected(strconv.Quote(closers[len(closers)-1]))
+			}
+			return nil
+		}
+		if len(closers) == 0 {
+			if p.isTerminator(p.pos) {
+				return nil
+			}
+			for _, keyword := range stopKeywords {
+				if token.kind == tokIdent && token.is(keyword) && !p.contiguous(p.pos) {
+					return nil
+				}
+			}
+		}
+		var err error
+		if closers, err = p.trackBrackets(closers, token); err != nil {
+			return err
+		}
+		p.next()
+	}
+}
+
+// skipBlock advances past the block (parentheses, brackets or braces) opened by the current token.
+func (p *sqlParser) skipBlock() error {
+	closers := make([]string, 0)
+	for {
+		token := p.peek()
+		if token.kind == tokEOF {
+			return p.unexpected(strconv.Quote(closers[len(closers)-1]))
+		}
+		var err error
+		if closers, err = p.trackBrackets(closers, token); err != nil {
+			return err
+		}
+		p.next()
+		if len(closers) == 0 {
+			return nil
+		}
+	}
+}
+
+// trackBrackets updates the stack of expected closing brackets with the supplied token.
+func (p *sqlParser) trackBrackets(closers []string, token sqlToken) ([]string, error) {
+	if token.kind != tokPunct {
+		return closers, nil
+	}
+	switch token.text {
+	case "(":
+		closers = append(closers, ")")
+	case "[":
+		closers = append(closers, "]")
+	case "{":
+		closers = append(closers, "}")
+	case ")", "]", "}":
+		if len(closers) == 0 || closers[len(closers)-1] != token.text {
+			return nil, p.errorAt(token, "unexpected %s", token.describe())
+		}
+		closers = closers[:len(closers)-1]
+	}
+	return closers, nil
+}
+
+// parseWithClause parses the "WITH <option>[=<value>] [[,] WITH <option>[=<value>]...]" clause, shared by all statements.
+// Option names are case-insensitive and stored in upper case. An option value spans till the next WITH option (or the
+// end of the statement). A quoted identifier value is stored unquoted, other values are stored as-is.
+func (p *sqlParser) parseWithClause(opts map[string]string) error {
+	for p.accept("WITH") {
+		keyToken := p.peek()
+		key, err := p.parseName("option name")
+		if err != nil {
+			return err
+		}
+		key = strings.ToUpper(key)
+		if _, ok := opts[key]; ok {
+			return p.errorAt(keyToken, "option %s is specified more than once", key)
+		}
+		opts[key] = ""
+		if p.accept("=") {
+			if offset, ok := p.pathStart(); ok && sqlPathOptions[key] {
+				if opts[key], err = p.parsePathList(offset); err != nil {
+					return err
+				}
+				if p.peek().is(",") && p.peekAt(1).is("WITH") {
+					p.next()
+				}
+				continue
+			}
+			start := p.pos
+			if err := p.skipClause("WITH", "RETURNING"); err != nil {
+				return err
+			}
+			end := p.pos
+			if end > start && p.tokens[end-1].is(",") && p.peek().is("WITH") {
+				// ", WITH" separates options
+				end--
+			}
+			if end == start {
+				return p.errorAt(p.tokens[end], "missing value of option %s", key)
+			}
+			if end-start == 1 && p.tokens[start].kind == tokQuotedIdent {
+				opts[key] = p.tokens[start].value
+			} else {
+				opts[key] = p.rawText(start, end, nil)
+			}
+		}
+		if p.peek().is(",") && p.peekAt(1).is("WITH") {
+			p.next()
+		}
+	}
+	return nil
+}
+
+// sqlPathOptions are the WITH options whose value is a comma-separated list of paths that may have wildcards.
+var sqlPathOptions = map[string]bool{"INCLUDE": true, "EXCLUDE": true, "SPATIAL": true}
+
+// parsePathList parses the comma-separated list of paths starting at byte offset (e.g. /*,/tags/*) and returns it as
+// in the statement.
+func (p *sqlParser) parsePathList(offset int) (string, error) {
+	var sb strings.Builder
+	for {
+		sb.WriteString(p.scanPath(offset))
+		if !p.peek().is(",") || p.peekAt(1).is("WITH") {
+			return sb.String(), nil
+		}
+		p.next()
+		var ok bool
+		if offset, ok = p.pathStart(); !ok {
+			return "", p.unexpected("path")
+		}
+		sb.WriteString(",")
+	}
+}
+
+// parseReturning parses the "RETURNING *|<field>[,<field>...]" clause of INSERT/UPSERT/UPDATE/DELETE statements.
+func (p *sqlParser) parseReturning() ([]string, error) {
+	if err := p.expect("RETURNING"); err != nil {
+		return nil, err
+	}
+	if p.accept("*") {
+		return []string{"*"}, nil
+	}
+	returning := make([]string, 0)
+	for {
+		path, err := p.parseName("field name")
+		if err != nil {
+			return nil, err
+		}
+		for p.accept(".") {
+			name, err := p.parseName("field name")
+			if err != nil {
+				return nil, err
+			}
+			path += "." + name
+		}
+		returning = append(returning, path)
+		if !p.accept(",") {
+			return returning, nil
+		}
+	}
+}
+
+// parseTail parses the clauses ending a statement: WITH options and, if allowed, the RETURNING clause (in any order).
+func (p *sqlParser) parseTail(opts map[string]string, allowReturning bool) ([]string, error) {
+	var returning []string
+	for !p.atEnd() {
+		var err error
+		switch {
+		case p.peek().is("WITH"):
+			err = p.parseWithClause(opts)
+		case allowReturning && returning == nil && p.peek().is("RETURNING"):
+			returning, err = p.parseReturning()
+		default:
+			return nil, p.unexpected("WITH clause or end of statement")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return returning, nil
+}
+
+// parseValue parses a value: a placeholder (e.g. :1, @2, $3 or @name), null, a boolean, a number, a JSON value
+// enclosed in double quotes (e.g. "\"a string\"" or "{\"key\":\"value\"}"), or an unquoted string (e.g. abc).
+func (p *sqlParser) parseValue() (interface{}, error) {
+	token := p.peek()
+	switch token.kind {
+	case tokPlaceholder:
+		index, _ := strconv.Atoi(token.text[1:])
+		if index < 1 {
+			return nil, p.errorAt(token, "invalid placeholder %s, positional placeholders start at 1", token.text)
+		}
+		p.next()
+		return placeholder{index}, nil
+	case tokNamedPlaceholder:
+		p.next()
+		return namedPlaceholder{token.value}, nil
+	case tokString:
+		if token.text[0] != '"' {
+			return nil, p.errorAt(token, "invalid value %s, expect a JSON value enclosed in double quotes", token.text)
+		}
+		p.next()
+		var data interface{}
+		unquoted, err := strconv.Unquote(token.text)
+		if err != nil {
+			return nil, p.errorAt(token, "invalid string literal %s", token.text)
+		}
+		if err = json.Unmarshal([]byte(unquoted), &data); err != nil {
+			return nil, p.errorAt(token, "invalid value %s, expect a JSON value enclosed in double quotes", token.text)
+		}
+		return data, nil
+	}
+
+	// null, boolean, number or unquoted string: a sequence of letters, digits and . / : \ - characters
+	start := p.pos
+	for p.pos == start || p.contiguous(p.pos) {
+		token := p.peek()
+		if token.kind != tokIdent && token.kind != tokNumber && !token.is(".") && !token.is("/") &&
+			!token.is(":") && !token.is("\\") && !token.is("-") && !(p.pos == start && token.is("+")) {
+			break
+		}
+		p.next()
+	}
+	if p.pos == start {
+		return nil, p.unexpected("a value")
+	}
+	text := p.sourceText(start, p.pos)
+	switch strings.ToLower(text) {
+	case "null":
+		return nil, nil
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if strings.ContainsRune("0123456789+-.", rune(text[0])) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(text), &data); err != nil {
+			return nil, p.errorAt(token, "invalid number %s", text)
+		}
+		return data, nil
+	}
+	return text, nil
+}
+
+// _parseValueText parses the whole text as a value, see sqlParser.parseValue.
+func _parseValueText(text string) (interface{}, error) {
+	p := newSqlParser(text)
+	value, err := p.parseValue()
+	if err == nil && p.peek().kind != tokEOF {
+		err = p.unexpected("end of value")
+	}
+	if err == nil {
+		err = p.err
+	}
+	return value, err
+}
+
+// parseWhere parses the WHERE clause of UPDATE/DELETE statements. The clause is either in the point form
+// "<field>=<value> [AND <field>=<value>...]" with one of the fields being id (returned as a list of conditions), or a
+// Cosmos DB SQL predicate (the returned list is nil). The text of the clause is also returned.
+func (p *sqlParser) parseWhere() ([]sqlFieldValue, string, error) {
+	if err := p.expect("WHERE"); err != nil {
+		return nil, "", err
+	}
+	start := p.pos
+	if conds, ok, err := p.parsePointConditions(); ok {
+		if err != nil {
+			return nil, "", err
+		}
+		return conds, p.rawText(start, p.pos, nil), nil
+	}
+	p.pos = start
+	if err := p.skipClause("WITH", "RETURNING"); err != nil {
+		return nil, "", err
+	}
+	if p.pos == start {
+		return nil, "", p.unexpected("WHERE condition")
+	}
+	return nil, p.rawText(start, p.pos, nil), nil
+}
+
+// parsePointConditions tries to parse the point form of a WHERE clause. ok is false if the clause is not in the point
+// form, in which case the position of the parser is left undefined; otherwise err reports an invalid value.
+func (p *sqlParser) parsePointConditions() (conds []sqlFieldValue, ok bool, err error) {
+	hasId := false
+	for {
+		token := p.peek()
+		if token.kind != tokIdent && token.kind != tokNumber {
+			return nil, false, nil
+		}
+		field, e := p.parseName("field name")
+		if e != nil || !p.accept("=") {
+			return nil, false, nil
+		}
+		hasId = hasId || strings.ToLower(field) == "id"
+		value, e := p.parseValue()
+		if e != nil {
+			// a bad value is reported only if the rest of the clause is in the point form
+			if err == nil {
+				err = e
+			}
+			if p.skipClause("AND", "WITH", "RETURNING") != nil {
+				return nil, false, nil
+			}
+		}
+		conds = append(conds, sqlFieldValue{field: field, value: value})
+		if !p.accept("AND") {
+			break
+		}
+	}
+	if next := p.peek(); !p.atEnd() && !next.is("WITH") && !next.is("RETURNING") {
+		if next.kind == tokPunct || next.kind == tokIdent && _isPredicateKeyword(next.text) {
+			return nil, false, nil
+		}
+		// an operand cannot follow a condition, neither in the point form nor in a predicate
+		return nil, true, p.unexpected("AND, WITH clause or end of statement")
+	}
+	if !hasId {
+		return nil, false, nil
+	}
+	return conds, true, err
+}
+
+// _isPredicateKeyword reports whether word is an operator keyword that may follow an operand in a predicate.
+func _isPredicateKeyword(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT", "IN", "BETWEEN", "LIKE":
+		return true
+	}
+	return false
+}
+
+var reUpdatePathName = regexp.MustCompile(`^[\w\-]+$`)
+
+// parseFieldPath parses a field path, e.g. a, a.b, tags[0] or a.b[2].c.
+func (p *sqlParser) parseFieldPath() (string, error) {
+	var sb strings.Builder
+	for {
+		token := p.peek()
+		name, err := p.parseName("field name")
+		if err != nil {
+			return "", err
+		}
+		if !reUpdatePathName.MatchString(name) {
+			return "", p.errorAt(token, "invalid field name %s, only letters, digits, underscores and dashes are allowed in a path", token.text)
+		}
+		sb.WriteString(name)
+		for p.accept("[") {
+			token := p.peek()
+			if _, err := strconv.Atoi(token.text); token.kind != tokNumber || err != nil {
+				return "", p.unexpected("array index")
+			}
+			p.next()
+			if err := p.expect("]"); err != nil {
+				return "", err
+			}
+			sb.WriteString("[" + token.text + "]")
+		}
+		if !p.accept(".") {
+			return sb.String(), nil
+		}
+		sb.WriteString(".")
+	}
+}
+
+// acceptText consumes the contiguous tokens whose text is exactly the supplied text.
+func (p *sqlParser) acceptText(text string) bool {
+	start := p.pos
+	var sb strings.Builder
+	for sb.Len() < len(text) && (p.pos == start || p.contiguous(p.pos)) && p.peek().kind != tokEOF {
+		sb.WriteString(p.next().text)
+	}
+	if sb.String() != text {
+		p.pos = start
+		return false
+	}
+	return true
+}
+
+func (p *sqlParser) isUpdateKeyword() bool {
+	token := p.peek()
+	return (token.is("SET") || token.is("UNSET") || token.is("REMOVE")) && !p.peekAt(1).is("=")
+}
+
+// parseUpdateClause parses the "SET <assignment>[,...] [UNSET|REMOVE <path>[,...]]" clause of UPDATE statements.
+func (p *sqlParser) parseUpdateClause() (fields []string, values []interface{}, err error) {
+	fields, values = make([]string, 0), make([]interface{}, 0)
+	if !p.isUpdateKeyword() {
+		return nil, nil, p.unexpected("SET clause")
+	}
+	unset := false
+	for len(fields) == 0 || p.accept(",") || p.isUpdateKeyword() {
+		// SET, UNSET and REMOVE keywords switch between assignments and removals
+		if p.isUpdateKeyword() {
+			unset = !p.next().is("SET")
+		}
+		field, err := p.parseFieldPath()
+		if err != nil {
+			return nil, nil, err
+		}
+		fields = append(fields, field)
+		if unset {
+			values = append(values, updateUnset{})
+			continue
+		}
+		if err = p.expect("="); err != nil {
+			return nil, nil, err
+		}
+		value, err := p.parseUpdateValue(field)
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, value)
+	}
+	return fields, values, nil
+}
+
+// parseUpdateValue parses the right side of "<path> = ...": ARRAY_APPEND(<path>, <value>), <path> +|- <operand> or a value.
+func (p *sqlParser) parseUpdateValue(field string) (interface{}, error) {
+	if p.peek().is("ARRAY_APPEND") && p.peekAt(1).is("(") {
+		p.pos += 2
+		token := p.peek()
+		path, err := p.parseFieldPath()
+		if err != nil {
+			return nil, err
+		}
+		if path != field {
+			return nil, p.errorAt(token, "ARRAY_APPEND must append to the assigned field %s", field)
+		}
+		if err = p.expect(","); err != nil {
+			return nil, err
+		}
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		return updateAppend{value}, p.expect(")")
+	}
+
+	start := p.pos
+	if p.acceptText(field) && (p.peek().is("+") || p.peek().is("-")) {
+		negative := p.next().is("-")
+		operand, err := p.parseValue()
+		if _, ok := operand.(string); err == nil && !ok {
+			return updateIncrement{operand: operand, negative: negative}, nil
+		}
+		// not an increment, e.g. an unquoted string value
+	}
+	p.pos = start
+	return p.parseValue()
+}
+
+/*----------------------------------------------------------------------*/
+
+// newStmt creates the base statement, its text spans from token start to the current position.
+func (p *sqlParser) newStmt(c *Conn, start int, opts map[string]string) *Stmt {
+	return &Stmt{query: p.sourceText(start, p.pos), conn: c, withOpts: opts}
+}
+
+// parseStatement parses the statement starting at the current position, the ";" terminating the statement (if any)
+// is not consumed.
+func (p *sqlParser) parseStatement(c *Conn, defaultDb string) (driver.Stmt, error) {
+	start := p.pos
+	token := p.next()
+	switch {
+	case token.is("CREATE"), token.is("ALTER"), token.is("DROP"):
+		switch object := p.next(); {
+		case object.is("DATABASE"):
+			return p.parseDatabaseStmt(c, token, start)
+		case object.is("COLLECTION"), object.is("TABLE"):
+			return p.parseCollectionStmt(c, defaultDb, token, start)
+		case !token.is("ALTER") && (object.is("INDEX") || ((object.is("COMPOSITE") || object.is("SPATIAL")) && p.peek().is("INDEX"))):
+			return p.parseIndexStmt(c, defaultDb, token, object, start)
+		}
+		p.pos--
+		if token.is("ALTER") {
+			return nil, p.unexpected("DATABASE, COLLECTION or TABLE")
+		}
+		return nil, p.unexpected("DATABASE, COLLECTION, TABLE or INDEX")
+	case token.is("SHOW"):
+		return p.parseShow(c, defaultDb, start)
+	case token.is("DESCRIBE"):
+		if !p.accept("COLLECTION") && !p.accept("TABLE") {
+			return nil, p.unexpected("COLLECTION or TABLE")
+		}
+		dbName, collName, err := p.parseQualifiedName()
+		if err != nil {
+			return nil, err
+		}
+		if dbName == "" {
+			dbName = defaultDb
+		}
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtDescribeCollection{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName}
+		return stmt, stmt.validate()
+	case token.is("LIST"):
+		return p.parseList(c, defaultDb, start)
+	case token.is("USE"):
+		dbName, err := p.parseName("database name")
+		if err != nil {
+			return nil, err
+		}
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtUse{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName}
+		return stmt, stmt.validate()
+	case token.is("INSERT"), token.is("UPSERT"):
+		return p.parseInsert(c, defaultDb, token.is("UPSERT"), start)
+	case token.is("SELECT"):
+		p.pos = start
+		stmt, _, err := p.parseSelect(c, defaultDb, false)
+		return stmt, err
+	case token.is("EXPLAIN"):
+		analyze := p.accept("ANALYZE")
+		if !p.peek().is("SELECT") {
+			return nil, p.unexpected("SELECT")
+		}
+		stmt, _, err := p.parseSelect(c, defaultDb, false)
+		if err != nil {
+			return nil, err
+		}
+		if stmt.readManyItems != nil {
+			return nil, p.errorAt(token, "EXPLAIN is not supported for read-many queries, documents are fetched with point reads")
+		}
+		explain := &StmtExplain{StmtSelect: stmt, analyze: analyze}
+		explain.query = p.sourceText(start, p.pos)
+		return explain, nil
+	case token.is("UPDATE"):
+		return p.parseUpdate(c, defaultDb, start)
+	case token.is("DELETE"):
+		return p.parseDelete(c, defaultDb, start)
+	case token.kind == tokEOF:
+		return nil, p.errorAt(token, "empty statement")
+	}
+	return nil, p.errorAt(token, "unknown statement %s", token.describe())
+}
+
+func (p *sqlParser) parseDatabaseStmt(c *Conn, verb sqlToken, start int) (driver.Stmt, error) {
+	var ifExists bool
+	var err error
+	switch {
+	case verb.is("CREATE"):
+		ifExists, err = p.acceptKeywords("IF", "NOT", "EXISTS")
+	case verb.is("DROP"):
+		ifExists, err = p.acceptKeywords("IF", "EXISTS")
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbName, err := p.parseName("database name")
+	if err != nil {
+		return nil, err
+	}
+	opts := make(map[string]string)
+	if verb.is("DROP") {
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtDropDatabase{Stmt: p.newStmt(c, start, opts), dbName: dbName, ifExists: ifExists}
+		return stmt, stmt.validate()
+	}
+	if _, err := p.parseTail(opts, false); err != nil {
+		return nil, err
+	}
+	if verb.is("CREATE") {
+		stmt := &StmtCreateDatabase{Stmt: p.newStmt(c, start, opts), dbName: dbName, ifNotExists: ifExists}
+		if err := stmt.parse(); err != nil {
+			return nil, err
+		}
+		return stmt, stmt.validate()
+	}
+	stmt := &StmtAlterDatabase{Stmt: p.newStmt(c, start, opts), dbName: dbName}
+	if err := stmt.parse(); err != nil {
+		return nil, err
+	}
+	return stmt, stmt.validate()
+}
+
+func (p *sqlParser) parseCollectionStmt(c *Conn, defaultDb string, verb sqlToken, start int) (driver.Stmt, error) {
+	var ifExists bool
+	var err error
+	switch {
+	case verb.is("CREATE"):
+		ifExists, err = p.acceptKeywords("IF", "NOT", "EXISTS")
+	case verb.is("DROP"):
+		ifExists, err = p.acceptKeywords("IF", "EXISTS")
+	}
+	if err != nil {
+		return nil, err
+	}
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	opts := make(map[string]string)
+	if verb.is("DROP") {
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtDropCollection{Stmt: p.newStmt(c, start, opts), dbName: dbName, collName: collName, ifExists: ifExists}
+		return stmt, stmt.validate()
+	}
+	if _, err := p.parseTail(opts, false); err != nil {
+		return nil, err
+	}
+	if verb.is("CREATE") {
+		stmt := &StmtCreateCollection{Stmt: p.newStmt(c, start, opts), dbName: dbName, collName: collName, ifNotExists: ifExists}
+		if err := stmt.parse(); err != nil {
+			return nil, err
+		}
+		return stmt, stmt.validate()
+	}
+	stmt := &StmtAlterCollection{Stmt: p.newStmt(c, start, opts), dbName: dbName, collName: collName}
+	if err := stmt.parse(); err != nil {
+		return nil, err
+	}
+	return stmt, stmt.validate()
+}
+
+// parseIndexStmt parses CREATE/DROP [COMPOSITE|SPATIAL] INDEX statements, the object token is INDEX, COMPOSITE or SPATIAL.
+func (p *sqlParser) parseIndexStmt(c *Conn, defaultDb string, verb, object sqlToken, start int) (driver.Stmt, error) {
+	kind := ""
+	if !object.is("INDEX") {
+		kind = strings.ToUpper(object.text)
+		p.next()
+	}
+	var ifExists bool
+	var err error
+	if verb.is("CREATE") {
+		ifExists, err = p.acceptKeywords("IF", "NOT", "EXISTS")
+	} else {
+		ifExists, err = p.acceptKeywords("IF", "EXISTS")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect("ON"); err != nil {
+		return nil, err
+	}
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	specs, err := p.parseIndexPaths()
+	if err != nil {
+		return nil, err
+	}
+	if !p.atEnd() {
+		return nil, p.unexpected("end of statement")
+	}
+	paths, err := _parseIndexPaths(specs, kind == "COMPOSITE")
+	if err != nil {
+		return nil, err
+	}
+	if verb.is("CREATE") {
+		stmt := &StmtCreateIndex{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName,
+			kind: kind, paths: paths, ifNotExists: ifExists}
+		return stmt, stmt.validate()
+	}
+	stmt := &StmtDropIndex{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName,
+		kind: kind, paths: paths, ifExists: ifExists}
+	return stmt, stmt.validate()
+}
+
+// parseIndexPaths parses the list of index paths "(<path1> [ASC|DESC], <path2> [ASC|DESC],...)". A path starts with a
+// "/" and has no whitespace, e.g. /name/?, /tags/[]/* or /"_etag"/?.
+func (p *sqlParser) parseIndexPaths() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	specs := make([]string, 0)
+	for {
+		offset, ok := p.pathStart()
+		if !ok {
+			return nil, p.unexpected("index path")
+		}
+		spec := p.scanPath(offset)
+		if p.peek().is("ASC") || p.peek().is("DESC") {
+			spec += " " + p.next().text
+		}
+		specs = append(specs, spec)
+		if !p.accept(",") {
+			break
+		}
+	}
+	return specs, p.expect(")")
+}
+
+// parseShow parses SHOW statements: SHOW INDEX PROGRESS, SHOW THROUGHPUT FOR and SHOW PARTITIONS FOR.
+func (p *sqlParser) parseShow(c *Conn, defaultDb string, start int) (driver.Stmt, error) {
+	var what string
+	switch {
+	case p.accept("THROUGHPUT"):
+		what = "THROUGHPUT"
+		if err := p.expect("FOR"); err != nil {
+			return nil, err
+		}
+	case p.accept("PARTITIONS"):
+		what = "PARTITIONS"
+		if err := p.expect("FOR"); err != nil {
+			return nil, err
+		}
+	default:
+		if ok, err := p.acceptKeywords("INDEX", "PROGRESS"); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, p.unexpected("INDEX PROGRESS, THROUGHPUT or PARTITIONS")
+		}
+	}
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if !p.atEnd() {
+		return nil, p.unexpected("end of statement")
+	}
+	if what == "THROUGHPUT" {
+		// SHOW THROUGHPUT FOR <db-name> targets a database, the database name is never implied
+		if dbName == "" {
+			dbName, collName = collName, ""
+		}
+		stmt := &StmtShowThroughput{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName}
+		return stmt, stmt.validate()
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	if what == "PARTITIONS" {
+		stmt := &StmtShowPartitions{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName}
+		return stmt, stmt.validate()
+	}
+	stmt := &StmtShowIndexProgress{Stmt: p.newStmt(c, start, map[string]string{}), dbName: dbName, collName: collName}
+	return stmt, stmt.validate()
+}
+
+func (p *sqlParser) parseList(c *Conn, defaultDb string, start int) (driver.Stmt, error) {
+	opts := make(map[string]string)
+	switch object := p.next(); {
+	case object.is("DATABASES"), object.is("DATABASE"):
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtListDatabases{Stmt: p.newStmt(c, start, opts)}
+		return stmt, stmt.validate()
+	case object.is("COLLECTIONS"), object.is("COLLECTION"), object.is("TABLES"), object.is("TABLE"):
+		dbName := defaultDb
+		if p.accept("FROM") {
+			var err error
+			if dbName, err = p.parseName("database name"); err != nil {
+				return nil, err
+			}
+		}
+		if !p.atEnd() {
+			return nil, p.unexpected("end of statement")
+		}
+		stmt := &StmtListCollections{Stmt: p.newStmt(c, start, opts), dbName: dbName}
+		return stmt, stmt.validate()
+	}
+	p.pos--
+	return nil, p.unexpected("DATABASES, COLLECTIONS or TABLES")
+}
+
+func (p *sqlParser) parseInsert(c *Conn, defaultDb string, isUpsert bool, start int) (driver.Stmt, error) {
+	if err := p.expect("INTO"); err != nil {
+		return nil, err
+	}
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	crud := &StmtCRUD{dbName: dbName, collName: collName}
+	opts := make(map[string]string)
+	switch {
+	case p.peek().is("("):
+		stmt := &StmtInsert{StmtCRUD: crud, isUpsert: isUpsert}
+		if err := p.parseInsertValues(stmt); err != nil {
+			return nil, err
+		}
+		if crud.returning, err = p.parseTail(opts, true); err != nil {
+			return nil, err
+		}
+		crud.Stmt = p.newStmt(c, start, opts)
+		if err := stmt.parse(); err != nil {
+			return nil, err
+		}
+		return stmt, stmt.validate()
+	case p.peek().is("VALUE"):
+		p.next()
+		stmt := &StmtInsertValue{StmtCRUD: crud, isUpsert: isUpsert}
+		token, valueStart := p.peek(), p.pos
+		switch token.kind {
+		case tokPlaceholder, tokNamedPlaceholder:
+			stmt.value, _ = p.parseValue()
+		default:
+			if !token.is("{") {
+				return nil, p.unexpected("JSON object or placeholder")
+			}
+			if err := p.skipBlock(); err != nil {
+				return nil, err
+			}
+			if stmt.value, err = _toDocument(p.sourceText(valueStart, p.pos)); err != nil {
+				return nil, p.errorAt(token, "%s", err)
+			}
+		}
+		stmt.valueStr = p.sourceText(valueStart, p.pos)
+		if crud.returning, err = p.parseTail(opts, true); err != nil {
+			return nil, err
+		}
+		crud.Stmt = p.newStmt(c, start, opts)
+		if err := stmt.parse(); err != nil {
+			return nil, err
+		}
+		return stmt, stmt.validate()
+	case p.peek().is("SELECT"):
+		// the source query is parsed as a regular SELECT, defaulting to the target database
+		selectStmt, returning, err := p.parseSelect(c, dbName, true)
+		if err != nil {
+			return nil, err
+		}
+		crud.returning = returning
+		crud.Stmt = p.newStmt(c, start, opts)
+		stmt := &StmtInsertSelect{StmtCRUD: crud, isUpsert: isUpsert, selectStmt: selectStmt}
+		return stmt, stmt.validate()
+	}
+	return nil, p.unexpected("field list, VALUE or SELECT")
+}
+
+// parseInsertValues parses "(<field>[,<field>...]) VALUES (<value>[,<value>...])[, (<value>[,<value>...])...]".
+func (p *sqlParser) parseInsertValues(stmt *StmtInsert) error {
+	if err := p.expect("("); err != nil {
+		return err
+	}
+	start := p.pos
+	stmt.fields = make([]string, 0)
+	for {
+		field, err := p.parseName("field name")
+		if err != nil {
+			return err
+		}
+		stmt.fields = append(stmt.fields, field)
+		if !p.accept(",") {
+			break
+		}
+	}
+	stmt.fieldsStr = p.sourceText(start, p.pos)
+	if err := p.expect(")"); err != nil {
+		return err
+	}
+	if err := p.expect("VALUES"); err != nil {
+		return err
+	}
+	moreValuesStart := -1
+	for {
+		if err := p.expect("("); err != nil {
+			return err
+		}
+		start := p.pos
+		values := make([]interface{}, 0)
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			values = append(values, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if stmt.values == nil {
+			stmt.values, stmt.valuesStr = values, p.sourceText(start, p.pos)
+		} else {
+			stmt.moreValues = append(stmt.moreValues, values)
+		}
+		if err := p.expect(")"); err != nil {
+			return err
+		}
+		if !p.peek().is(",") || !p.peekAt(1).is("(") {
+			break
+		}
+		if moreValuesStart < 0 {
+			moreValuesStart = p.pos
+		}
+		p.next()
+	}
+	if moreValuesStart >= 0 {
+		stmt.moreValuesStr = p.sourceText(moreValuesStart, p.pos)
+	}
+	return nil
+}
+
+// parseSelect parses a SELECT statement. If nested (i.e. INSERT ... SELECT), the statement may have a RETURNING clause
+// (which belongs to the INSERT statement).
+func (p *sqlParser) parseSelect(c *Conn, defaultDb string, nested bool) (*StmtSelect, []string, error) {
+	start := p.pos
+	if err := p.expect("SELECT"); err != nil {
+		return nil, nil, err
+	}
+	isCrossPartition := false
+	queryStart := start
+	if p.peek().is("CROSS") && p.peekAt(1).is("PARTITION") {
+		p.pos += 2
+		isCrossPartition = true
+		queryStart = p.pos
+	}
+	bodyStart := p.pos
+	stopKeywords := []string{"WITH"}
+	if nested {
+		stopKeywords = append(stopKeywords, "RETURNING")
+	}
+	if err := p.skipClause(stopKeywords...); err != nil {
+		return nil, nil, err
+	}
+	end := p.pos
+
+	// the collection name is extracted from the (top-level) FROM clause
+	collName, replace := "", map[int]string{}
+	depth := 0
+	for i := bodyStart; i < end && collName == ""; i++ {
+		token := p.tokens[i]
+		switch {
+		case token.is("("), token.is("["), token.is("{"):
+			depth++
+		case token.is(")"), token.is("]"), token.is("}"):
+			depth--
+		case depth == 0 && token.is("FROM") && i+1 < end:
+			p.pos = i + 1
+			name, err := p.parseName("collection name")
+			if err != nil {
+				return nil, nil, err
+			}
+			collName = name
+			if p.tokens[i+1].kind == tokQuotedIdent {
+				// a quoted collection name is not valid Cosmos DB SQL, the collection is referred to as "root"
+				replace[i+1] = "root"
+			}
+		}
+	}
+	if collName == "" {
+		p.pos = end
+		return nil, nil, p.unexpected("FROM clause")
+	}
+
+	var readManyItems [][]interface{}
+	if !nested {
+		items, isReadMany, err := p.parseReadMany(bodyStart, end)
+		if err != nil {
+			return nil, nil, err
+		}
+		if isReadMany {
+			readManyItems = items
+		}
+	}
+	p.pos = end
+
+	selectQuery := p.rawText(queryStart, end, replace)
+	if isCrossPartition {
+		selectQuery = p.tokens[start].text + " " + selectQuery
+	}
+	opts := make(map[string]string)
+	returning, err := p.parseTail(opts, nested)
+	if err != nil {
+		return nil, nil, err
+	}
+	stmt := &StmtSelect{
+		Stmt:             p.newStmt(c, start, opts),
+		isCrossPartition: isCrossPartition,
+		dbName:           defaultDb,
+		collName:         collName,
+		selectQuery:      selectQuery,
+		readManyItems:    readManyItems,
+	}
+	if err := stmt.parse(); err != nil {
+		return nil, nil, err
+	}
+	return stmt, returning, stmt.validate()
+}
+
+// parseReadMany checks if the SELECT query [from, to) is in the read-many form
+// "* FROM <collection> [<alias>] WHERE (id, <pk-field>[, <pk-field>...]) IN ((<id>, <pk-value>[, <pk-value>...])[, ...])"
+// and returns the (id, partition key values...) tuples. isReadMany is false if the query is not in this form.
+func (p *sqlParser) parseReadMany(from, to int) (items [][]interface{}, isReadMany bool, err error) {
+	p.pos = from
+	if !p.accept("*") || !p.accept("FROM") {
+		return nil, false, nil
+	}
+	if _, err := p.parseName("collection name"); err != nil {
+		return nil, false, nil
+	}
+	if token := p.peek(); token.kind == tokIdent && !token.is("WHERE") {
+		// alias of the collection
+		p.next()
+	}
+	if !p.accept("WHERE") || !p.accept("(") {
+		return nil, false, nil
+	}
+	numFields := 0
+	for {
+		field, err := p.parseFieldPath()
+		if err != nil || (numFields == 0 && field != "id" && !strings.HasSuffix(field, ".id")) {
+			return nil, false, nil
+		}
+		numFields++
+		if !p.accept(",") {
+			break
+		}
+	}
+	if numFields < 2 || !p.accept(")") || !p.accept("IN") || !p.peek().is("(") || !p.peekAt(1).is("(") {
+		return nil, false, nil
+	}
+
+	// Cosmos DB does not support tuples: from here on, the query is handled as a read-many query
+	p.next()
+	items = make([][]interface{}, 0)
+	for {
+		if err := p.expect("("); err != nil {
+			return nil, true, err
+		}
+		token := p.peek()
+		item := make([]interface{}, 0, numFields)
+		for {
+			value, err := p.parseSqlLiteral()
+			if err != nil {
+				return nil, true, err
+			}
+			item = append(item, value)
+			if !p.accept(",") {
+				break
+			}
+		}
+		if len(item) != numFields {
+			return nil, true, p.errorAt(token, "expect %d values (id and partition key values) but found %d", numFields, len(item))
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, true, err
+		}
+		items = append(items, item)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, true, err
+	}
+	if p.pos != to {
+		return nil, true, p.unexpected("WITH clause or end of statement")
+	}
+	return items, true, nil
+}
+
+var sqlStringUnescaper = strings.NewReplacer(`\'`, `'`, `\"`, `"`, `\\`, `\`)
+
+// parseSqlLiteral parses a Cosmos DB SQL literal: a placeholder, null, a boolean, a number or a string enclosed in
+// single or double quotes.
+func (p *sqlParser) parseSqlLiteral() (interface{}, error) {
+	token := p.peek()
+	if token.kind == tokString {
+		p.next()
+		return sqlStringUnescaper.Replace(token.text[1 : len(token.text)-1]), nil
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := value.(string); ok {
+		return nil, p.errorAt(token, "invalid value %s, expect a placeholder, null, a boolean, a number or a quoted string", token.text)
+	}
+	return value, nil
+}
+
+func (p *sqlParser) parseUpdate(c *Conn, defaultDb string, start int) (driver.Stmt, error) {
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	updateStart := p.pos
+	fields, values, err := p.parseUpdateClause()
+	if err != nil {
+		return nil, err
+	}
+	updateStr := p.rawText(updateStart, p.pos, nil)
+	where, whereStr, err := p.parseWhere()
+	if err != nil {
+		return nil, err
+	}
+	crud := &StmtCRUD{dbName: dbName, collName: collName}
+	opts := make(map[string]string)
+	if crud.returning, err = p.parseTail(opts, true); err != nil {
+		return nil, err
+	}
+	crud.Stmt = p.newStmt(c, start, opts)
+	stmt := &StmtUpdate{StmtCRUD: crud, updateStr: updateStr, whereStr: whereStr, fields: fields, values: values}
+	if err := stmt.parse(where); err != nil {
+		return nil, err
+	}
+	return stmt, stmt.validate()
+}
+
+func (p *sqlParser) parseDelete(c *Conn, defaultDb string, start int) (driver.Stmt, error) {
+	if err := p.expect("FROM"); err != nil {
+		return nil, err
+	}
+	dbName, collName, err := p.parseQualifiedName()
+	if err != nil {
+		return nil, err
+	}
+	if dbName == "" {
+		dbName = defaultDb
+	}
+	where, whereStr, err := p.parseWhere()
+	if err != nil {
+		return nil, err
+	}
+	crud := &StmtCRUD{dbName: dbName, collName: collName}
+	opts := make(map[string]string)
+	if crud.returning, err = p.parseTail(opts, true); err != nil {
+		return nil, err
+	}
+	crud.Stmt = p.newStmt(c, start, opts)
+	stmt := &StmtDelete{StmtCRUD: crud, whereStr: whereStr}
+	if err := stmt.parse(where); err != nil {
+		return nil, err
+	}
+	return stmt, stmt.validate()
+}
@@ -0,0 +1,214 @@
+package gocosmos
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSqlParser_syntaxErrors(t *testing.T) {
+	testName := "TestSqlParser_syntaxErrors"
+	testData := []struct {
+		name   string
+		sql    string
+		line   int
+		column int
+	}{
+		{name: "empty", sql: "  -- nothing here", line: 1, column: 18},
+		{name: "unknown_statement", sql: "MERGE INTO db.table", line: 1, column: 1},
+		{name: "missing_collection", sql: "CREATE COLLECTION db.", line: 1, column: 22},
+		{name: "unterminated_string", sql: "INSERT INTO db.table (a) VALUES (\n\"abc)", line: 2, column: 1},
+		{name: "unterminated_comment", sql: "SELECT * FROM c /* comment", line: 1, column: 17},
+		{name: "unterminated_quoted_identifier", sql: "DROP DATABASE `db", line: 1, column: 15},
+		{name: "invalid_value", sql: "INSERT INTO db.table (a, b)\nVALUES (1, 'x')", line: 2, column: 12},
+		{name: "invalid_json", sql: "INSERT INTO db.table (a) VALUES (\"not json\")", line: 1, column: 34},
+		{name: "missing_parenthesis", sql: "INSERT INTO db.table (a, b) VALUES (1, 2", line: 1, column: 41},
+		{name: "unbalanced_parenthesis", sql: "DELETE FROM db.table WHERE (c.a > 1))", line: 1, column: 37},
+		{name: "missing_where", sql: "UPDATE db.table SET a=1", line: 1, column: 24},
+		{name: "where_stray_operand", sql: "UPDATE db.table SET a=1\n  WHERE id=1 id=2", line: 2, column: 14},
+		{name: "with_twice", sql: "CREATE DATABASE db WITH ru=400\nWITH RU=500", line: 2, column: 6},
+		{name: "with_no_value", sql: "CREATE DATABASE db WITH ru=", line: 1, column: 28},
+		{name: "trailing_tokens", sql: "DROP DATABASE db garbage", line: 1, column: 18},
+		{name: "after_terminator", sql: "LIST DATABASES; LIST DATABASES", line: 1, column: 17},
+		{name: "select_no_from", sql: "SELECT 1", line: 1, column: 9},
+		{name: "returning_expression", sql: "DELETE FROM db.table WHERE id=1 RETURNING a+b", line: 1, column: 44},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("%s failed: expected SyntaxError but received %#v", testName+"/"+testCase.name, err)
+			}
+			if syntaxErr.Line != testCase.line || syntaxErr.Column != testCase.column {
+				t.Fatalf("%s failed: expected error at %d:%d but received %s", testName+"/"+testCase.name, testCase.line, testCase.column, err)
+			}
+		})
+	}
+}
+
+func TestSqlParser_comments(t *testing.T) {
+	testName := "TestSqlParser_comments"
+	testData := []struct {
+		name     string
+		sql      string
+		expected string
+	}{
+		{
+			name:     "insert",
+			sql:      "/* new user */ INSERT INTO db.table -- the users\n(id, name) VALUES (:1, /* name */ :2) WITH pk=/id -- partition key",
+			expected: "INSERT INTO db.table (id, name) VALUES (:1, :2) WITH pk=/id",
+		},
+		{
+			name:     "select",
+			sql:      "SELECT CROSS PARTITION c.id -- only id\nFROM c WHERE c.age > 18 /* adults */ WITH db=db WITH collection=table;",
+			expected: "SELECT CROSS PARTITION c.id FROM c WHERE c.age > 18 WITH db=db WITH collection=table",
+		},
+		{
+			name:     "delete",
+			sql:      "DELETE FROM db.table WHERE c.a > 1 -- WITH MAX_ROWS=1\n",
+			expected: "DELETE FROM db.table WHERE c.a > 1",
+		},
+		{
+			name:     "trailing_comment",
+			sql:      "DELETE FROM db.table WHERE c.a=1/* note */",
+			expected: "DELETE FROM db.table WHERE c.a=1",
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			stmt, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			expected, err := ParseQueryWithDefaultDb(nil, "", testCase.expected)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			_clearStmtText(stmt)
+			_clearStmtText(expected)
+			if !reflect.DeepEqual(stmt, expected) {
+				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, expected, stmt)
+			}
+		})
+	}
+}
+
+// _clearStmtText clears the fields holding the text of the statement, which may differ by whitespaces and comments.
+func _clearStmtText(stmt interface{}) {
+	switch s := stmt.(type) {
+	case *StmtInsert:
+		s.query, s.fieldsStr, s.valuesStr = "", "", ""
+	case *StmtSelect:
+		s.query = ""
+	case *StmtDelete:
+		s.query = ""
+	}
+}
+
+func TestSqlParser_quotedIdentifiers(t *testing.T) {
+	testName := "TestSqlParser_quotedIdentifiers"
+	s, err := ParseQueryWithDefaultDb(nil, "", "CREATE COLLECTION `my db`.`my.coll``s` WITH pk=/id")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtCreateCollection); stmt.dbName != "my db" || stmt.collName != "my.coll`s" {
+		t.Fatalf("%s failed: received db %q / collection %q", testName, stmt.dbName, stmt.collName)
+	}
+
+	s, err = ParseQueryWithDefaultDb(nil, "", "SELECT * FROM `my-coll` WHERE root.a=1 WITH db=`my db`")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtSelect); stmt.dbName != "my db" || stmt.collName != "my-coll" || stmt.selectQuery != "SELECT * FROM root WHERE root.a=1" {
+		t.Fatalf("%s failed: received db %q / collection %q / query %q", testName, stmt.dbName, stmt.collName, stmt.selectQuery)
+	}
+
+	s, err = ParseQueryWithDefaultDb(nil, "", "INSERT INTO db.table (id, `first name`) VALUES (:1, :2)")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtInsert); !reflect.DeepEqual(stmt.fields, []string{"id", "first name"}) {
+		t.Fatalf("%s failed: received fields %#v", testName, stmt.fields)
+	}
+}
+
+func TestSqlParser_specialValues(t *testing.T) {
+	testName := "TestSqlParser_specialValues"
+
+	// parentheses, commas and keywords inside string values
+	s, err := ParseQueryWithDefaultDb(nil, "", `INSERT INTO db.table (id, note) VALUES ("\"(1)\"", "\"a, b) WITH pk=/x\""), (:1, "\"RETURNING\"") WITH pk=/id`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	stmt := s.(*StmtInsert)
+	if !reflect.DeepEqual(stmt.values, []interface{}{"(1)", "a, b) WITH pk=/x"}) || !reflect.DeepEqual(stmt.moreValues, [][]interface{}{{placeholder{1}, "RETURNING"}}) || stmt.withPk != "/id" {
+		t.Fatalf("%s failed: received values %#v / %#v / pk %q", testName, stmt.values, stmt.moreValues, stmt.withPk)
+	}
+
+	// WITH keyword inside a string of the predicate
+	s, err = ParseQueryWithDefaultDb(nil, "", `DELETE FROM db.table WHERE c.note = 'done WITH care' AND c.a IN (1, 2) WITH MAX_ROWS=5`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtDelete); stmt.predicate != `c.note = 'done WITH care' AND c.a IN (1, 2)` || stmt.maxRows != 5 {
+		t.Fatalf("%s failed: received predicate %q / max rows %d", testName, stmt.predicate, stmt.maxRows)
+	}
+
+	// placeholders inside strings of a query are not rewritten
+	s, err = ParseQueryWithDefaultDb(nil, "", `SELECT * FROM c WHERE c.time = "10:30" AND c.a = :1 WITH db=db`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtSelect); stmt.selectQuery != `SELECT * FROM c WHERE c.time = "10:30" AND c.a = @_1` {
+		t.Fatalf("%s failed: received query %q", testName, stmt.selectQuery)
+	}
+}
+
+func TestSqlParser_terminator(t *testing.T) {
+	testName := "TestSqlParser_terminator"
+	s, err := ParseQueryWithDefaultDb(nil, "", "CREATE COLLECTION db.table WITH pk=/id WITH uk=/a;/b ;")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtCreateCollection); !reflect.DeepEqual(stmt.uk, [][]string{{"/a"}, {"/b"}}) {
+		t.Fatalf("%s failed: received unique keys %#v", testName, stmt.uk)
+	}
+}
+
+func TestSqlParser_wildcardPaths(t *testing.T) {
+	testName := "TestSqlParser_wildcardPaths"
+	s, err := ParseQueryWithDefaultDb(nil, "", "CREATE COLLECTION db.table /* new */ WITH pk=/id WITH include=/*,/tags/* WITH exclude=/a/* /* comment */")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtCreateCollection); !reflect.DeepEqual(stmt.indexing.include, []string{"/*", "/tags/*"}) || !reflect.DeepEqual(stmt.indexing.exclude, []string{"/a/*"}) {
+		t.Fatalf("%s failed: received included paths %#v / excluded paths %#v", testName, stmt.indexing.include, stmt.indexing.exclude)
+	}
+}
+
+func TestSqlParser_wildcardPathsList(t *testing.T) {
+	testName := "TestSqlParser_wildcardPathsList"
+	s, err := ParseQueryWithDefaultDb(nil, "", "ALTER COLLECTION db.table WITH include=/*, /tags/*, WITH spatial=/loc/*;")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtAlterCollection); !reflect.DeepEqual(stmt.indexing.include, []string{"/*", "/tags/*"}) || !reflect.DeepEqual(stmt.indexing.spatial, []string{"/loc/*"}) {
+		t.Fatalf("%s failed: received included paths %#v / spatial paths %#v", testName, stmt.indexing.include, stmt.indexing.spatial)
+	}
+
+	if _, err := ParseQueryWithDefaultDb(nil, "", "ALTER COLLECTION db.table WITH include=/*, tags"); err == nil {
+		t.Fatalf("%s failed: expected error", testName)
+	}
+}
+
+func TestSqlParser_wildcardIndexPaths(t *testing.T) {
+	testName := "TestSqlParser_wildcardIndexPaths"
+	s, err := ParseQueryWithDefaultDb(nil, "", "DROP INDEX ON db.table (/*, /tags/*) /* comment */")
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if stmt := s.(*StmtDropIndex); !reflect.DeepEqual(stmt.paths, []indexPath{{path: "/*"}, {path: "/tags/*"}}) {
+		t.Fatalf("%s failed: received paths %#v", testName, stmt.paths)
+	}
+}
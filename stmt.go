@@ -1,221 +1,101 @@
 package gocosmos
 
 import (
+	"context"
 	"database/sql/driver"
 	"fmt"
 	"io"
+	"net/http"
 	"reflect"
-	"regexp"
 	"sort"
 	"strings"
-)
+	"sync"
+	"sync/atomic"
+	"time"
 
-const (
-	field       = `([\w\-]+)`
-	ifNotExists = `(\s+IF\s+NOT\s+EXISTS)?`
-	ifExists    = `(\s+IF\s+EXISTS)?`
-	with        = `(\s+WITH\s+.*)?`
-)
-
-var (
-	reCreateDb = regexp.MustCompile(`(?is)^CREATE\s+DATABASE` + ifNotExists + `\s+` + field + with + `$`)
-	reAlterDb  = regexp.MustCompile(`(?is)^ALTER\s+DATABASE` + `\s+` + field + with + `$`)
-	reDropDb   = regexp.MustCompile(`(?is)^DROP\s+DATABASE` + ifExists + `\s+` + field + `$`)
-	reListDbs  = regexp.MustCompile(`(?is)^LIST\s+DATABASES?$`)
-
-	reCreateColl = regexp.MustCompile(`(?is)^CREATE\s+(COLLECTION|TABLE)` + ifNotExists + `\s+(` + field + `\.)?` + field + with + `$`)
-	reAlterColl  = regexp.MustCompile(`(?is)^ALTER\s+(COLLECTION|TABLE)` + `\s+(` + field + `\.)?` + field + with + `$`)
-	reDropColl   = regexp.MustCompile(`(?is)^DROP\s+(COLLECTION|TABLE)` + ifExists + `\s+(` + field + `\.)?` + field + `$`)
-	reListColls  = regexp.MustCompile(`(?is)^LIST\s+(COLLECTIONS?|TABLES?)(\s+FROM\s+` + field + `)?$`)
-
-	reInsert = regexp.MustCompile(`(?is)^(INSERT|UPSERT)\s+INTO\s+(` + field + `\.)?` + field + `\s*\(([^)]*?)\)\s*VALUES\s*\(([^)]*?)\)` + with + `$`)
-	reSelect = regexp.MustCompile(`(?is)^SELECT\s+(CROSS\s+PARTITION\s+)?.*?\s+FROM\s+` + field + `.*?` + with + `$`)
-	//reUpdate = regexp.MustCompile(`(?is)^UPDATE\s+(` + field + `\.)?` + field + `\s+SET\s+(.*)\s+WHERE\s+id\s*=\s*(.*?)` + with + `$`)
-	reUpdate = regexp.MustCompile(`(?is)^UPDATE\s+(` + field + `\.)?` + field + `\s+SET\s+(.*)\s+WHERE\s+(.*?)` + with + `$`)
-	reDelete = regexp.MustCompile(`(?is)^DELETE\s+FROM\s+(` + field + `\.)?` + field + `\s+WHERE\s+(.*?)` + with + `$`)
+	"github.com/btnguyen2k/consu/g18"
 )
 
 // ParseQueryWithDefaultDb parses the given query and returns a Stmt.
 //
+// (since v1.2.0) Statements are parsed by a tokenizer-based parser: "-- comment" and "/* comment */" are allowed
+// anywhere whitespaces are, names with special characters can be quoted with backticks (e.g. `my-db.2`), the statement
+// may end with a ";", and syntax errors are reported as *SyntaxError with the line and column where they occur.
+//
 // @Available since v1.0.0
 func ParseQueryWithDefaultDb(c *Conn, defaultDb, query string) (driver.Stmt, error) {
-	query = strings.TrimSpace(query)
-	if re := reCreateDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtCreateDatabase{
-			Stmt:        &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:      strings.TrimSpace(groups[0][2]),
-			ifNotExists: strings.TrimSpace(groups[0][1]) != "",
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][3])); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reAlterDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtAlterDatabase{
-			Stmt:   &Stmt{query: query, conn: c, numInputs: 0},
-			dbName: strings.TrimSpace(groups[0][1]),
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][2])); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reDropDb; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtDropDatabase{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][2]),
-			ifExists: strings.TrimSpace(groups[0][1]) != "",
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reListDbs; re.MatchString(query) {
-		stmt := &StmtListDatabases{
-			Stmt: &Stmt{query: query, conn: c, numInputs: 0},
-		}
-		return stmt, stmt.validate()
+	p := newSqlParser(query)
+	stmt, err := p.parseStatement(c, defaultDb)
+	if err == nil {
+		err = p.expectEnd()
+	} else if p.peek().kind == tokEOF && p.err != nil {
+		// the statement is cut short by a lexer error (e.g. an unterminated comment)
+		err = p.err
 	}
+	return stmt, err
+}
 
-	if re := reCreateColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtCreateCollection{
-			Stmt:        &Stmt{query: query, conn: c, numInputs: 0},
-			ifNotExists: strings.TrimSpace(groups[0][2]) != "",
-			dbName:      strings.TrimSpace(groups[0][4]),
-			collName:    strings.TrimSpace(groups[0][5]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][6])); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reAlterColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtAlterCollection{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][3]),
-			collName: strings.TrimSpace(groups[0][4]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(strings.TrimSpace(groups[0][5])); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reDropColl; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtDropCollection{
-			Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-			dbName:   strings.TrimSpace(groups[0][4]),
-			collName: strings.TrimSpace(groups[0][5]),
-			ifExists: strings.TrimSpace(groups[0][2]) != "",
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reListColls; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtListCollections{
-			Stmt:   &Stmt{query: query, conn: c, numInputs: 0},
-			dbName: strings.TrimSpace(groups[0][3]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		return stmt, stmt.validate()
-	}
+const (
+	bulkConcurrency        = 8  // number of parallel requests used by multi-document statements
+	bulkMaxThrottleRetries = 10 // number of times a throttled request of a multi-document statement is retried
+)
 
-	if re := reInsert; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtInsert{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][3]),
-				collName: strings.TrimSpace(groups[0][4]),
-			},
-			isUpsert:  strings.ToUpper(strings.TrimSpace(groups[0][1])) == "UPSERT",
-			fieldsStr: strings.TrimSpace(groups[0][5]),
-			valuesStr: strings.TrimSpace(groups[0][6]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(groups[0][7]); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
-	}
-	if re := reSelect; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtSelect{
-			Stmt:             &Stmt{query: query, conn: c, numInputs: 0},
-			isCrossPartition: strings.TrimSpace(groups[0][1]) != "",
-			collName:         strings.TrimSpace(groups[0][2]),
-			dbName:           defaultDb,
-			selectQuery:      strings.ReplaceAll(strings.ReplaceAll(query, groups[0][1], ""), groups[0][3], ""),
-		}
-		if err := stmt.parse(groups[0][3]); err != nil {
-			return nil, err
-		}
-		return stmt, stmt.validate()
+// _parallelDo calls fn(i) for each i in [0, n) using up to concurrency goroutines, and returns the error of each call.
+//
+// If stopOnError is true, remaining calls are skipped once a call returned an error (the errors of skipped calls are nil).
+// Remaining calls are also skipped if ctx is done, their errors are set to ctx.Err().
+func _parallelDo(ctx context.Context, n, concurrency int, stopOnError bool, fn func(i int) error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	var stopped int32
+	indexes := make(chan int)
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if errs[i] = fn(i); errs[i] != nil && stopOnError {
+					atomic.StoreInt32(&stopped, 1)
+				}
+			}
+		}()
 	}
-	if re := reUpdate; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtUpdate{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][2]),
-				collName: strings.TrimSpace(groups[0][3]),
-			},
-			updateStr: strings.TrimSpace(groups[0][4]),
-			whereStr:  strings.TrimSpace(groups[0][5]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(groups[0][6]); err != nil {
-			return nil, err
+	for i := 0; i < n && atomic.LoadInt32(&stopped) == 0; i++ {
+		if err := ctx.Err(); err != nil {
+			for ; i < n; i++ {
+				errs[i] = err
+			}
+			break
 		}
-		return stmt, stmt.validate()
+		indexes <- i
 	}
-	if re := reDelete; re.MatchString(query) {
-		groups := re.FindAllStringSubmatch(query, -1)
-		stmt := &StmtDelete{
-			StmtCRUD: &StmtCRUD{
-				Stmt:     &Stmt{query: query, conn: c, numInputs: 0},
-				dbName:   strings.TrimSpace(groups[0][2]),
-				collName: strings.TrimSpace(groups[0][3]),
-			},
-			whereStr: strings.TrimSpace(groups[0][4]),
-		}
-		if stmt.dbName == "" {
-			stmt.dbName = defaultDb
-		}
-		if err := stmt.parse(groups[0][5]); err != nil {
-			return nil, err
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
+// _retryThrottled calls fn, retrying up to maxRetries times while the request is throttled (status 429).
+// The retry-after hint returned by the server is honored between retries.
+func _retryThrottled(maxRetries int, fn func() *RestResponse) *RestResponse {
+	for numRetries := 0; ; numRetries++ {
+		resp := fn()
+		if resp.StatusCode != http.StatusTooManyRequests || numRetries >= maxRetries {
+			return resp
 		}
-		return stmt, stmt.validate()
+		time.Sleep(_retryAfter(resp.RespHeader[respHeaderRetryAfterMs]))
 	}
-
-	return nil, fmt.Errorf("invalid query: %s", query)
 }
 
 // Stmt is Azure Cosmos DB abstract implementation of driver.Stmt.
 type Stmt struct {
-	query     string // the SQL query
-	conn      *Conn  // the connection that this prepared statement is bound to
-	numInputs int    // number of placeholder parameters, INCLUDING PK values!
-	withOpts  map[string]string
+	query       string            // the SQL query
+	conn        *Conn             // the connection that this prepared statement is bound to
+	numInputs   int               // number of placeholder parameters, INCLUDING PK values!
+	namedInputs map[string]bool   // names of named placeholders (e.g. @name) used by the statement
+	withOpts    map[string]string // options of the WITH clause, keyed by upper-cased option name
+
+	consistencyLevel string // consistency level of read/query requests, specified via WITH CONSISTENCY
+	sessionToken     string // session token of read/query requests, specified via WITH SESSION_TOKEN
 }
 
 // String implements interface fmt.Stringer/String.
@@ -237,25 +117,79 @@ func (s *Stmt) onlyOneWithOption(errmsg string, optKeys ...string) error {
 	return nil
 }
 
-var reWithOpts = regexp.MustCompile(`(?is)^(\s+|\s*,\s+|\s+,\s*)WITH\s+` + field + `(\s*=\s*([\w/\.\*,;:'"-]+))?`)
+// trackPlaceholder records the placeholder (if value is one) so that the statement knows its expected inputs.
+func (s *Stmt) trackPlaceholder(value interface{}) {
+	switch v := value.(type) {
+	case placeholder:
+		s.numInputs = g18.Max(s.numInputs, v.index)
+	case namedPlaceholder:
+		if s.namedInputs == nil {
+			s.namedInputs = make(map[string]bool)
+		}
+		s.namedInputs[v.name] = true
+	}
+}
 
-// parseWithOpts parses "WITH..." clause and store result in withOpts map.
-// This function returns no error. Sub-implementations may override this behavior.
-func (s *Stmt) parseWithOpts(withOptsStr string) error {
-	withOptsStr = " " + withOptsStr
-	s.withOpts = make(map[string]string)
-	for {
-		matches := reWithOpts.FindStringSubmatch(withOptsStr)
-		if matches == nil {
-			break
+// bindArgs splits the supplied arguments into positional and named values.
+//
+// Named values are validated against the named placeholders used by the statement: a named placeholder without value
+// and a named value that is not used by the statement are both reported as errors.
+func (s *Stmt) bindArgs(args []driver.NamedValue) (*stmtArgs, error) {
+	result := &stmtArgs{positional: make([]driver.Value, 0, len(args)), named: make(map[string]driver.Value)}
+	for _, arg := range args {
+		if arg.Name == "" {
+			result.positional = append(result.positional, arg.Value)
+			continue
+		}
+		name := strings.TrimPrefix(arg.Name, "@")
+		if !s.namedInputs[name] {
+			return nil, fmt.Errorf("named parameter @%s is not used in the statement", name)
+		}
+		if _, ok := result.named[name]; ok {
+			return nil, fmt.Errorf("named parameter @%s is supplied more than once", name)
+		}
+		result.named[name] = arg.Value
+	}
+	names := make([]string, 0, len(s.namedInputs))
+	for name := range s.namedInputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := result.named[name]; !ok {
+			return nil, fmt.Errorf("missing value for named parameter @%s", name)
 		}
-		k := strings.TrimSpace(strings.ToUpper(matches[2]))
-		s.withOpts[k] = strings.TrimSpace(strings.TrimSuffix(matches[4], ","))
-		withOptsStr = withOptsStr[len(matches[0]):]
+	}
+	return result, nil
+}
+
+// parseConsistencyOpts extracts the WITH CONSISTENCY and WITH SESSION_TOKEN options.
+func (s *Stmt) parseConsistencyOpts() error {
+	if v, ok := s.withOpts["CONSISTENCY"]; ok {
+		level, err := _normalizeConsistencyLevel(v)
+		if err != nil || level == "" {
+			return fmt.Errorf("invalid value at WITH CONSISTENCY (accepted values: Strong, BoundedStaleness, Session, ConsistentPrefix or Eventual)")
+		}
+		s.consistencyLevel = level
+	}
+	if v, ok := s.withOpts["SESSION_TOKEN"]; ok {
+		if v = strings.Trim(v, `'"`); v == "" {
+			return fmt.Errorf("invalid value at WITH SESSION_TOKEN (session token must not be empty)")
+		}
+		s.sessionToken = v
 	}
 	return nil
 }
 
+// readSessionToken returns the session token to attach to read/query requests on the specified collection: the one
+// specified via WITH SESSION_TOKEN if any, otherwise the latest one tracked by the connection.
+func (s *Stmt) readSessionToken(dbName, collName string) string {
+	if s.sessionToken != "" {
+		return s.sessionToken
+	}
+	return s.conn.sessions.Get(dbName, collName)
+}
+
 // Close implements driver.Stmt/Close.
 func (s *Stmt) Close() error {
 	return nil
@@ -268,6 +202,33 @@ func (s *Stmt) NumInput() int {
 
 /*----------------------------------------------------------------------*/
 
+// stmtArgs holds the arguments supplied to a statement execution.
+type stmtArgs struct {
+	positional []driver.Value          // values for positional placeholders, in order
+	named      map[string]driver.Value // values for named placeholders, keyed by name (without the leading '@')
+}
+
+// resolve returns the argument value bound to the supplied placeholder, or value as-is if it is not a placeholder.
+func (a *stmtArgs) resolve(value interface{}) interface{} {
+	switch v := value.(type) {
+	case placeholder:
+		return a.positional[v.index-1]
+	case namedPlaceholder:
+		return a.named[v.name]
+	}
+	return value
+}
+
+func _valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	result := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		result[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return result
+}
+
+/*----------------------------------------------------------------------*/
+
 func normalizeError(statusCode, ignoreErrorCode int, err error) error {
 	switch statusCode {
 	case 403:
@@ -318,7 +279,8 @@ type ResultNoResultSet struct {
 	err                 error
 	affectedRows        int64
 	supportLastInsertId bool
-	lastInsertId        string // holds the "_rid" if the operation returns it
+	lastInsertId        string    // holds the "_rid" if the operation returns it
+	docs                []DocInfo // the resulting documents, returned as rows by statements with a RETURNING clause
 }
 
 // LastInsertId implements driver.Result/LastInsertId.
@@ -2,13 +2,71 @@ package gocosmos
 
 import (
 	"database/sql/driver"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// _parseTtl parses a time-to-live value: a positive number of seconds or -1. If allowOff is true, OFF is also
+// accepted and returned as 0.
+func _parseTtl(v string, allowOff bool) (int, error) {
+	if allowOff && strings.EqualFold(v, "OFF") {
+		return 0, nil
+	}
+	ttl, err := strconv.ParseInt(v, 10, 32)
+	if err != nil || (ttl <= 0 && ttl != -1) {
+		if allowOff {
+			return 0, fmt.Errorf("invalid TTL value: %s (expect a positive number of seconds, -1 or OFF)", v)
+		}
+		return 0, fmt.Errorf("invalid TTL value: %s (expect a positive number of seconds or -1)", v)
+	}
+	return int(ttl), nil
+}
+
+// _modifyCollection reads the settings of a collection, modifies them with fn then replaces the collection if fn
+// returned true. Settings that are not modified by fn (partition key, indexing policy, unique keys, conflict resolution
+// policy, default time-to-live and any other property of the collection) are kept.
+// The returned result reports 1 affected row if the collection was replaced, 0 otherwise.
+func _modifyCollection(conn *Conn, dbName, collName string, fn func(spec *CollectionSpec) (bool, error)) (driver.Result, error) {
+	getResult := conn.restClient.GetCollection(dbName, collName)
+	if err := getResult.Error(); err != nil {
+		return nil, normalizeError(getResult.StatusCode, 0, err)
+	}
+	// work on a copy of the indexing policy so that the cached collection info is not modified
+	policy := make(map[string]interface{})
+	if js, err := json.Marshal(getResult.IndexingPolicy); err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(js, &policy); err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		policy = defaultIndexingPolicy()
+	}
+	props := make(map[string]interface{})
+	if err := json.Unmarshal(getResult.RespBody, &props); err != nil {
+		return nil, err
+	}
+	spec := CollectionSpec{DbName: dbName, CollName: collName, PartitionKeyInfo: getResult.PartitionKey,
+		IndexingPolicy: policy, UniqueKeyPolicy: getResult.UniqueKeyPolicy,
+		ConflictResolutionPolicy: getResult.ConflictResolutionPolicy, DefaultTtl: getResult.DefaultTtl, Properties: props}
+	changed, err := fn(&spec)
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		return &ResultNoResultSet{supportLastInsertId: true, lastInsertId: getResult.Rid}, nil
+	}
+	restResult := conn.restClient.ReplaceCollection(spec)
+	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
+	return result, result.err
+}
+
+/*----------------------------------------------------------------------*/
+
 // StmtCreateCollection implements "CREATE COLLECTION" statement.
 //
 // Syntax:
@@ -17,6 +75,13 @@ import (
 //	<WITH PK=partitionKey>
 //	[[,] WITH RU|MAXRU=ru]
 //	[[,] WITH UK=/path1:/path2,/path3;/path4]
+//	[[,] WITH INDEXING=consistent|none]
+//	[[,] WITH INCLUDE=/path1/?,/path2/*]
+//	[[,] WITH EXCLUDE=/path3/?,/path4/*]
+//	[[,] WITH COMPOSITE=/path1 ASC,/path2 DESC;/path3,/path4]
+//	[[,] WITH SPATIAL=/path5/*,/path6/*]
+//	[[,] WITH TTL=<seconds>|-1|OFF]
+//	[[,] WITH CONFLICT_PATH=/path|CONFLICT_SPROC=<sproc-name>]
 //
 // - ru: an integer specifying CosmosDB's collection throughput expressed in RU/s. Supply either RU or MAXRU, not both!
 //
@@ -25,21 +90,33 @@ import (
 // - If "IF NOT EXISTS" is specified, Exec will silently swallow the error "409 Conflict".
 //
 // - Use UK to define unique keys. Each unique key consists a list of paths separated by comma (,). Unique keys are separated by colons (:) or semi-colons (;).
+//
+// - (since v1.2.0) Use INDEXING, INCLUDE, EXCLUDE, COMPOSITE and SPATIAL to customize the indexing policy of the collection:
+// indexing mode, included paths, excluded paths, composite indexes (separated by colons or semi-colons, each one is a
+// list of paths with optional sort order ASC/DESC) and spatial indexes. Unspecified settings are the ones of Cosmos DB's
+// default indexing policy (all paths are included, except /"_etag"/?).
+//
+// - (since v1.2.0) Use TTL to set the default time-to-live of documents: a number of seconds, -1 (time-to-live is on but
+// documents do not expire unless they have their own "ttl" field) or OFF (default).
+//
+// - (since v1.2.0) Use CONFLICT_PATH or CONFLICT_SPROC to set the conflict resolution policy used with multi-region
+// writes: "Last Writer Wins" based on the (numeric) value at the specified path, or resolution by the specified stored
+// procedure of the collection. Supply either CONFLICT_PATH or CONFLICT_SPROC, not both!
 type StmtCreateCollection struct {
 	*Stmt
 	dbName      string
 	collName    string // collection name
 	ifNotExists bool
 	ru, maxru   int
-	pk          string     // partition key
-	uk          [][]string // unique keys
+	pk          string       // partition key
+	uk          [][]string   // unique keys
+	indexing    indexingOpts // indexing options
+	ttl         int          // default time-to-live of documents in seconds, 0 means off
+	lwwPath     string       // path of the "Last Writer Wins" conflict resolution policy
+	sproc       string       // stored procedure of the custom conflict resolution policy
 }
 
-func (s *StmtCreateCollection) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+func (s *StmtCreateCollection) parse() error {
 	for k, v := range s.withOpts {
 		switch k {
 		case "PK", "LARGEPK":
@@ -65,8 +142,28 @@ func (s *StmtCreateCollection) parse(withOptsStr string) error {
 				paths := regexp.MustCompile(`[,\s]+`).Split(token, -1)
 				s.uk = append(s.uk, paths)
 			}
+		case "TTL":
+			ttl, err := _parseTtl(v, true)
+			if err != nil {
+				return err
+			}
+			s.ttl = ttl
+		case "CONFLICT_PATH":
+			if !strings.HasPrefix(v, "/") {
+				return fmt.Errorf("invalid CONFLICT_PATH value: %s", v)
+			}
+			s.lwwPath = v
+		case "CONFLICT_SPROC":
+			if v == "" {
+				return fmt.Errorf("invalid CONFLICT_SPROC value: %s", v)
+			}
+			s.sproc = v
 		default:
-			return fmt.Errorf("invalid query, parsing error at WITH %s=%s", k, v)
+			if ok, err := s.indexing.parseOpt(k, v); err != nil {
+				return err
+			} else if !ok {
+				return fmt.Errorf("invalid query, parsing error at WITH %s=%s", k, v)
+			}
 		}
 	}
 
@@ -80,10 +177,13 @@ func (s *StmtCreateCollection) validate() error {
 	if s.ru > 0 && s.maxru > 0 {
 		return errors.New("only one of RU or MAXRU should be specified")
 	}
+	if s.lwwPath != "" && s.sproc != "" {
+		return errors.New("only one of CONFLICT_PATH or CONFLICT_SPROC should be specified")
+	}
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
-	return nil
+	return s.indexing.validate()
 }
 
 // Query implements driver.Stmt/Query.
@@ -99,7 +199,7 @@ func (s *StmtCreateCollection) Exec(_ []driver.Value) (driver.Result, error) {
 	if len(pkPaths) > 1 {
 		pkType = "MultiHash"
 	}
-	spec := CollectionSpec{DbName: s.dbName, CollName: s.collName, Ru: s.ru, MaxRu: s.maxru,
+	spec := CollectionSpec{DbName: s.dbName, CollName: s.collName, Ru: s.ru, MaxRu: s.maxru, DefaultTtl: s.ttl,
 		PartitionKeyInfo: map[string]interface{}{
 			"paths":   pkPaths,
 			"kind":    pkType,
@@ -113,6 +213,15 @@ func (s *StmtCreateCollection) Exec(_ []driver.Value) (driver.Result, error) {
 		}
 		spec.UniqueKeyPolicy = map[string]interface{}{"uniqueKeys": uniqueKeys}
 	}
+	if s.indexing.isSpecified() {
+		spec.IndexingPolicy = defaultIndexingPolicy()
+		s.indexing.apply(spec.IndexingPolicy)
+	}
+	if s.lwwPath != "" {
+		spec.ConflictResolutionPolicy = LwwConflictResolutionPolicy(s.lwwPath)
+	} else if s.sproc != "" {
+		spec.ConflictResolutionPolicy = CustomConflictResolutionPolicy(s.dbName, s.collName, s.sproc)
+	}
 
 	restResult := s.conn.restClient.CreateCollection(spec)
 	ignoreErrorCode := 0
@@ -129,23 +238,39 @@ func (s *StmtCreateCollection) Exec(_ []driver.Value) (driver.Result, error) {
 //
 // Syntax:
 //
-//	ALTER COLLECTION|TABLE [<db-name>.]<collection-name> WITH RU|MAXRU=<ru>
+//	ALTER COLLECTION|TABLE [<db-name>.]<collection-name>
+//	[WITH RU|MAXRU=<ru>|MIGRATE_TO=AUTOSCALE|MANUAL]
+//	[[,] WITH INDEXING=consistent|none]
+//	[[,] WITH INCLUDE=/path1/?,/path2/*]
+//	[[,] WITH EXCLUDE=/path3/?,/path4/*]
+//	[[,] WITH COMPOSITE=/path1 ASC,/path2 DESC;/path3,/path4]
+//	[[,] WITH SPATIAL=/path5/*,/path6/*]
+//	[[,] WITH TTL=<seconds>|-1|OFF]
 //
 // - ru: an integer specifying CosmosDB's collection throughput expressed in RU/s. Supply either RU or MAXRU, not both!
 //
+// - (since v1.2.0) Indexing options (see StmtCreateCollection) change the indexing policy of the collection: each
+// specified setting replaces the current one, other settings are kept.
+//
+// - (since v1.2.0) TTL changes the default time-to-live of documents (see StmtCreateCollection).
+//
+// - (since v1.2.0) MIGRATE_TO switches the throughput provisioning to autoscale or manual, the new throughput is
+// computed by Cosmos DB from the current one. It cannot be combined with RU or MAXRU.
+//
+// - At least one of throughput, indexing or TTL options must be specified.
+//
 // Available since v0.1.1
 type StmtAlterCollection struct {
 	*Stmt
 	dbName    string
 	collName  string // collection name
 	ru, maxru int
+	indexing  indexingOpts // indexing options
+	ttl       *int         // new default time-to-live of documents in seconds (0 means off), nil if not specified
+	migrateTo string       // AUTOSCALE or MANUAL, empty if not specified
 }
 
-func (s *StmtAlterCollection) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+func (s *StmtAlterCollection) parse() error {
 	for k, v := range s.withOpts {
 		switch k {
 		case "RU":
@@ -160,8 +285,24 @@ func (s *StmtAlterCollection) parse(withOptsStr string) error {
 				return fmt.Errorf("invalid MAXRU value: %s", v)
 			}
 			s.maxru = int(maxru)
+		case "TTL":
+			ttl, err := _parseTtl(v, true)
+			if err != nil {
+				return err
+			}
+			s.ttl = &ttl
+		case "MIGRATE_TO":
+			migrateTo, err := _parseMigrateTo(v)
+			if err != nil {
+				return err
+			}
+			s.migrateTo = migrateTo
 		default:
-			return fmt.Errorf("invalid query, parsing error at WITH %s=%s", k, v)
+			if ok, err := s.indexing.parseOpt(k, v); err != nil {
+				return err
+			} else if !ok {
+				return fmt.Errorf("invalid query, parsing error at WITH %s=%s", k, v)
+			}
 		}
 	}
 
@@ -169,13 +310,16 @@ func (s *StmtAlterCollection) parse(withOptsStr string) error {
 }
 
 func (s *StmtAlterCollection) validate() error {
-	if (s.ru <= 0 && s.maxru <= 0) || (s.ru > 0 && s.maxru > 0) {
+	if s.migrateTo != "" && (s.ru > 0 || s.maxru > 0) {
+		return errors.New("MIGRATE_TO cannot be combined with RU or MAXRU")
+	}
+	if (s.ru <= 0 && s.maxru <= 0 && s.migrateTo == "" && !s.indexing.isSpecified() && s.ttl == nil) || (s.ru > 0 && s.maxru > 0) {
 		return errors.New("only one of RU or MAXRU should be specified")
 	}
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
-	return nil
+	return s.indexing.validate()
 }
 
 // Query implements driver.Stmt/Query.
@@ -186,6 +330,21 @@ func (s *StmtAlterCollection) Query(_ []driver.Value) (driver.Rows, error) {
 
 // Exec implements driver.Stmt/Exec.
 func (s *StmtAlterCollection) Exec(_ []driver.Value) (driver.Result, error) {
+	if s.indexing.isSpecified() || s.ttl != nil {
+		result, err := _modifyCollection(s.conn, s.dbName, s.collName, func(spec *CollectionSpec) (bool, error) {
+			s.indexing.apply(spec.IndexingPolicy)
+			if s.ttl != nil {
+				spec.DefaultTtl = *s.ttl
+			}
+			return true, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if s.ru <= 0 && s.maxru <= 0 && s.migrateTo == "" {
+			return result, nil
+		}
+	}
 	getResult := s.conn.restClient.GetCollection(s.dbName, s.collName)
 	if err := getResult.Error(); err != nil {
 		switch getResult.StatusCode {
@@ -196,9 +355,7 @@ func (s *StmtAlterCollection) Exec(_ []driver.Value) (driver.Result, error) {
 		}
 		return nil, err
 	}
-	restResult := s.conn.restClient.ReplaceOfferForResource(getResult.Rid, s.ru, s.maxru)
-	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
-	return result, result.err
+	return _replaceOffer(s.conn, getResult.Rid, s.ru, s.maxru, s.migrateTo)
 }
 
 /*----------------------------------------------------------------------*/
@@ -233,6 +390,7 @@ func (s *StmtDropCollection) Query(_ []driver.Value) (driver.Rows, error) {
 // Exec implements driver.Stmt/Exec.
 func (s *StmtDropCollection) Exec(_ []driver.Value) (driver.Result, error) {
 	restResult := s.conn.restClient.DeleteCollection(s.dbName, s.collName)
+	s.conn.sessions.Remove(s.dbName, s.collName)
 	ignoreErrorCode := 0
 	if s.ifExists {
 		ignoreErrorCode = 404
@@ -271,7 +429,7 @@ func (s *StmtListCollections) Query(_ []driver.Value) (driver.Rows, error) {
 	restResult := s.conn.restClient.ListCollections(s.dbName)
 	result := &ResultResultSet{
 		err:        restResult.Error(),
-		columnList: []string{"id", "indexingPolicy", "_rid", "_ts", "_self", "_etag", "_docs", "_sprocs", "_triggers", "_udfs", "_conflicts"},
+		columnList: []string{"id", "indexingPolicy", "defaultTtl", "_rid", "_ts", "_self", "_etag", "_docs", "_sprocs", "_triggers", "_udfs", "_conflicts"},
 	}
 	if result.err == nil {
 		result.count = len(restResult.Collections)
@@ -288,3 +446,211 @@ func (s *StmtListCollections) Query(_ []driver.Value) (driver.Rows, error) {
 	}
 	return result, result.err
 }
+
+/*----------------------------------------------------------------------*/
+
+// StmtDescribeCollection implements "DESCRIBE COLLECTION" statement.
+//
+// Syntax:
+//
+//	DESCRIBE COLLECTION|TABLE [<db-name>.]<collection-name>
+//
+// The statement returns a single row with columns: id, partitionKey, indexingPolicy, uniqueKeyPolicy, defaultTtl
+// (0 if time-to-live is off), conflictResolutionPolicy, geospatialConfig, _rid, _ts, _self and _etag.
+//
+// @Available since v1.2.0
+type StmtDescribeCollection struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtDescribeCollection) String() string {
+	return fmt.Sprintf(`StmtDescribeCollection{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtDescribeCollection) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtDescribeCollection) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtDescribeCollection) Query(_ []driver.Value) (driver.Rows, error) {
+	restResult := s.conn.restClient.GetCollection(s.dbName, s.collName)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	row := DocInfo{
+		"id":                       restResult.Id,
+		"partitionKey":             restResult.PartitionKey,
+		"indexingPolicy":           restResult.IndexingPolicy,
+		"uniqueKeyPolicy":          restResult.UniqueKeyPolicy,
+		"defaultTtl":               restResult.DefaultTtl,
+		"conflictResolutionPolicy": restResult.ConflictResolutionPolicy,
+		"geospatialConfig":         restResult.GeospatialConfig,
+		"_rid":                     restResult.Rid,
+		"_ts":                      restResult.Ts,
+		"_self":                    restResult.Self,
+		"_etag":                    restResult.Etag,
+	}
+	return (&ResultResultSet{rows: []DocInfo{row}}).init(), nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtShowThroughput implements "SHOW THROUGHPUT" statement.
+//
+// Syntax:
+//
+//	SHOW THROUGHPUT FOR <db-name>[.<collection-name>]
+//
+// - SHOW THROUGHPUT FOR <db-name> reports the throughput provisioned for the database (shared by its collections),
+// SHOW THROUGHPUT FOR <db-name>.<collection-name> reports the throughput provisioned for the collection.
+//
+// - The statement returns a single row with columns: id (id of the offer), resource (name of the database or collection),
+// resourceType ("database" or "collection"), throughput (current provisioned RU/s), autopilot (true if autoscale is
+// enabled), maxThroughput (maximum RU/s of autoscale, 0 if autoscale is not enabled), maxThroughputEverProvisioned,
+// offerVersion and replacePending (true while a change of throughput, e.g. ALTER ... WITH MIGRATE_TO, is in progress).
+//
+// - ErrNotFound is returned if the database/collection does not exist, or if it has no dedicated throughput (e.g. a
+// collection sharing its database's throughput, or a serverless account).
+//
+// @Available since v1.2.0
+type StmtShowThroughput struct {
+	*Stmt
+	dbName   string
+	collName string // empty to show the database's throughput
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtShowThroughput) String() string {
+	return fmt.Sprintf(`StmtShowThroughput{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtShowThroughput) validate() error {
+	if s.dbName == "" {
+		return errors.New("database is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtShowThroughput) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtShowThroughput) Query(_ []driver.Value) (driver.Rows, error) {
+	var rid, resource, resourceType string
+	if s.collName == "" {
+		getResult := s.conn.restClient.GetDatabase(s.dbName)
+		if err := getResult.Error(); err != nil {
+			return nil, normalizeError(getResult.StatusCode, 0, err)
+		}
+		rid, resource, resourceType = getResult.Rid, s.dbName, "database"
+	} else {
+		getResult := s.conn.restClient.GetCollection(s.dbName, s.collName)
+		if err := getResult.Error(); err != nil {
+			return nil, normalizeError(getResult.StatusCode, 0, err)
+		}
+		rid, resource, resourceType = getResult.Rid, s.dbName+"."+s.collName, "collection"
+	}
+	offerResult := s.conn.restClient.GetOfferForResource(rid)
+	if err := offerResult.Error(); err != nil {
+		return nil, normalizeError(offerResult.StatusCode, 0, err)
+	}
+	// re-read the offer by its rid: only this request reports whether a replacement of the offer is pending
+	restResult := s.conn.restClient.GetOffer(offerResult.Rid)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	row := DocInfo{
+		"id":                           restResult.Id,
+		"resource":                     resource,
+		"resourceType":                 resourceType,
+		"throughput":                   restResult.OfferThroughput(),
+		"autopilot":                    restResult.IsAutopilot(),
+		"maxThroughput":                restResult.AutopilotMaxThroughput(),
+		"maxThroughputEverProvisioned": restResult.MaxThroughputEverProvisioned(),
+		"offerVersion":                 restResult.OfferVersion,
+		"replacePending":               restResult.ReplacePending,
+	}
+	return (&ResultResultSet{rows: []DocInfo{row}}).init(), nil
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtShowPartitions implements "SHOW PARTITIONS" statement, which lists the partition key ranges of a collection.
+//
+// Syntax:
+//
+//	SHOW PARTITIONS FOR [<db-name>.]<collection-name>
+//
+// The statement returns a row per partition key range, with columns: id, minInclusive, maxExclusive, parents (ids of
+// the ranges it was split from), _rid, _ts, _self and _etag.
+//
+// @Available since v1.2.0
+type StmtShowPartitions struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtShowPartitions) String() string {
+	return fmt.Sprintf(`StmtShowPartitions{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtShowPartitions) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtShowPartitions) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtShowPartitions) Query(_ []driver.Value) (driver.Rows, error) {
+	restResult := s.conn.restClient.GetPkranges(s.dbName, s.collName)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	rows := make([]DocInfo, len(restResult.Pkranges))
+	for i, pkrange := range restResult.Pkranges {
+		parents := pkrange.Parents
+		if parents == nil {
+			parents = []string{}
+		}
+		rows[i] = DocInfo{
+			"id":           pkrange.Id,
+			"minInclusive": pkrange.MinInclusive,
+			"maxExclusive": pkrange.MaxExclusive,
+			"parents":      parents,
+			"_rid":         pkrange.Rid,
+			"_ts":          pkrange.Ts,
+			"_self":        pkrange.Self,
+			"_etag":        pkrange.Etag,
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, _ := strconv.Atoi(rows[i]["id"].(string))
+		b, _ := strconv.Atoi(rows[j]["id"].(string))
+		return a < b
+	})
+	return (&ResultResultSet{rows: rows}).init(), nil
+}
@@ -25,12 +25,31 @@ func TestStmtCreateCollection_parse(t *testing.T) {
 		{name: "error_no_collection", sql: "CREATE TABLE db WITH Pk=/id", mustError: true},
 		{name: "error_if_not_exist", sql: "CREATE TABLE IF NOT EXIST db.table WITH Pk=/id", mustError: true},
 		{name: "error_invalid_with", sql: "CREATE TABLE db.table WITH Pk=/id, WITH a=1", mustError: true},
+		{name: "error_invalid_indexing_mode", sql: "CREATE TABLE db.table WITH Pk=/id WITH INDEXING=lazy", mustError: true},
+		{name: "error_indexing_none_with_paths", sql: "CREATE TABLE db.table WITH Pk=/id WITH INDEXING=none WITH INCLUDE=/*", mustError: true},
+		{name: "error_invalid_include", sql: "CREATE TABLE db.table WITH Pk=/id WITH INCLUDE=name", mustError: true},
+		{name: "error_composite_single_path", sql: "CREATE TABLE db.table WITH Pk=/id WITH COMPOSITE=/a,/b;/c", mustError: true},
+		{name: "error_composite_invalid_order", sql: "CREATE TABLE db.table WITH Pk=/id WITH COMPOSITE=/a UP,/b", mustError: true},
+		{name: "error_invalid_ttl", sql: "CREATE TABLE db.table WITH Pk=/id WITH TTL=0", mustError: true},
+		{name: "error_invalid_ttl2", sql: "CREATE TABLE db.table WITH Pk=/id WITH TTL=never", mustError: true},
+		{name: "error_invalid_conflict_path", sql: "CREATE TABLE db.table WITH Pk=/id WITH CONFLICT_PATH=_ts", mustError: true},
+		{name: "error_conflict_path_and_sproc", sql: "CREATE TABLE db.table WITH Pk=/id WITH CONFLICT_PATH=/_ts WITH CONFLICT_SPROC=resolver", mustError: true},
 
 		{name: "basic", sql: "CREATE COLLECTION db1.table1 WITH pk=/id", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id"}},
 		{name: "table_with_ru", sql: "create\ntable\rdb-2.table_2 WITH\tPK=/email WITH\r\nru=100", expected: &StmtCreateCollection{dbName: "db-2", collName: "table_2", pk: "/email", ru: 100}},
 		{name: "if_not_exists_large_pk_with_maxru", sql: "CREATE collection\nIF\rNOT\t\nEXISTS\n\tdb_3.table-3 with largePK=/id WITH\t\rmaxru=100", expected: &StmtCreateCollection{dbName: "db_3", collName: "table-3", ifNotExists: true, pk: "/id", maxru: 100}},
 		{name: "table_if_not_exists_large_pk_with_uk", sql: "create TABLE if not exists db-0_1.table_0-1 WITH LARGEpk=/a/b/c with uk=/a:/b,/c/d;/e/f/g", expected: &StmtCreateCollection{dbName: "db-0_1", collName: "table_0-1", ifNotExists: true, pk: "/a/b/c", uk: [][]string{{"/a"}, {"/b", "/c/d"}, {"/e/f/g"}}}},
 		{name: "subpartitions", sql: "CREATE COLLECTION db1.table1 WITH pK=/TenantId,/UserId,/SessionId", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/TenantId,/UserId,/SessionId"}},
+		{name: "ttl", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH TTL=3600", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id", ttl: 3600}},
+		{name: "ttl_no_default_expiry", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH ttl=-1", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id", ttl: -1}},
+		{name: "ttl_off", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH ttl=off", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id"}},
+		{name: "conflict_path", sql: "CREATE COLLECTION db1.table1 WITH pk=/id, WITH conflict_path=/version", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id", lwwPath: "/version"}},
+		{name: "conflict_sproc", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH CONFLICT_SPROC=resolver", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id", sproc: "resolver"}},
+		{name: "indexing_none", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH indexing=None", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id", indexing: indexingOpts{mode: "none"}}},
+		{name: "indexing_paths", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH INDEXING=consistent WITH include=/name/?, /tags/[]/? WITH exclude=/*", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id",
+			indexing: indexingOpts{mode: "consistent", include: []string{"/name/?", "/tags/[]/?"}, exclude: []string{"/*"}}}},
+		{name: "indexing_composite_spatial", sql: "CREATE COLLECTION db1.table1 WITH pk=/id WITH composite=/name ASC,/age DESC;/a,/b WITH spatial=/location/*", expected: &StmtCreateCollection{dbName: "db1", collName: "table1", pk: "/id",
+			indexing: indexingOpts{composite: [][]indexPath{{{"/name", "ascending"}, {"/age", "descending"}}, {{"/a", "ascending"}, {"/b", "ascending"}}}, spatial: []string{"/location/*"}}}},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -110,12 +129,22 @@ func TestStmtAlterCollection_parse(t *testing.T) {
 		{name: "error_no_db", sql: "ALTER collection coll WITH ru=400", mustError: true},
 		{name: "error_invalid_query", sql: "ALTER collection .coll WITH maxru=4000", mustError: true},
 		{"error_ru_and_maxru", "alter TABLE db.coll WITH ru=400 WITH maxru=4000", nil, true},
+		{name: "error_indexing_none_with_paths", sql: "ALTER TABLE db.coll WITH INDEXING=none WITH COMPOSITE=/a,/b", mustError: true},
+		{name: "error_invalid_ttl", sql: "ALTER TABLE db.coll WITH TTL=-5", mustError: true},
 		{name: "error_invalid_ru", sql: "alter TABLE db.coll WITH ru=-1", mustError: true},
 		{name: "error_invalid_maxru", sql: "alter TABLE db.coll WITH maxru=-1", mustError: true},
 		{name: "error_invalid_with", sql: "alter TABLE db.coll WITH ru=400, WITH a=1", mustError: true},
+		{name: "error_invalid_migrate_to", sql: "alter TABLE db.coll WITH MIGRATE_TO=provisioned", mustError: true},
+		{name: "error_migrate_to_and_maxru", sql: "alter TABLE db.coll WITH MIGRATE_TO=AUTOSCALE WITH maxru=4000", mustError: true},
 
 		{name: "basic", sql: "ALTER collection db1.table1 WITH ru=400", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", ru: 400}},
 		{name: "table", sql: "alter\nTABLE\rdb-2.table_2 WITH\tmaxru=40000", expected: &StmtAlterCollection{dbName: "db-2", collName: "table_2", maxru: 40000}},
+		{name: "indexing_only", sql: "ALTER COLLECTION db1.table1 WITH exclude=/large/*", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", indexing: indexingOpts{exclude: []string{"/large/*"}}}},
+		{name: "ttl", sql: "ALTER COLLECTION db1.table1 WITH TTL=60", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", ttl: _intPtr(60)}},
+		{name: "ttl_off", sql: "ALTER COLLECTION db1.table1 WITH ru=400 WITH TTL=OFF", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", ru: 400, ttl: _intPtr(0)}},
+		{name: "migrate_to", sql: "ALTER COLLECTION db1.table1 WITH MIGRATE_TO=Autoscale", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", migrateTo: "AUTOSCALE"}},
+		{name: "migrate_to_and_ttl", sql: "ALTER TABLE db1.table1 WITH MIGRATE_TO=manual, WITH TTL=-1", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", migrateTo: "MANUAL", ttl: _intPtr(-1)}},
+		{name: "indexing_and_ru", sql: "ALTER COLLECTION db1.table1 WITH ru=400 WITH spatial=/loc/*", expected: &StmtAlterCollection{dbName: "db1", collName: "table1", ru: 400, indexing: indexingOpts{spatial: []string{"/loc/*"}}}},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -350,3 +379,130 @@ func TestStmtListCollections_parse_defaultDb(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtDescribeCollection_parse(t *testing.T) {
+	testName := "TestStmtDescribeCollection_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtDescribeCollection
+		mustError bool
+	}{
+		{name: "error_no_db", sql: "DESCRIBE COLLECTION coll", mustError: true},
+		{name: "error_no_collection", sql: "DESCRIBE COLLECTION db.", mustError: true},
+		{name: "error_database", sql: "DESCRIBE DATABASE db", mustError: true},
+		{name: "error_trailing_tokens", sql: "DESCRIBE COLLECTION db.coll WITH a=1", mustError: true},
+
+		{name: "basic", sql: "DESCRIBE COLLECTION db1.table1", expected: &StmtDescribeCollection{dbName: "db1", collName: "table1"}},
+		{name: "table_default_db", db: "mydb", sql: "describe\ntable table1;", expected: &StmtDescribeCollection{dbName: "mydb", collName: "table1"}},
+		{name: "db_in_query", db: "mydb", sql: "DESCRIBE TABLE db2.table2", expected: &StmtDescribeCollection{dbName: "db2", collName: "table2"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtDescribeCollection)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtDescribeCollection but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtShowThroughput_parse(t *testing.T) {
+	testName := "TestStmtShowThroughput_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtShowThroughput
+		mustError bool
+	}{
+		{name: "error_no_for", sql: "SHOW THROUGHPUT db", mustError: true},
+		{name: "error_no_name", sql: "SHOW THROUGHPUT FOR", mustError: true},
+		{name: "error_trailing_tokens", sql: "SHOW THROUGHPUT FOR db.coll x", mustError: true},
+
+		{name: "database", sql: "SHOW THROUGHPUT FOR db1", expected: &StmtShowThroughput{dbName: "db1"}},
+		{name: "database_ignore_default_db", db: "mydb", sql: "show throughput for db1", expected: &StmtShowThroughput{dbName: "db1"}},
+		{name: "collection", db: "mydb", sql: "SHOW THROUGHPUT FOR db1.table1", expected: &StmtShowThroughput{dbName: "db1", collName: "table1"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtShowThroughput)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtShowThroughput but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtShowPartitions_parse(t *testing.T) {
+	testName := "TestStmtShowPartitions_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtShowPartitions
+		mustError bool
+	}{
+		{name: "error_no_db", sql: "SHOW PARTITIONS FOR coll", mustError: true},
+		{name: "error_no_for", sql: "SHOW PARTITIONS db.coll", mustError: true},
+		{name: "error_unknown", sql: "SHOW TABLES", mustError: true},
+
+		{name: "basic", sql: "SHOW PARTITIONS FOR db1.table1", expected: &StmtShowPartitions{dbName: "db1", collName: "table1"}},
+		{name: "default_db", db: "mydb", sql: "show partitions for table1", expected: &StmtShowPartitions{dbName: "mydb", collName: "table1"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtShowPartitions)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtShowPartitions but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func _intPtr(v int) *int {
+	return &v
+}
@@ -5,8 +5,39 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
+const (
+	migrateToAutoscale = "AUTOSCALE"
+	migrateToManual    = "MANUAL"
+)
+
+// _parseMigrateTo parses the value of option MIGRATE_TO: AUTOSCALE or MANUAL (case-insensitive).
+func _parseMigrateTo(v string) (string, error) {
+	switch mode := strings.ToUpper(v); mode {
+	case migrateToAutoscale, migrateToManual:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid MIGRATE_TO value: %s (expect AUTOSCALE or MANUAL)", v)
+}
+
+// _replaceOffer changes the throughput of a resource (database or collection): migrates it to autoscale or manual
+// provisioning if migrateTo is specified, otherwise sets its RU or MAXRU.
+func _replaceOffer(conn *Conn, rid string, ru, maxru int, migrateTo string) (driver.Result, error) {
+	var restResult *RespReplaceOffer
+	switch migrateTo {
+	case migrateToAutoscale:
+		restResult = conn.restClient.MigrateOfferToAutoscale(rid)
+	case migrateToManual:
+		restResult = conn.restClient.MigrateOfferToManual(rid)
+	default:
+		restResult = conn.restClient.ReplaceOfferForResource(rid, ru, maxru)
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
+	return result, result.err
+}
+
 // StmtCreateDatabase implements "CREATE DATABASE" statement.
 //
 // Syntax:
@@ -23,11 +54,7 @@ type StmtCreateDatabase struct {
 	ru, maxru   int
 }
 
-func (s *StmtCreateDatabase) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+func (s *StmtCreateDatabase) parse() error {
 	for k, v := range s.withOpts {
 		switch k {
 		case "RU":
@@ -80,22 +107,22 @@ func (s *StmtCreateDatabase) Exec(_ []driver.Value) (driver.Result, error) {
 //
 // Syntax:
 //
-//	ALTER DATABASE <db-name> WITH RU|MAXRU=<ru>
+//	ALTER DATABASE <db-name> WITH RU|MAXRU=<ru>|MIGRATE_TO=AUTOSCALE|MANUAL
 //
 // - ru: an integer specifying CosmosDB's database throughput expressed in RU/s. Supply either RU or MAXRU, not both!
 //
+// - (since v1.2.0) MIGRATE_TO switches the throughput provisioning to autoscale or manual, the new throughput is
+// computed by Cosmos DB from the current one. It cannot be combined with RU or MAXRU.
+//
 // Available since v0.1.1
 type StmtAlterDatabase struct {
 	*Stmt
 	dbName    string
 	ru, maxru int
+	migrateTo string // AUTOSCALE or MANUAL, empty if not specified
 }
 
-func (s *StmtAlterDatabase) parse(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+func (s *StmtAlterDatabase) parse() error {
 	for k, v := range s.withOpts {
 		switch k {
 		case "RU":
@@ -110,6 +137,12 @@ func (s *StmtAlterDatabase) parse(withOptsStr string) error {
 				return fmt.Errorf("invalid RU value: %s", v)
 			}
 			s.maxru = int(maxru)
+		case "MIGRATE_TO":
+			migrateTo, err := _parseMigrateTo(v)
+			if err != nil {
+				return err
+			}
+			s.migrateTo = migrateTo
 		default:
 			return fmt.Errorf("invalid query, parsing error at WITH %s=%s", k, v)
 		}
@@ -119,6 +152,12 @@ func (s *StmtAlterDatabase) parse(withOptsStr string) error {
 }
 
 func (s *StmtAlterDatabase) validate() error {
+	if s.migrateTo != "" {
+		if s.ru > 0 || s.maxru > 0 {
+			return errors.New("MIGRATE_TO cannot be combined with RU or MAXRU")
+		}
+		return nil
+	}
 	if (s.ru <= 0 && s.maxru <= 0) || (s.ru > 0 && s.maxru > 0) {
 		return errors.New("only one of RU or MAXRU must be specified")
 	}
@@ -143,9 +182,7 @@ func (s *StmtAlterDatabase) Exec(_ []driver.Value) (driver.Result, error) {
 		}
 		return nil, err
 	}
-	restResult := s.conn.restClient.ReplaceOfferForResource(getResult.Rid, s.ru, s.maxru)
-	result := buildResultNoResultSet(&restResult.RestResponse, true, restResult.Rid, 0)
-	return result, result.err
+	return _replaceOffer(s.conn, getResult.Rid, s.ru, s.maxru, s.migrateTo)
 }
 
 /*----------------------------------------------------------------------*/
@@ -225,3 +262,49 @@ func (s *StmtListDatabases) Query(_ []driver.Value) (driver.Rows, error) {
 	}
 	return result, result.err
 }
+
+/*----------------------------------------------------------------------*/
+
+// StmtUse implements "USE" statement, which changes the default database of the connection.
+//
+// Syntax:
+//
+//	USE <db-name>
+//
+// - The database must exist, ErrNotFound is returned otherwise. Statements prepared after USE on the same connection
+// use the new default database; the default database specified in the DSN is restored when the connection is returned
+// to the pool, hence USE should be executed on a pinned connection (sql.Conn).
+//
+// @Available since v1.2.0
+type StmtUse struct {
+	*Stmt
+	dbName string
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtUse) String() string {
+	return fmt.Sprintf(`StmtUse{Stmt: %s, db: %q}`, s.Stmt, s.dbName)
+}
+
+func (s *StmtUse) validate() error {
+	if s.dbName == "" {
+		return errors.New("database is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtUse) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtUse) Exec(_ []driver.Value) (driver.Result, error) {
+	restResult := s.conn.restClient.GetDatabase(s.dbName)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	s.conn.defaultDb = s.dbName
+	return &ResultNoResultSet{}, nil
+}
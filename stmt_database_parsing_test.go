@@ -65,9 +65,13 @@ func TestStmtAlterDatabase_parse(t *testing.T) {
 		{name: "error_ru_and_maxru", sql: "ALTER database db0 WITH RU=400, WITH maxRU=4000", mustError: true},
 		{name: "error_invalid_with", sql: "ALTER database db0 WITH RU=400, WITH a", mustError: true},
 		{name: "error_invalid_with2", sql: "ALTER database db0 WITH RU=400 WITH a=1", mustError: true},
+		{name: "error_invalid_migrate_to", sql: "ALTER database db0 WITH MIGRATE_TO=serverless", mustError: true},
+		{name: "error_migrate_to_and_ru", sql: "ALTER database db0 WITH MIGRATE_TO=manual WITH RU=400", mustError: true},
 
 		{name: "with_ru", sql: "ALTER\rdatabase\ndb1\tWITH ru=400", expected: &StmtAlterDatabase{dbName: "db1", ru: 400}},
 		{name: "with_maxru", sql: "alter DATABASE db-1 with maxru=4000", expected: &StmtAlterDatabase{dbName: "db-1", maxru: 4000}},
+		{name: "migrate_to_autoscale", sql: "ALTER DATABASE db1 WITH MIGRATE_TO=autoscale", expected: &StmtAlterDatabase{dbName: "db1", migrateTo: "AUTOSCALE"}},
+		{name: "migrate_to_manual", sql: "ALTER DATABASE db1 WITH migrate_to=MANUAL", expected: &StmtAlterDatabase{dbName: "db1", migrateTo: "MANUAL"}},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -167,3 +171,42 @@ func TestStmtListDatabases_parse(t *testing.T) {
 		})
 	}
 }
+
+func TestStmtUse_parse(t *testing.T) {
+	testName := "TestStmtUse_parse"
+	testData := []struct {
+		name      string
+		sql       string
+		expected  *StmtUse
+		mustError bool
+	}{
+		{name: "error_no_db", sql: "USE", mustError: true},
+		{name: "error_qualified_name", sql: "USE db.coll", mustError: true},
+		{name: "error_with", sql: "USE db WITH ru=400", mustError: true},
+
+		{name: "basic", sql: "USE db1", expected: &StmtUse{dbName: "db1"}},
+		{name: "quoted", sql: "use\n`my db`;", expected: &StmtUse{dbName: "my db"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "mydb", testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtUse)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtUse but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
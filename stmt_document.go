@@ -1,151 +1,46 @@
 package gocosmos
 
 import (
+	"bytes"
+	"context"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/btnguyen2k/consu/g18"
 	"github.com/btnguyen2k/consu/reddo"
+	"math"
+	"net/http"
 	"os"
+	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
-)
-
-var (
-	reValNull          = regexp.MustCompile(`(?i)(null)\s*?`)
-	reValNumber        = regexp.MustCompile(`([\d\.xe+-]+)\s*?`)
-	reValBoolean       = regexp.MustCompile(`(?i)(true|false)\s*?`)
-	reValString        = regexp.MustCompile(`(?i)("(\\"|[^"])*?")\s*?`)
-	reValPlaceholder   = regexp.MustCompile(`(?i)[$@:](\d+)\s*?`)
-	reValStringNoQuote = regexp.MustCompile(`(?i)([a-z0-9_./;:\\-]+)`)
-
-	reFieldEqual = regexp.MustCompile(field + `\s*=(.*?)`)
+	"sync/atomic"
 )
 
 type placeholder struct {
 	index int
 }
 
-func _buildLeftover(input string, reSep *regexp.Regexp) string {
-	if sep := reSep.FindString(input); sep != "" {
-		input = input[len(sep):]
-	}
-	return input
-}
-
-func _parseValue(input string, separator rune) (value interface{}, leftOver string, err error) {
-	reSep := regexp.MustCompile(`^\s*\` + string(separator) + `\s*`)
-
-	if loc := reValPlaceholder.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]+1 : loc[1]])
-		index, err := strconv.Atoi(token)
-		return placeholder{index}, _buildLeftover(input[loc[1]:], reSep), err
-	}
-
-	if loc := reValNull.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		return nil, _buildLeftover(input[loc[1]:], reSep), nil
-	}
-
-	if loc := reValNumber.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]:loc[1]])
-		var data interface{}
-		err := json.Unmarshal([]byte(token), &data)
-		if err != nil {
-			err = errors.New("(number) cannot parse query, invalid token at: " + token)
-		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
-	}
-
-	if loc := reValBoolean.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		token := strings.TrimSpace(input[loc[0]:loc[1]])
-		var data bool
-		err := json.Unmarshal([]byte(token), &data)
-		if err != nil {
-			err = errors.New("(bool) cannot parse query, invalid token at: " + token)
-		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
-	}
-
-	if loc := reValString.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		var data interface{}
-		token, err := strconv.Unquote(strings.TrimSpace(input[loc[0]:loc[1]]))
-		if err == nil {
-			err = json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(unmarshal) cannot parse query, invalid token at: " + token)
-			}
-		} else {
-			err = errors.New("(unquote) cannot parse query, invalid token at: " + token)
-		}
-		return data, _buildLeftover(input[loc[1]:], reSep), err
-	}
-	if loc := reValStringNoQuote.FindStringIndex(input); loc != nil && loc[0] == 0 {
-		data := strings.TrimSpace(input[loc[0]:loc[1]])
-		return data, _buildLeftover(input[loc[1]:], reSep), err
-	}
-
-	return nil, input, errors.New("cannot parse query, invalid token at: " + input)
+// namedPlaceholder is a placeholder referenced by name (e.g. @name), its value is supplied via sql.Named.
+type namedPlaceholder struct {
+	name string
 }
 
-func _parseFieldValueAnd(input string) (field string, value interface{}, leftOver string, err error) {
-	reSep := regexp.MustCompile(`(?i)^\s*and\s+`)
-
-	//must star with <field>\s*=
-	if loc := reFieldEqual.FindStringSubmatchIndex(input); loc != nil && loc[0] == 0 {
-		field = input[loc[2]:loc[3]]
-		input = strings.TrimSpace(input[loc[1]:])
-
-		if loc := reValPlaceholder.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]+1 : loc[1]])
-			index, err := strconv.Atoi(token)
-			return field, placeholder{index}, _buildLeftover(input[loc[1]:], reSep), err
-		}
-
-		if loc := reValNull.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			return field, nil, _buildLeftover(input[loc[1]:], reSep), nil
-		}
-
-		if loc := reValNumber.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]:loc[1]])
-			var data interface{}
-			err := json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(number) cannot parse query, invalid token at: " + token)
-			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
-		}
-
-		if loc := reValBoolean.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			token := strings.TrimSpace(input[loc[0]:loc[1]])
-			var data bool
-			err := json.Unmarshal([]byte(token), &data)
-			if err != nil {
-				err = errors.New("(bool) cannot parse query, invalid token at: " + token)
-			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
-		}
-
-		if loc := reValString.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			var data interface{}
-			token, err := strconv.Unquote(strings.TrimSpace(input[loc[0]:loc[1]]))
-			if err == nil {
-				err = json.Unmarshal([]byte(token), &data)
-				if err != nil {
-					err = errors.New("(unmarshal) cannot parse query, invalid token at: " + token)
-				}
-			} else {
-				err = errors.New("(unquote) cannot parse query, invalid token at: " + token)
-			}
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
-		}
-		if loc := reValStringNoQuote.FindStringIndex(input); loc != nil && loc[0] == 0 {
-			data := strings.TrimSpace(input[loc[0]:loc[1]])
-			return field, data, _buildLeftover(input[loc[1]:], reSep), err
+// _findNamedPlaceholders returns names of named placeholders (e.g. @name) found in the input, string literals are skipped.
+// Each name is returned once, in order of first appearance.
+func _findNamedPlaceholders(input string) []string {
+	result := make([]string, 0)
+	tokens, _ := _tokenize(input)
+	found := make(map[string]bool)
+	for _, token := range tokens {
+		if token.kind == tokNamedPlaceholder && !found[token.value] {
+			found[token.value] = true
+			result = append(result, token.value)
 		}
 	}
-	return "", nil, input, errors.New("cannot parse query, invalid token at: " + input)
+	return result
 }
 
 // StmtCRUD is abstract implementation of "INSERT|UPSERT|UPDATE|DELETE|SELECT" operations.
@@ -158,7 +53,13 @@ type StmtCRUD struct {
 	isSinglePathPk bool
 	withPk         string
 	pkPaths        []string
-	numPkPaths     int // number of PK paths
+	numPkPaths     int            // number of PK paths
+	predicate      string         // Cosmos DB SQL predicate of the WHERE clause (multi-document statements)
+	placeholders   map[int]string // positional placeholders of the predicate, rewritten to @_N parameters
+	maxRows        int            // maximum number of documents a multi-document statement is allowed to affect
+	returning      []string       // fields of the RETURNING clause ("*" means all fields), nil if there is no RETURNING clause
+	etag           interface{}    // ETag condition (If-Match), specified via "AND _etag=<value>" or "WITH IF_MATCH=<value>"
+	ttl            int            // time-to-live in seconds of the written documents, specified via "WITH TTL=<seconds>|-1"
 }
 
 // String implements interface fmt.Stringer/String.
@@ -182,11 +83,8 @@ func (s *StmtCRUD) fetchPkInfo() error {
 	return normalizeError(getCollResult.StatusCode, 0, getCollResult.Error())
 }
 
-func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
-	if err := s.Stmt.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+// parseWithOpts extracts the WITH options common to INSERT/UPSERT/UPDATE/DELETE statements.
+func (s *StmtCRUD) parseWithOpts() error {
 	if err := s.onlyOneWithOption("single PK path is specified more than once, only one of SINGLE_PK or SINGLEPK should be specified", "SINGLE_PK", "SINGLEPK"); err != nil {
 		return err
 	}
@@ -211,6 +109,12 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 			}
 		case "PK":
 			s.withPk = "/" + strings.TrimLeft(v, "/")
+		case "MAX_ROWS":
+			maxRows, err := strconv.Atoi(v)
+			if err != nil || maxRows <= 0 {
+				return fmt.Errorf("invalid value at WITH %s (expect a positive integer)", k)
+			}
+			s.maxRows = maxRows
 		}
 	}
 
@@ -220,22 +124,244 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 	} else if s.isSinglePathPk {
 		s.numPkPaths = 1
 	}
-	s.numInputs = 0
 	return nil
 }
 
+// parsePredicate parses the WHERE clause of a multi-document statement as a Cosmos DB SQL predicate.
+// Documents are referred to via alias "c" in the predicate (e.g. c.createdAt < :1).
+func (s *StmtCRUD) parsePredicate(whereStr string) error {
+	var err error
+	if s.predicate, s.placeholders, err = _rewritePlaceholders(strings.TrimSpace(whereStr)); err != nil {
+		return err
+	}
+	for index := range s.placeholders {
+		s.trackPlaceholder(placeholder{index})
+	}
+	for _, name := range _findNamedPlaceholders(s.predicate) {
+		s.trackPlaceholder(namedPlaceholder{name})
+	}
+	return nil
+}
+
+// queryMatchingDocs executes the WHERE predicate as a cross-partition query and returns the matching documents.
+//
+// If WITH MAX_ROWS is specified, an error is returned if more than MAX_ROWS documents match.
+func (s *StmtCRUD) queryMatchingDocs(args *stmtArgs) ([]DocInfo, error) {
+	params := make([]interface{}, 0)
+	for index, name := range s.placeholders {
+		params = append(params, map[string]interface{}{"name": name, "value": args.positional[index-1]})
+	}
+	for _, name := range _findNamedPlaceholders(s.predicate) {
+		params = append(params, map[string]interface{}{"name": "@" + name, "value": args.named[name]})
+	}
+	top := ""
+	if s.maxRows > 0 {
+		// fetch one more document to detect if the limit is exceeded
+		top = fmt.Sprintf("TOP %d ", s.maxRows+1)
+	}
+	query := QueryReq{
+		DbName:           s.dbName,
+		CollName:         s.collName,
+		Query:            fmt.Sprintf("SELECT %s* FROM c WHERE (%s)", top, s.predicate),
+		Params:           params,
+		ConsistencyLevel: s.consistencyLevel,
+		SessionToken:     s.readSessionToken(s.dbName, s.collName),
+	}
+	restResult := s.conn.restClient.QueryDocumentsCrossPartition(query)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	docs := restResult.Documents.AsDocInfoSlice()
+	if s.maxRows > 0 && len(docs) > s.maxRows {
+		return nil, fmt.Errorf("more than %d documents match the WHERE clause, statement is aborted (see WITH MAX_ROWS)", s.maxRows)
+	}
+	return docs, nil
+}
+
+// parseIfMatch parses the WITH IF_MATCH option, parseWithOpts must be called first.
+func (s *StmtCRUD) parseIfMatch() error {
+	v, ok := s.withOpts["IF_MATCH"]
+	if !ok {
+		return nil
+	}
+	value, err := _parseValueText(v)
+	if err != nil || value == nil {
+		return fmt.Errorf("invalid value at WITH IF_MATCH (expect a placeholder or an ETag value)")
+	}
+	s.etag = value
+	s.trackPlaceholder(value)
+	return nil
+}
+
+// parseTtl parses the WITH TTL option, parseWithOpts must be called first.
+func (s *StmtCRUD) parseTtl() error {
+	v, ok := s.withOpts["TTL"]
+	if !ok {
+		return nil
+	}
+	ttl, err := _parseTtl(v, false)
+	s.ttl = ttl
+	return err
+}
+
+// parseWherePointForm applies the conditions of a point-form WHERE clause (i.e. id=<value> [AND <pk-field>=<value>...]
+// [AND _etag=<value>]), and returns the id and the partition key values.
+func (s *StmtCRUD) parseWherePointForm(where []sqlFieldValue) (id interface{}, pkValues []interface{}, err error) {
+	s.pkPaths = make([]string, 0)
+	pkValues = make([]interface{}, 0)
+	for _, cond := range where {
+		switch {
+		case strings.ToLower(cond.field) == "id":
+			id = cond.value
+		case cond.field == "_etag":
+			if err := s.setEtagCondition(cond.value); err != nil {
+				return nil, nil, err
+			}
+		default:
+			s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(cond.field, "/"))
+			pkValues = append(pkValues, cond.value)
+		}
+		s.trackPlaceholder(cond.value)
+	}
+	if !s.isSinglePathPk {
+		s.numPkPaths = len(s.pkPaths)
+	}
+	return id, pkValues, nil
+}
+
+// setEtagCondition records the "_etag=<value>" condition of the WHERE clause.
+func (s *StmtCRUD) setEtagCondition(value interface{}) error {
+	if s.etag != nil {
+		return errors.New("ETag condition is specified more than once, only one of WITH IF_MATCH or _etag=<value> should be specified")
+	}
+	s.etag = value
+	return nil
+}
+
+// resolveEtag returns the ETag value the document must match, or empty string if there is no ETag condition.
+func (s *StmtCRUD) resolveEtag(args *stmtArgs) (string, error) {
+	if s.etag == nil {
+		return "", nil
+	}
+	etag, err := reddo.ToString(args.resolve(s.etag))
+	if err != nil || etag == "" {
+		return "", errors.New("ETag value must be a non-empty string")
+	}
+	return etag, nil
+}
+
+// buildReturningRows builds the rows of a statement with RETURNING clause from the documents attached to its result.
+//
+// Rows include system properties (e.g. _rid, _etag, _ts). With "RETURNING *" all fields are returned, otherwise the
+// rows contain only the listed fields (a nested field is specified with dotted path, e.g. address.city), in order.
+func (s *StmtCRUD) buildReturningRows(result driver.Result, err error) (driver.Rows, error) {
+	if err != nil {
+		return nil, err
+	}
+	rows := &ResultResultSet{rows: make([]DocInfo, 0)}
+	if r, ok := result.(*ResultNoResultSet); ok {
+		for _, doc := range r.docs {
+			rows.rows = append(rows.rows, _projectReturning(doc, s.returning))
+		}
+	}
+	rows.init()
+	if len(s.returning) != 1 || s.returning[0] != "*" {
+		rows.columnList = s.returning
+	}
+	return rows, nil
+}
+
+// _projectReturning extracts the fields of the RETURNING clause from a document.
+func _projectReturning(doc DocInfo, fields []string) DocInfo {
+	if len(fields) == 1 && fields[0] == "*" {
+		return doc
+	}
+	row := make(DocInfo, len(fields))
+	for _, field := range fields {
+		var value interface{} = map[string]interface{}(doc)
+		for _, name := range strings.Split(field, ".") {
+			m, _ := value.(map[string]interface{})
+			value = m[name]
+		}
+		row[field] = value
+	}
+	return row
+}
+
+// _extractPkValues extracts the partition key values of a document, following the supplied PK paths.
+// Missing values are represented as {}, which is how Cosmos DB addresses documents without partition key value.
+func _extractPkValues(doc DocInfo, pkPaths []string) []interface{} {
+	pkValues := make([]interface{}, len(pkPaths))
+	for i, pkPath := range pkPaths {
+		var value interface{} = map[string]interface{}(doc)
+		for _, name := range strings.Split(strings.TrimPrefix(pkPath, "/"), "/") {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				value = nil
+				break
+			}
+			if value, ok = m[name]; !ok {
+				value = nil
+				break
+			}
+		}
+		if value == nil {
+			value = map[string]interface{}{}
+		}
+		pkValues[i] = value
+	}
+	return pkValues
+}
+
+// _rewritePlaceholders rewrites positional placeholders (:N, @N or $N) of a Cosmos DB SQL query to parameters @_N.
+// It returns the rewritten query and the map from placeholder index to parameter name. Positional placeholders start
+// at 1, an error is returned for placeholders :0, @0 or $0.
+func _rewritePlaceholders(query string) (string, map[int]string, error) {
+	placeholders := make(map[int]string)
+	tokens, err := _tokenize(query)
+	if err != nil {
+		return query, placeholders, err
+	}
+	var sb strings.Builder
+	last := 0
+	for _, token := range tokens {
+		if token.kind != tokPlaceholder {
+			continue
+		}
+		v, _ := strconv.Atoi(token.text[1:])
+		if v < 1 {
+			return "", nil, fmt.Errorf("invalid placeholder %s, positional placeholders start at 1", token.text)
+		}
+		placeholders[v] = "@_" + token.text[1:]
+		sb.WriteString(query[last:token.start])
+		sb.WriteString(placeholders[v])
+		last = token.end
+	}
+	sb.WriteString(query[last:])
+	return sb.String(), placeholders, nil
+}
+
 // StmtInsert implements "INSERT" operation.
 //
 // Syntax:
 //
 //	INSERT|UPSERT INTO <db-name>.<collection-name>
 //	(<field-list>)
-//	VALUES (<value-list>)
+//	VALUES (<value-list>)[, (<value-list>)...]
 //	[WITH PK=/pk-path]
+//	[WITH TTL=<seconds>|-1]
 //
 //	- values are comma separated.
+//	- (since v1.2.0) multiple rows can be inserted in one statement, each row is written as a separate document
+//	  (rows are written in parallel). If some rows fail, a *BulkWriteError is returned reporting the error of each
+//	  failed row, other rows are still written. Partition key values must be included in the rows in this case.
+//	- (since v1.2.0) single-row UPSERT accepts WITH IF_MATCH=<etag-value>: the existing document is replaced only if its
+//	  ETag matches, otherwise ErrPreconditionFailure is returned.
+//	- (since v1.2.0) WITH TTL sets the time-to-live of the written documents, in seconds (-1: never expire), overriding the
+//	  collection's default time-to-live. Time-to-live must be enabled on the collection, see StmtCreateCollection.
 //	- a value is either:
 //	  - a placeholder (e.g. :1, @2 or $3)
+//	  - (since v1.2.0) a named placeholder (e.g. @name), its value is supplied via sql.Named("name", value)
 //	  - a null
 //	  - a number
 //	  - a boolean (true/false)
@@ -254,11 +380,13 @@ func (s *StmtCRUD) parseWithOpts(withOptsStr string) error {
 // See https://docs.microsoft.com/en-us/azure/cosmos-db/account-databases-containers-items#properties-of-an-item.
 type StmtInsert struct {
 	*StmtCRUD
-	isUpsert  bool
-	fieldsStr string
-	valuesStr string
-	fields    []string
-	values    []interface{}
+	isUpsert      bool
+	fieldsStr     string
+	valuesStr     string
+	moreValuesStr string // value lists of the additional rows of a multi-row INSERT
+	fields        []string
+	values        []interface{}
+	moreValues    [][]interface{} // values of the additional rows of a multi-row INSERT
 }
 
 // String implements interface fmt.Stringer/String.
@@ -269,33 +397,31 @@ func (s *StmtInsert) String() string {
 		s.StmtCRUD, s.isUpsert, s.fieldsStr, s.valuesStr, s.fields, s.values)
 }
 
-func (s *StmtInsert) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtInsert) parse() error {
+	if err := s.parseWithOpts(); err != nil {
 		return err
 	}
 
 	for k := range s.withOpts {
-		if k != "SINGLE_PK" && k != "SINGLEPK" && k != "PK" {
+		if k != "SINGLE_PK" && k != "SINGLEPK" && k != "PK" && k != "IF_MATCH" && k != "TTL" {
 			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
 		}
 	}
-
-	s.fields = regexp.MustCompile(`[,\s]+`).Split(s.fieldsStr, -1)
-	s.values = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.valuesStr); temp != ""; temp = strings.TrimSpace(temp) {
-		value, leftOver, err := _parseValue(temp, ',')
-		if err == nil {
-			s.values = append(s.values, value)
-			temp = leftOver
-			switch v := value.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
-		}
+	if err := s.parseIfMatch(); err != nil {
+		return err
+	}
+	if err := s.parseTtl(); err != nil {
 		return err
 	}
 
+	for _, value := range s.values {
+		s.trackPlaceholder(value)
+	}
+	for _, values := range s.moreValues {
+		for _, value := range values {
+			s.trackPlaceholder(value)
+		}
+	}
 	return nil
 }
 
@@ -303,6 +429,20 @@ func (s *StmtInsert) validate() error {
 	if len(s.fields) != len(s.values) {
 		return fmt.Errorf("number of fields (%d) does not match number of values (%d)", len(s.fields), len(s.values))
 	}
+	for i, values := range s.moreValues {
+		if len(s.fields) != len(values) {
+			return fmt.Errorf("number of fields (%d) does not match number of values (%d) at row #%d", len(s.fields), len(values), i+2)
+		}
+	}
+	if s.etag != nil && !s.isUpsert {
+		return errors.New("WITH IF_MATCH is only supported with UPSERT")
+	}
+	if s.etag != nil && len(s.moreValues) > 0 {
+		return errors.New("WITH IF_MATCH is not supported with multi-row UPSERT")
+	}
+	if s.isSinglePathPk && len(s.moreValues) > 0 {
+		return errors.New("WITH SINGLE_PK is not supported with multi-row INSERT/UPSERT, use WITH PK instead")
+	}
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
@@ -314,15 +454,29 @@ func (s *StmtInsert) validate() error {
 
 // Exec implements driver.Stmt/Exec.
 func (s *StmtInsert) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) ExecContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Result, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
+	}
 	if err := s.fetchPkInfo(); err != nil {
 		return nil, err
 	}
+	if len(s.moreValues) > 0 {
+		return s.execMulti(ctx, args)
+	}
 
 	pkValues := make([]driver.Value, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
+	if n := len(args.positional); n == s.numInputs+s.numPkPaths {
 		_, _ = fmt.Fprintf(os.Stderr, "[WARN] supplying PK value at the end of parameter list is deprecated, please use WITH PK\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
+		copy(pkValues, args.positional[s.numInputs:])
+		args.positional = args.positional[:s.numInputs]
 	} else if n == s.numInputs {
 		fieldValMap := make(map[string]interface{})
 		for i, field := range s.fields {
@@ -345,180 +499,545 @@ func (s *StmtInsert) Exec(args []driver.Value) (driver.Result, error) {
 		IsUpsert:           s.isUpsert,
 		PartitionKeyValues: make([]any, len(pkValues)),
 		DocumentData:       make(map[string]any),
+		Ttl:                s.ttl,
 	}
 	for i, pkValue := range pkValues {
-		switch v := pkValue.(type) {
-		case placeholder:
-			spec.PartitionKeyValues[i] = args[v.index-1]
-		default:
-			spec.PartitionKeyValues[i] = pkValue
-		}
+		spec.PartitionKeyValues[i] = args.resolve(pkValue)
 	}
 	for i, field := range s.fields {
-		switch v := s.values[i].(type) {
-		case placeholder:
-			spec.DocumentData[field] = args[v.index-1]
-		default:
-			spec.DocumentData[field] = s.values[i]
-		}
+		spec.DocumentData[field] = args.resolve(s.values[i])
+	}
+	if spec.MatchEtag, err = s.resolveEtag(args); err != nil {
+		return nil, err
 	}
 	restResult := s.conn.restClient.CreateDocument(spec)
+	s.conn.sessions.Update(s.dbName, s.collName, restResult.SessionToken)
 	rid := ""
 	if restResult.DocInfo != nil {
 		rid, _ = restResult.DocInfo["_rid"].(string)
 	}
 	result := buildResultNoResultSet(&restResult.RestResponse, true, rid, 0)
+	if result.err == nil {
+		result.docs = []DocInfo{restResult.DocInfo}
+	}
+	return result, result.err
+}
+
+// execMulti writes all rows of a multi-row INSERT/UPSERT, using up to bulkConcurrency parallel requests.
+func (s *StmtInsert) execMulti(ctx context.Context, args *stmtArgs) (driver.Result, error) {
+	if n := len(args.positional); n != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	rows := append([][]interface{}{s.values}, s.moreValues...)
+	docs := make([]map[string]interface{}, len(rows))
+	for i, values := range rows {
+		docs[i] = make(map[string]interface{}, len(s.fields))
+		for j, field := range s.fields {
+			docs[i][field] = args.resolve(values[j])
+		}
+		if s.ttl != 0 {
+			docs[i][docFieldTtl] = s.ttl
+		}
+		for _, pkPath := range s.pkPaths {
+			if _, ok := docs[i][pkPath[1:]]; !ok {
+				return nil, fmt.Errorf("missing value for PK %s", pkPath)
+			}
+		}
+	}
+	return s.conn.writeDocs(ctx, s.dbName, s.collName, s.pkPaths, s.isUpsert, docs)
+}
+
+// writeDocs creates (or upserts) the supplied documents in parallel, using up to bulkConcurrency requests.
+// Partition key values are extracted from the documents. A *BulkWriteError is returned if some documents failed.
+func (c *Conn) writeDocs(ctx context.Context, dbName, collName string, pkPaths []string, isUpsert bool, docs []map[string]interface{}) (driver.Result, error) {
+	var numWritten int64
+	written := make([]DocInfo, len(docs))
+	errs := _parallelDo(ctx, len(docs), bulkConcurrency, false, func(i int) error {
+		spec := DocumentSpec{
+			DbName:             dbName,
+			CollName:           collName,
+			IsUpsert:           isUpsert,
+			PartitionKeyValues: _extractPkValues(DocInfo(docs[i]), pkPaths),
+			DocumentData:       docs[i],
+		}
+		var restResult *RespCreateDoc
+		_retryThrottled(bulkMaxThrottleRetries, func() *RestResponse {
+			restResult = c.restClient.CreateDocument(spec)
+			return &restResult.RestResponse
+		})
+		c.sessions.Update(dbName, collName, restResult.SessionToken)
+		if err := restResult.Error(); err != nil {
+			return normalizeError(restResult.StatusCode, 0, err)
+		}
+		written[i] = restResult.DocInfo
+		atomic.AddInt64(&numWritten, 1)
+		return nil
+	})
+	result := &ResultNoResultSet{affectedRows: numWritten}
+	for _, doc := range written {
+		if doc != nil {
+			result.docs = append(result.docs, doc)
+		}
+	}
+	bulkErr := &BulkWriteError{NumRows: len(docs), NumSucceeded: int(numWritten), RowErrors: make(map[int]error)}
+	for i, err := range errs {
+		if err != nil {
+			bulkErr.RowErrors[i] = err
+		}
+	}
+	if len(bulkErr.RowErrors) > 0 {
+		result.err = bulkErr
+	}
 	return result, result.err
 }
 
 // Query implements driver.Stmt/Query.
-// This function is not implemented, use Exec instead.
-func (s *StmtInsert) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, ErrQueryNotSupported
+// (since v1.2.0) This function is supported only if the statement has a RETURNING clause, otherwise use Exec instead.
+func (s *StmtInsert) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// The statement is executed and the resulting documents are returned as rows, see StmtCRUD.buildReturningRows.
+//
+// @Available since v1.2.0
+func (s *StmtInsert) QueryContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
+	}
+	return s.buildReturningRows(s.ExecContext(ctx, namedArgs))
 }
 
 /*----------------------------------------------------------------------*/
 
-// StmtDelete implements "DELETE" operation.
+// StmtInsertValue implements "INSERT|UPSERT ... VALUE" operation, writing a whole JSON document.
 //
 // Syntax:
 //
-//	DELETE FROM <db-name>.<collection-name>
-//	WHERE id=<id-value>
-//	[AND pk1-path=<pk1-value> [AND pk2-path=<pk2-value> ...]]
+//	INSERT|UPSERT INTO <db-name>.<collection-name>
+//	VALUE <json-object>|<placeholder>
+//	[WITH PK=/pk-path]
+//	[WITH TTL=<seconds>|-1]
 //
-//	- DELETE removes only one document specified by 'id'.
-//	- The clause WHERE id=<id-value> is mandatory, and 'id' is a keyword, _not_ a field name.
-//	- <id-value> and <pk-value> must be a placeholder (e.g. :1, @2 or $3), or JSON value.
-//	- Supplying pk-paths and pk-values is highly recommended to save one round-trip to server to fetch the collection's partition key info.
-//	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths must be specified in the same order as in the collection (.e.g. AND field1=value1 AND field2=value2...).
+//	- the document is either a JSON object literal (e.g. {"id":"1","user":{"name":"me"}}) or a placeholder (e.g. :1, @2, $3 or @name).
+//	- the value bound to the placeholder can be a map, a struct (marshalled to JSON following its json tags), or a JSON object
+//	  as string or []byte.
+//	- partition key values are extracted from the document following the collection's PK paths (nested paths, e.g. /user/name, are supported).
+//	- Using WITH PK is highly recommended to save one round-trip to server to fetch the collection's partition key info.
+//	- WITH TTL sets the time-to-live of the document, in seconds (-1: never expire), see StmtInsert.
 //
-// See StmtInsert for details on <id-value> and <pk-value>.
-type StmtDelete struct {
+// @Available since v1.2.0
+type StmtInsertValue struct {
 	*StmtCRUD
-	whereStr string
-	id       interface{}
-	pkValues []interface{}
+	isUpsert bool
+	valueStr string
+	value    interface{} // the document (map[string]interface{}), or the placeholder it is bound to
 }
 
 // String implements interface fmt.Stringer/String.
-//
-// @Available since v1.1.0
-func (s *StmtDelete) String() string {
-	return fmt.Sprintf(`StmtDelete{StmtCRUD: %s, where_clause: %q, id: %v, pk_values: %v}`,
-		s.StmtCRUD, s.whereStr, s.id, s.pkValues)
+func (s *StmtInsertValue) String() string {
+	return fmt.Sprintf(`StmtInsertValue{StmtCRUD: %s, upsert: %v, value_str: %q, value: %v}`,
+		s.StmtCRUD, s.isUpsert, s.valueStr, s.value)
 }
 
-func (s *StmtDelete) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func (s *StmtInsertValue) parse() error {
+	if err := s.parseWithOpts(); err != nil {
 		return err
 	}
 
 	for k := range s.withOpts {
-		if k != "SINGLE_PK" && k != "SINGLEPK" {
+		if k != "PK" && k != "IF_MATCH" && k != "TTL" {
 			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
 		}
 	}
-
-	s.pkPaths = make([]string, 0)
-	s.pkValues = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.whereStr); temp != ""; temp = strings.TrimSpace(temp) {
-		pkPath, pkValue, leftOver, err := _parseFieldValueAnd(temp)
-		if err == nil {
-			if strings.ToLower(pkPath) == "id" {
-				s.id = pkValue
-			} else {
-				s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(pkPath, "/"))
-				s.pkValues = append(s.pkValues, pkValue)
-			}
-			temp = leftOver
-			switch v := pkValue.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
-		}
+	if err := s.parseIfMatch(); err != nil {
 		return err
 	}
-	if !s.isSinglePathPk {
-		s.numPkPaths = len(s.pkPaths)
+	if err := s.parseTtl(); err != nil {
+		return err
 	}
 
+	s.trackPlaceholder(s.value)
 	return nil
 }
 
-func (s *StmtDelete) validate() error {
-	if s.id == nil {
-		return errors.New("id value is missing")
-	}
+func (s *StmtInsertValue) validate() error {
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
-	if s.isSinglePathPk {
-		_, _ = fmt.Fprintf(os.Stderr, "[WARN] WITH singlePK/SINGLE_PK is deprecated, please use WHERE pk=value\n")
+	if s.etag != nil && !s.isUpsert {
+		return errors.New("WITH IF_MATCH is only supported with UPSERT")
 	}
 	return nil
 }
 
+// _toDocument converts the supplied value (JSON object as string/[]byte, map or struct) to a document.
+func _toDocument(value interface{}) (map[string]interface{}, error) {
+	var js []byte
+	switch v := value.(type) {
+	case map[string]interface{}:
+		// shallow copy, so that the caller's map (or the parsed literal) is not modified when id is generated
+		doc := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			doc[k] = val
+		}
+		return doc, nil
+	case string:
+		js = []byte(v)
+	case []byte:
+		js = v
+	default:
+		var err error
+		if js, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("cannot convert value of type %T to a document: %s", value, err)
+		}
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(js, &doc); err != nil || doc == nil {
+		return nil, fmt.Errorf("cannot convert value of type %T to a document: a JSON object is expected", value)
+	}
+	return doc, nil
+}
+
 // Exec implements driver.Stmt/Exec.
-func (s *StmtDelete) Exec(args []driver.Value) (driver.Result, error) {
-	if err := s.fetchPkInfo(); err != nil {
+func (s *StmtInsertValue) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtInsertValue) ExecContext(_ context.Context, namedArgs []driver.NamedValue) (driver.Result, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
 		return nil, err
 	}
-
-	pkValues := make([]driver.Value, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
-		_, _ = fmt.Fprintf(os.Stderr, "[WARN] supplying PK value at the end of parameter list is deprecated, please use WHERE pk=value\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
-	} else if n == s.numInputs {
-		for i, pkValue := range s.pkValues {
-			pkValues[i] = pkValue
+	if n := len(args.positional); n != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	doc, err := _toDocument(args.resolve(s.value))
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := doc["id"]; ok {
+		if _, ok := id.(string); !ok {
+			return nil, fmt.Errorf("document id must be a string, got %T", id)
 		}
-	} else {
-		return nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
 	}
-
-	id := s.id
-	switch v := s.id.(type) {
-	case placeholder:
-		id = args[v.index-1]
+	if err := s.fetchPkInfo(); err != nil {
+		return nil, err
+	}
+	pkValues := _extractPkValues(DocInfo(doc), s.pkPaths)
+	for i, pkValue := range pkValues {
+		if m, ok := pkValue.(map[string]interface{}); ok && len(m) == 0 {
+			return nil, fmt.Errorf("missing value for PK %s", s.pkPaths[i])
+		}
 	}
-	id, _ = reddo.ToString(id)
 
-	docReq := DocReq{
+	spec := DocumentSpec{
 		DbName:             s.dbName,
 		CollName:           s.collName,
-		DocId:              id.(string),
-		PartitionKeyValues: make([]any, len(pkValues)),
+		IsUpsert:           s.isUpsert,
+		PartitionKeyValues: pkValues,
+		DocumentData:       doc,
+		Ttl:                s.ttl,
 	}
-
-	for i, pkValue := range pkValues {
-		switch v := pkValue.(type) {
-		case placeholder:
-			docReq.PartitionKeyValues[i] = args[v.index-1]
-		default:
-			docReq.PartitionKeyValues[i] = pkValue
-		}
+	if spec.MatchEtag, err = s.resolveEtag(args); err != nil {
+		return nil, err
 	}
-
-	restResult := s.conn.restClient.DeleteDocument(docReq)
-	result := buildResultNoResultSet(&restResult.RestResponse, false, "", 0)
-	switch restResult.StatusCode {
-	case 404:
-		// consider "document not found" as successful operation
-		// but database/collection not found is not!
-		if strings.Contains(fmt.Sprintf("%s", restResult.Error()), "ResourceType: Document") {
+	restResult := s.conn.restClient.CreateDocument(spec)
+	s.conn.sessions.Update(s.dbName, s.collName, restResult.SessionToken)
+	rid := ""
+	if restResult.DocInfo != nil {
+		rid, _ = restResult.DocInfo["_rid"].(string)
+	}
+	result := buildResultNoResultSet(&restResult.RestResponse, true, rid, 0)
+	if result.err == nil {
+		result.docs = []DocInfo{restResult.DocInfo}
+	}
+	return result, result.err
+}
+
+// Query implements driver.Stmt/Query.
+// (since v1.2.0) This function is supported only if the statement has a RETURNING clause, otherwise use Exec instead.
+func (s *StmtInsertValue) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// The statement is executed and the resulting documents are returned as rows, see StmtCRUD.buildReturningRows.
+//
+// @Available since v1.2.0
+func (s *StmtInsertValue) QueryContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
+	}
+	return s.buildReturningRows(s.ExecContext(ctx, namedArgs))
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtDelete implements "DELETE" operation.
+//
+// Syntax:
+//
+//	DELETE FROM <db-name>.<collection-name>
+//	WHERE id=<id-value>
+//	[AND pk1-path=<pk1-value> [AND pk2-path=<pk2-value> ...]]
+//
+// or (since v1.2.0):
+//
+//	DELETE FROM <db-name>.<collection-name>
+//	WHERE <cosmos-db-sql-predicate>
+//	[WITH MAX_ROWS=<n>]
+//	[WITH DRY_RUN[=true]]
+//
+//	- With the first form, DELETE removes only one document specified by 'id'.
+//	- The clause WHERE id=<id-value> is mandatory, and 'id' is a keyword, _not_ a field name.
+//	- <id-value> and <pk-value> must be a placeholder (e.g. :1, @2 or $3), or JSON value.
+//	- Supplying pk-paths and pk-values is highly recommended to save one round-trip to server to fetch the collection's partition key info.
+//	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths must be specified in the same order as in the collection (.e.g. AND field1=value1 AND field2=value2...).
+//	- (since v1.2.0) If the WHERE clause is not in the form "id=<id-value> [AND ...]", it is a Cosmos DB SQL predicate where documents
+//	  are referred to via alias "c" (e.g. WHERE c.createdAt < :1). All matching documents are deleted in parallel, throttled
+//	  requests are retried, and RowsAffected returns the number of documents actually deleted.
+//	  Use WITH MAX_ROWS=<n> to abort the statement if more than n documents match, and WITH DRY_RUN to only count the
+//	  matching documents (RowsAffected returns the count) without deleting them.
+//	- (since v1.2.0) With the first form, "AND _etag=<etag-value>" (or WITH IF_MATCH=<etag-value>) deletes the document only
+//	  if its ETag matches, otherwise ErrPreconditionFailure is returned.
+//
+// See StmtInsert for details on <id-value> and <pk-value>.
+type StmtDelete struct {
+	*StmtCRUD
+	whereStr string
+	id       interface{}
+	pkValues []interface{}
+	dryRun   bool // if true, multi-document DELETE only counts the matching documents
+}
+
+// String implements interface fmt.Stringer/String.
+//
+// @Available since v1.1.0
+func (s *StmtDelete) String() string {
+	return fmt.Sprintf(`StmtDelete{StmtCRUD: %s, where_clause: %q, id: %v, pk_values: %v}`,
+		s.StmtCRUD, s.whereStr, s.id, s.pkValues)
+}
+
+func (s *StmtDelete) parse(where []sqlFieldValue) error {
+	if err := s.parseWithOpts(); err != nil {
+		return err
+	}
+
+	for k, v := range s.withOpts {
+		switch k {
+		case "SINGLE_PK", "SINGLEPK", "MAX_ROWS", "CONSISTENCY", "SESSION_TOKEN", "IF_MATCH":
+		case "DRY_RUN":
+			if v == "" {
+				s.dryRun = true
+			} else {
+				val, err := strconv.ParseBool(v)
+				if err != nil {
+					return fmt.Errorf("invalid value at WITH %s (expect true/false)", k)
+				}
+				s.dryRun = val
+			}
+		default:
+			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
+		}
+	}
+	if err := s.parseConsistencyOpts(); err != nil {
+		return err
+	}
+	if err := s.parseIfMatch(); err != nil {
+		return err
+	}
+
+	if where == nil {
+		// (since v1.2.0) the WHERE clause is a Cosmos DB SQL predicate, DELETE may remove multiple documents
+		return s.parsePredicate(s.whereStr)
+	}
+	var err error
+	s.id, s.pkValues, err = s.parseWherePointForm(where)
+	return err
+}
+
+func (s *StmtDelete) validate() error {
+	if s.id == nil && s.predicate == "" {
+		return errors.New("id value is missing")
+	}
+	if s.predicate != "" && s.isSinglePathPk {
+		return errors.New("WITH SINGLE_PK is not supported with a WHERE predicate")
+	}
+	if s.predicate == "" && s.dryRun {
+		return errors.New("WITH DRY_RUN is only supported with a WHERE predicate")
+	}
+	if s.predicate == "" && s.maxRows > 0 {
+		return errors.New("WITH MAX_ROWS is only supported with a WHERE predicate")
+	}
+	if s.predicate != "" && s.etag != nil {
+		return errors.New("WITH IF_MATCH is not supported with a WHERE predicate, use c._etag in the predicate instead")
+	}
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	if s.isSinglePathPk {
+		_, _ = fmt.Fprintf(os.Stderr, "[WARN] WITH singlePK/SINGLE_PK is deprecated, please use WHERE pk=value\n")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtDelete) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+//
+// @Available since v1.2.0
+func (s *StmtDelete) ExecContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Result, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fetchPkInfo(); err != nil {
+		return nil, err
+	}
+	if s.predicate != "" {
+		return s.execMulti(ctx, args)
+	}
+
+	pkValues := make([]driver.Value, s.numPkPaths)
+	if n := len(args.positional); n == s.numInputs+s.numPkPaths {
+		_, _ = fmt.Fprintf(os.Stderr, "[WARN] supplying PK value at the end of parameter list is deprecated, please use WHERE pk=value\n")
+		copy(pkValues, args.positional[s.numInputs:])
+		args.positional = args.positional[:s.numInputs]
+	} else if n == s.numInputs {
+		for i, pkValue := range s.pkValues {
+			pkValues[i] = pkValue
+		}
+	} else {
+		return nil, fmt.Errorf("expected %d or %d input values, got %d", s.numInputs, s.numInputs+s.numPkPaths, n)
+	}
+
+	id, _ := reddo.ToString(args.resolve(s.id))
+	docReq := DocReq{
+		DbName:             s.dbName,
+		CollName:           s.collName,
+		DocId:              id,
+		PartitionKeyValues: make([]any, len(pkValues)),
+	}
+	for i, pkValue := range pkValues {
+		docReq.PartitionKeyValues[i] = args.resolve(pkValue)
+	}
+
+	if docReq.MatchEtag, err = s.resolveEtag(args); err != nil {
+		return nil, err
+	}
+	var deletedDoc DocInfo
+	if s.returning != nil {
+		// RETURNING: fetch the document first, it is deleted only if not modified in the meantime
+		getDocResult := s.conn.restClient.GetDocument(docReq)
+		if err := getDocResult.Error(); err != nil {
+			if getDocResult.StatusCode == 404 && strings.Contains(fmt.Sprintf("%s", err), "ResourceType: Document") {
+				return &ResultNoResultSet{}, nil
+			}
+			return nil, normalizeError(getDocResult.StatusCode, 0, err)
+		}
+		deletedDoc = getDocResult.DocInfo
+		if docReq.MatchEtag != "" && docReq.MatchEtag != deletedDoc.Etag() {
+			return nil, ErrPreconditionFailure
+		}
+		docReq.MatchEtag = deletedDoc.Etag()
+	}
+	restResult := s.conn.restClient.DeleteDocument(docReq)
+	s.conn.sessions.Update(s.dbName, s.collName, restResult.SessionToken)
+	result := buildResultNoResultSet(&restResult.RestResponse, false, "", 0)
+	if restResult.Error() == nil && deletedDoc != nil {
+		result.docs = []DocInfo{deletedDoc}
+	}
+	switch restResult.StatusCode {
+	case 404:
+		// consider "document not found" as successful operation
+		// but database/collection not found is not!
+		if strings.Contains(fmt.Sprintf("%s", restResult.Error()), "ResourceType: Document") {
 			result.err = nil
 		}
 	}
 	return result, result.err
 }
 
+// execMulti deletes all documents matching the WHERE predicate, using up to bulkConcurrency parallel requests.
+//
+// Throttled requests (status 429) are retried up to bulkMaxThrottleRetries times, honoring the retry-after hint
+// from the server. Documents that no longer exist are not counted in RowsAffected. The first other error stops the
+// statement; RowsAffected then returns the number of documents deleted so far along with the error.
+func (s *StmtDelete) execMulti(ctx context.Context, args *stmtArgs) (driver.Result, error) {
+	if n := len(args.positional); n != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	docs, err := s.queryMatchingDocs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := &ResultNoResultSet{}
+	if s.dryRun {
+		result.affectedRows = int64(len(docs))
+		return result, nil
+	}
+
+	var numDeleted int64
+	deleted := make([]bool, len(docs))
+	errs := _parallelDo(ctx, len(docs), bulkConcurrency, true, func(i int) error {
+		docReq := DocReq{
+			DbName:             s.dbName,
+			CollName:           s.collName,
+			DocId:              docs[i].Id(),
+			PartitionKeyValues: _extractPkValues(docs[i], s.pkPaths),
+		}
+		var restResult *RespDeleteDoc
+		_retryThrottled(bulkMaxThrottleRetries, func() *RestResponse {
+			restResult = s.conn.restClient.DeleteDocument(docReq)
+			return &restResult.RestResponse
+		})
+		s.conn.sessions.Update(s.dbName, s.collName, restResult.SessionToken)
+		if restResult.StatusCode == http.StatusNotFound && strings.Contains(fmt.Sprintf("%s", restResult.Error()), "ResourceType: Document") {
+			// document has been deleted concurrently, but database/collection not found is an error!
+			return nil
+		}
+		if err := restResult.Error(); err != nil {
+			return normalizeError(restResult.StatusCode, 0, err)
+		}
+		deleted[i] = true
+		atomic.AddInt64(&numDeleted, 1)
+		return nil
+	})
+	result.affectedRows = numDeleted
+	for i, doc := range docs {
+		if deleted[i] {
+			result.docs = append(result.docs, doc)
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			result.err = err
+			break
+		}
+	}
+	return result, result.err
+}
+
 // Query implements driver.Stmt/Query.
-// This function is not implemented, use Exec instead.
-func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, ErrQueryNotSupported
+// (since v1.2.0) This function is supported only if the statement has a RETURNING clause, otherwise use Exec instead.
+func (s *StmtDelete) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// The statement is executed and the resulting documents are returned as rows, see StmtCRUD.buildReturningRows.
+//
+// @Available since v1.2.0
+func (s *StmtDelete) QueryContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
+	}
+	return s.buildReturningRows(s.ExecContext(ctx, namedArgs))
 }
 
 /*----------------------------------------------------------------------*/
@@ -532,6 +1051,8 @@ func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
 //	WITH database|db=<db-name>
 //	[WITH collection|table=<collection/table-name>]
 //	[WITH cross_partition|CrossPartition[=true]]
+//	[WITH CONSISTENCY=<consistency-level>]
+//	[WITH SESSION_TOKEN=<session-token>]
 //
 //	- (extension) If the collection is partitioned, specify "CROSS PARTITION" to allow execution across multiple partitions.
 //	  This clause is not required if query is to be executed on a single partition.
@@ -540,6 +1061,12 @@ func (s *StmtDelete) Query(_ []driver.Value) (driver.Rows, error) {
 //	- (extension) Use "WITH collection=<coll-name>" (or "WITH table=<coll-name>") to specify the collection/table on which the query is to be executed.
 //	  If not specified, collection/table name is extracted from the "FROM <collection/table-name>" clause.
 //	- (extension) Use placeholder syntax @i, $i or :i (where i denotes the i-th parameter, the first parameter is 1)
+//	- (since v1.2.0) Cosmos DB's native named parameters @name are also supported, values are supplied via sql.Named("name", value)
+//	- (since v1.2.0) Use "WITH CONSISTENCY=<level>" to override the consistency level of the query, accepted values: Strong, BoundedStaleness, Session, ConsistentPrefix or Eventual.
+//	- (since v1.2.0) Use "WITH SESSION_TOKEN=<token>" to specify the session token of the query, instead of the one tracked by the connection.
+//	- (since v1.2.0) "SELECT * FROM <collection/table-name> WHERE (id, <pk-field>) IN ((<id>, <pk-value>)[, (<id>, <pk-value>)...])"
+//	  reads the listed documents with RestClient.ReadMany instead of querying the collection. Documents that do not exist
+//	  are not returned. With hierarchical partition keys, list one field/value per level, e.g. (id, tenant, user) IN (('1', 't1', 'u1')).
 type StmtSelect struct {
 	*Stmt
 	isCrossPartition bool
@@ -547,6 +1074,7 @@ type StmtSelect struct {
 	collName         string
 	selectQuery      string
 	placeholders     map[int]string
+	readManyItems    [][]interface{} // (id, partition key values...) tuples of a read-many query
 }
 
 // String implements interface fmt.Stringer/String.
@@ -557,11 +1085,7 @@ func (s *StmtSelect) String() string {
 		s.Stmt, s.isCrossPartition, s.dbName, s.collName)
 }
 
-func (s *StmtSelect) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
-		return err
-	}
-
+func (s *StmtSelect) parse() error {
 	if err := s.onlyOneWithOption("database is specified more than once, only one of DATABASE or DB should be specified", "DATABASE", "DB"); err != nil {
 		return err
 	}
@@ -588,22 +1112,33 @@ func (s *StmtSelect) parse(withOptsStr string) error {
 				}
 				s.isCrossPartition = true
 			}
+		case "CONSISTENCY", "SESSION_TOKEN":
 		default:
 			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
 		}
 	}
+	if err := s.parseConsistencyOpts(); err != nil {
+		return err
+	}
 
-	matches := reValPlaceholder.FindAllStringSubmatch(s.selectQuery, -1)
-	s.numInputs = len(matches)
-	s.placeholders = make(map[int]string)
-	for _, match := range matches {
-		v, _ := strconv.Atoi(match[1])
-		key := "@_" + match[1]
-		s.placeholders[v] = key
-		if strings.HasSuffix(match[0], " ") {
-			key += " "
+	var err error
+	if s.selectQuery, s.placeholders, err = _rewritePlaceholders(s.selectQuery); err != nil {
+		return err
+	}
+	for index := range s.placeholders {
+		s.trackPlaceholder(placeholder{index})
+	}
+	for _, name := range _findNamedPlaceholders(s.selectQuery) {
+		s.trackPlaceholder(namedPlaceholder{name})
+	}
+	if s.readManyItems != nil {
+		// the listed values are bound to the arguments, the query is not sent to the server
+		s.numInputs = 0
+		for _, values := range s.readManyItems {
+			for _, value := range values {
+				s.trackPlaceholder(value)
+			}
 		}
-		s.selectQuery = strings.ReplaceAll(s.selectQuery, match[0], key)
 	}
 
 	return nil
@@ -618,36 +1153,326 @@ func (s *StmtSelect) validate() error {
 
 // Query implements driver.Stmt/Query.
 func (s *StmtSelect) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+//
+// @Available since v1.2.0
+func (s *StmtSelect) QueryContext(_ context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	if s.readManyItems != nil {
+		return s.queryReadMany(args)
+	}
+	query, err := s.buildQueryReq(args)
+	if err != nil {
+		return nil, err
+	}
+
+	restResult := s.conn.restClient.QueryDocumentsCrossPartition(query)
+	result := &ResultResultSet{err: restResult.Error(), columnList: make([]string, 0)}
+	if result.err == nil {
+		result.documents = restResult.Documents
+		result.init()
+	}
+	result.err = normalizeError(restResult.StatusCode, 0, result.err)
+	return result, result.err
+}
+
+// queryReadMany reads the documents listed by a read-many query, binding the supplied arguments to the listed values.
+func (s *StmtSelect) queryReadMany(args *stmtArgs) (driver.Rows, error) {
+	if n := len(args.positional); n != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	items := make([]ItemIdentity, len(s.readManyItems))
+	for i, values := range s.readManyItems {
+		id, ok := args.resolve(values[0]).(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid id %#v, expect a string", args.resolve(values[0]))
+		}
+		items[i] = ItemIdentity{Id: id, PartitionKeyValues: make([]interface{}, len(values)-1)}
+		for j, value := range values[1:] {
+			items[i].PartitionKeyValues[j] = args.resolve(value)
+		}
+	}
+	restResult := s.conn.restClient.ReadMany(ReadManyReq{
+		DbName:           s.dbName,
+		CollName:         s.collName,
+		Items:            items,
+		ConsistencyLevel: s.consistencyLevel,
+		SessionToken:     s.readSessionToken(s.dbName, s.collName),
+	})
+	result := &ResultResultSet{err: restResult.Error(), columnList: make([]string, 0)}
+	if result.err == nil {
+		result.documents = make(QueriedDocs, len(restResult.Documents))
+		for i, doc := range restResult.Documents {
+			result.documents[i] = doc
+		}
+		result.init()
+	}
+	result.err = normalizeError(restResult.StatusCode, 0, result.err)
+	return result, result.err
+}
+
+// buildQueryReq builds the query request, binding the supplied arguments to the query parameters.
+func (s *StmtSelect) buildQueryReq(args *stmtArgs) (QueryReq, error) {
 	params := make([]interface{}, 0)
-	for i, arg := range args {
+	for i, arg := range args.positional {
 		v, ok := s.placeholders[i+1]
 		if !ok {
-			return nil, fmt.Errorf("there is no placeholder #%d", i+1)
+			return QueryReq{}, fmt.Errorf("there is no placeholder #%d", i+1)
 		}
 		params = append(params, map[string]interface{}{"name": v, "value": arg})
 	}
-	query := QueryReq{
+	for name, arg := range args.named {
+		params = append(params, map[string]interface{}{"name": "@" + name, "value": arg})
+	}
+	return QueryReq{
 		DbName:                s.dbName,
 		CollName:              s.collName,
 		Query:                 s.selectQuery,
 		Params:                params,
 		CrossPartitionEnabled: s.isCrossPartition,
+		ConsistencyLevel:      s.consistencyLevel,
+		SessionToken:          s.readSessionToken(s.dbName, s.collName),
+	}, nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtSelect) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtExplain implements "EXPLAIN [ANALYZE] SELECT" operation, exposing how Cosmos DB executes a query.
+//
+// Syntax:
+//
+//	EXPLAIN [ANALYZE] <select-statement>
+//
+//	- <select-statement> is a SELECT statement, see StmtSelect.
+//	- EXPLAIN returns the query plan as a single row, the query is not executed. Columns: query, rewrittenQuery,
+//	  distinctType, top, offset, limit, orderBy, orderByExpressions, groupByExpressions, aggregates, hasSelectValue,
+//	  queryRanges and targetPartitions (ids of the partition key ranges targeted by the query).
+//	- EXPLAIN ANALYZE executes the query and returns its metrics (see QueryMetrics): one row per partition key range the
+//	  query was executed on, followed by a row summing them up (its "partition" column is "total"). Columns: partition,
+//	  numRequests, requestCharge, retrievedDocumentCount, retrievedDocumentSize, outputDocumentCount, outputDocumentSize,
+//	  indexHitRatio, totalExecutionTimeMs, queryPreparationTimeMs, indexLookupTimeMs, documentLoadTimeMs,
+//	  runtimeExecutionTimeMs and documentWriteTimeMs.
+//
+// @Available since v1.2.0
+type StmtExplain struct {
+	*StmtSelect
+	analyze bool
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtExplain) String() string {
+	return fmt.Sprintf(`StmtExplain{StmtSelect: %s, analyze: %v}`, s.StmtSelect, s.analyze)
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtExplain) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+func (s *StmtExplain) QueryContext(_ context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
 	}
+	query, err := s.buildQueryReq(args)
+	if err != nil {
+		return nil, err
+	}
+	if s.analyze {
+		return s.queryAnalyze(query)
+	}
+	return s.queryPlan(query)
+}
 
+func (s *StmtExplain) queryPlan(query QueryReq) (driver.Rows, error) {
+	queryPlan := s.conn.restClient.QueryPlan(query)
+	if err := queryPlan.Error(); err != nil {
+		return nil, normalizeError(queryPlan.StatusCode, 0, err)
+	}
+	pkranges := s.conn.restClient.GetPkranges(query.DbName, query.CollName)
+	if err := pkranges.Error(); err != nil {
+		return nil, normalizeError(pkranges.StatusCode, 0, err)
+	}
+	targetPartitions := make([]string, 0)
+	for _, pkrange := range queryPlan.TargetPkranges(pkranges.Pkranges) {
+		targetPartitions = append(targetPartitions, pkrange.Id)
+	}
+	queryRanges := make([]interface{}, 0, len(queryPlan.QueryRanges))
+	for _, r := range queryPlan.QueryRanges {
+		queryRanges = append(queryRanges, map[string]interface{}{"min": r.Min, "max": r.Max, "isMinInclusive": r.IsMinInclusive, "isMaxInclusive": r.IsMaxInclusive})
+	}
+	nonNil := func(list []string) []string {
+		if list == nil {
+			return []string{}
+		}
+		return list
+	}
+	info := queryPlan.QueryInfo
+	row := DocInfo{
+		"query":              query.Query,
+		"rewrittenQuery":     info.RewrittenQuery,
+		"distinctType":       info.DistinctType,
+		"top":                info.Top,
+		"offset":             info.Offset,
+		"limit":              info.Limit,
+		"orderBy":            nonNil(info.OrderBy),
+		"orderByExpressions": nonNil(info.OrderByExpressions),
+		"groupByExpressions": nonNil(info.GroupByExpressions),
+		"aggregates":         nonNil(info.Aggregates),
+		"hasSelectValue":     info.HasSelectValue,
+		"queryRanges":        queryRanges,
+		"targetPartitions":   targetPartitions,
+	}
+	return (&ResultResultSet{rows: []DocInfo{row}}).init(), nil
+}
+
+func (s *StmtExplain) queryAnalyze(query QueryReq) (driver.Rows, error) {
 	restResult := s.conn.restClient.QueryDocumentsCrossPartition(query)
-	result := &ResultResultSet{err: restResult.Error(), columnList: make([]string, 0)}
-	if result.err == nil {
-		result.documents = restResult.Documents
-		result.init()
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
 	}
-	result.err = normalizeError(restResult.StatusCode, 0, result.err)
-	return result, result.err
+	pkRangeIds := make([]string, 0, len(restResult.QueryMetrics))
+	for pkRangeId := range restResult.QueryMetrics {
+		pkRangeIds = append(pkRangeIds, pkRangeId)
+	}
+	sort.Slice(pkRangeIds, func(i, j int) bool {
+		// partition key range ids are numbers
+		if len(pkRangeIds[i]) != len(pkRangeIds[j]) {
+			return len(pkRangeIds[i]) < len(pkRangeIds[j])
+		}
+		return pkRangeIds[i] < pkRangeIds[j]
+	})
+	rows := make([]DocInfo, 0, len(pkRangeIds)+1)
+	total := &QueryMetrics{}
+	for _, pkRangeId := range pkRangeIds {
+		metrics := restResult.QueryMetrics[pkRangeId]
+		rows = append(rows, _queryMetricsToRow(pkRangeId, metrics))
+		total.Add(metrics)
+	}
+	rows = append(rows, _queryMetricsToRow("total", total))
+	return (&ResultResultSet{rows: rows}).init(), nil
+}
+
+func _queryMetricsToRow(partition string, m *QueryMetrics) DocInfo {
+	return DocInfo{
+		"partition":              partition,
+		"numRequests":            m.NumRequests,
+		"requestCharge":          m.RequestCharge,
+		"retrievedDocumentCount": m.RetrievedDocumentCount,
+		"retrievedDocumentSize":  m.RetrievedDocumentSize,
+		"outputDocumentCount":    m.OutputDocumentCount,
+		"outputDocumentSize":     m.OutputDocumentSize,
+		"indexHitRatio":          m.IndexHitRatio,
+		"totalExecutionTimeMs":   m.TotalExecutionTimeMs,
+		"queryPreparationTimeMs": m.QueryPreparationTimeMs,
+		"indexLookupTimeMs":      m.IndexLookupTimeMs,
+		"documentLoadTimeMs":     m.DocumentLoadTimeMs,
+		"runtimeExecutionTimeMs": m.RuntimeExecutionTimeMs,
+		"documentWriteTimeMs":    m.DocumentWriteTimeMs,
+	}
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtInsertSelect implements "INSERT INTO ... SELECT" operation, copying documents between collections.
+//
+// Syntax:
+//
+//	INSERT|UPSERT INTO <db-name>.<collection-name>
+//	SELECT ... FROM <source-collection-name> ...
+//	[WITH database|db=<source-db-name>]
+//	[WITH cross_partition|CrossPartition[=true]]
+//
+//	- The SELECT part follows the syntax of StmtSelect, its WITH options apply to the source collection.
+//	  The source database defaults to the target database, use WITH database=<db-name> to copy documents across databases.
+//	- The SELECT query must return JSON objects (i.e. not SELECT VALUE of scalar values). System attributes (e.g. _rid, _etag)
+//	  are removed before documents are written to the target collection.
+//	- Documents are written in parallel. RowsAffected reports the number of documents written. If some documents
+//	  failed, a *BulkWriteError is returned reporting the error of each failed document (by index in the query result).
+//
+// @Available since v1.2.0
+type StmtInsertSelect struct {
+	*StmtCRUD
+	isUpsert   bool
+	selectStmt *StmtSelect
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtInsertSelect) String() string {
+	return fmt.Sprintf(`StmtInsertSelect{StmtCRUD: %s, upsert: %v, select: %s}`, s.StmtCRUD, s.isUpsert, s.selectStmt)
+}
+
+func (s *StmtInsertSelect) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
 }
 
 // Exec implements driver.Stmt/Exec.
-// This function is not implemented, use Query instead.
-func (s *StmtSelect) Exec(_ []driver.Value) (driver.Result, error) {
-	return nil, ErrExecNotSupported
+func (s *StmtInsertSelect) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+func (s *StmtInsertSelect) ExecContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Result, error) {
+	args, err := s.selectStmt.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
+	}
+	if n := len(args.positional); n != s.selectStmt.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.selectStmt.numInputs, n)
+	}
+	if err := s.fetchPkInfo(); err != nil {
+		return nil, err
+	}
+	query, err := s.selectStmt.buildQueryReq(args)
+	if err != nil {
+		return nil, err
+	}
+	restResult := s.conn.restClient.QueryDocumentsCrossPartition(query)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	srcDocs := restResult.Documents.AsDocInfoSlice()
+	if srcDocs == nil {
+		return nil, errors.New("the SELECT query of INSERT ... SELECT must return JSON objects")
+	}
+	docs := make([]map[string]interface{}, len(srcDocs))
+	for i, doc := range srcDocs {
+		docs[i] = doc.RemoveSystemAttrs().AsMap()
+	}
+	return s.conn.writeDocs(ctx, s.dbName, s.collName, s.pkPaths, s.isUpsert, docs)
+}
+
+// Query implements driver.Stmt/Query.
+// (since v1.2.0) This function is supported only if the statement has a RETURNING clause, otherwise use Exec instead.
+func (s *StmtInsertSelect) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// The statement is executed and the resulting documents are returned as rows, see StmtCRUD.buildReturningRows.
+//
+// @Available since v1.2.0
+func (s *StmtInsertSelect) QueryContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
+	}
+	return s.buildReturningRows(s.ExecContext(ctx, namedArgs))
 }
 
 /*----------------------------------------------------------------------*/
@@ -658,14 +1483,33 @@ func (s *StmtSelect) Exec(_ []driver.Value) (driver.Result, error) {
 //
 //	UPDATE <db-name>.<collection-name>
 //	SET <field-name1>=<value1>[,<field-nameN>=<valueN>]*
+//	[UNSET <field-name1>[,<field-nameN>]*]
 //	WHERE id=<id-value>
 //	[AND pk1-path=<pk1-value> [AND pk2-path=<pk2-value> ...]]
+//	[WITH CONSISTENCY=<consistency-level>]
+//	[WITH SESSION_TOKEN=<session-token>]
+//
+// or (since v1.2.0):
+//
+//	UPDATE <db-name>.<collection-name>
+//	SET <field-name1>=<value1>[,<field-nameN>=<valueN>]*
+//	WHERE <cosmos-db-sql-predicate>
+//	[WITH MAX_ROWS=<n>]
 //
-//	- UPDATE modifies only one document specified by 'id'.
+//	- With the first form, UPDATE modifies only one document specified by 'id'.
 //	- The clause WHERE id=<id-value> is mandatory, and 'id' is a keyword, _not_ a field name.
 //	- <id-value> and <pk-value> must be a placeholder (e.g. :1, @2 or $3), or JSON value.
 //	- Supplying pk-paths and pk-values is highly recommended to save one round-trip to server to fetch the collection's partition key info.
 //	- If collection's PK has more than one path (i.e. sub-partition is used), the partition paths must be specified in the same order as in the collection (.e.g. AND field1=value1 AND field2=value2...).
+//	- (since v1.2.0) WITH CONSISTENCY and WITH SESSION_TOKEN apply to the read of the document being updated, see StmtSelect.
+//	- (since v1.2.0) If the WHERE clause is not in the form "id=<id-value> [AND ...]", it is a Cosmos DB SQL predicate where documents
+//	  are referred to via alias "c" (e.g. WHERE c.createdAt < :1). All matching documents are updated, and RowsAffected
+//	  returns the number of documents actually updated. Use WITH MAX_ROWS=<n> to abort the statement if more than n documents match.
+//	- (since v1.2.0) Field names can be nested paths (e.g. address.city, tags[0], a.b[2].c). Assignments can also be increments
+//	  (e.g. views=views+1 or stock=stock-:1) or array appends (e.g. tags=ARRAY_APPEND(tags, :1)). Fields are removed
+//	  with "UNSET <path>[, <path>...]" (or "REMOVE <path>..."), which can follow or replace the SET clause.
+//	- (since v1.2.0) With the first form, "AND _etag=<etag-value>" (or WITH IF_MATCH=<etag-value>) updates the document only
+//	  if its ETag matches, otherwise ErrPreconditionFailure is returned.
 //
 // See StmtInsert for details on <id-value> and <pk-value>.
 type StmtUpdate struct {
@@ -686,85 +1530,295 @@ func (s *StmtUpdate) String() string {
 		s.StmtCRUD, s.fields, s.values, s.whereStr, s.id, s.pkValues)
 }
 
-var (
-	reFieldPart = regexp.MustCompile(`\s*([\w\-]+)\s*=`)
-)
+var reUpdatePathSeg = regexp.MustCompile(`\[(\d+)\]|\.?([\w\-]+)`)
+
+// updateIncrement is the value of a "SET <path> = <path> +|- <operand>" assignment.
+type updateIncrement struct {
+	operand  interface{}
+	negative bool
+}
 
-func (s *StmtUpdate) _parseUpdateClause() error {
-	s.fields = make([]string, 0)
-	s.values = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.updateStr); temp != ""; temp = strings.TrimSpace(temp) {
-		// firstly, extract the field name
-		if loc := reFieldPart.FindStringIndex(temp); loc != nil && loc[0] == 0 {
-			field := strings.TrimSpace(temp[loc[0] : loc[1]-1])
-			s.fields = append(s.fields, field)
-			temp = strings.TrimSpace(temp[loc[1]:])
+// updateAppend is the value of a "SET <path> = ARRAY_APPEND(<path>, <value>)" assignment.
+type updateAppend struct {
+	value interface{}
+}
+
+// updateUnset is the value of an "UNSET <path>" (or "REMOVE <path>") item.
+type updateUnset struct{}
+
+// _parseUpdatePath splits a field path (e.g. a.b[0].c) into its segments: object keys (string) and array indexes (int).
+func _parseUpdatePath(path string) []interface{} {
+	segments := make([]interface{}, 0)
+	for _, groups := range reUpdatePathSeg.FindAllStringSubmatch(path, -1) {
+		if groups[1] != "" {
+			index, _ := strconv.Atoi(groups[1])
+			segments = append(segments, index)
 		} else {
-			return errors.New("(field) cannot parse query, invalid token at: " + temp)
+			segments = append(segments, groups[2])
 		}
+	}
+	return segments
+}
 
-		// secondly, parse the value part
-		value, leftOver, err := _parseValue(temp, ',')
-		if err == nil {
-			s.values = append(s.values, value)
-			temp = leftOver
-			switch v := value.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
-			}
-			continue
+// applyUpdates applies the SET/UNSET clause to the supplied document.
+func (s *StmtUpdate) applyUpdates(doc DocInfo, args *stmtArgs) error {
+	for i, field := range s.fields {
+		if err := _applyUpdate(map[string]interface{}(doc), _parseUpdatePath(field), s.values[i], args); err != nil {
+			return fmt.Errorf("cannot update field %s: %s", field, err)
 		}
-		return err
 	}
 	return nil
 }
 
-func (s *StmtUpdate) parse(withOptsStr string) error {
-	if err := s.parseWithOpts(withOptsStr); err != nil {
+func _applyUpdate(doc map[string]interface{}, path []interface{}, value interface{}, args *stmtArgs) error {
+	switch v := value.(type) {
+	case updateUnset:
+		_, err := _updateAtPath(doc, path, false, func(_ interface{}, _ bool) (interface{}, bool, error) {
+			return nil, true, nil
+		})
 		return err
-	}
-
-	for k := range s.withOpts {
-		if k != "SINGLE_PK" && k != "SINGLEPK" {
-			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
+	case updateIncrement:
+		rawOperand := args.resolve(v.operand)
+		operand, ok := _toNumber(rawOperand)
+		if !ok {
+			return fmt.Errorf("increment value must be a number, got %T", rawOperand)
 		}
-	}
-
-	if err := s._parseUpdateClause(); err != nil {
+		intOperand, isIntOperand := _toInt64(rawOperand)
+		if v.negative {
+			operand, intOperand = -operand, -intOperand
+		}
+		_, err := _updateAtPath(doc, path, true, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				if isIntOperand {
+					return intOperand, false, nil
+				}
+				return operand, false, nil
+			}
+			number, ok := _toNumber(current)
+			if !ok {
+				return nil, false, fmt.Errorf("current value is not a number (%T)", current)
+			}
+			if intCurrent, isIntCurrent := _toInt64(current); isIntCurrent && isIntOperand {
+				// integers are added as int64 so that counters beyond 2^53 do not lose precision
+				if sum := intCurrent + intOperand; (sum > intCurrent) == (intOperand > 0) {
+					return sum, false, nil
+				}
+			}
+			return number + operand, false, nil
+		})
+		return err
+	case updateAppend:
+		_, err := _updateAtPath(doc, path, true, func(current interface{}, exists bool) (interface{}, bool, error) {
+			if !exists || current == nil {
+				return []interface{}{args.resolve(v.value)}, false, nil
+			}
+			array, ok := current.([]interface{})
+			if !ok {
+				return nil, false, fmt.Errorf("current value is not an array (%T)", current)
+			}
+			return append(array, args.resolve(v.value)), false, nil
+		})
 		return err
 	}
+	_, err := _updateAtPath(doc, path, true, func(_ interface{}, _ bool) (interface{}, bool, error) {
+		return args.resolve(value), false, nil
+	})
+	return err
+}
 
-	s.pkPaths = make([]string, 0)
-	s.pkValues = make([]interface{}, 0)
-	for temp := strings.TrimSpace(s.whereStr); temp != ""; temp = strings.TrimSpace(temp) {
-		pkPath, pkValue, leftOver, err := _parseFieldValueAnd(temp)
-		if err == nil {
-			if strings.ToLower(pkPath) == "id" {
-				s.id = pkValue
+// _updateAtPath walks down the supplied path and calls fn with the current value at the end of the path, replacing
+// it with the returned value (or removing it). Missing objects along the path are created if create is true,
+// otherwise the node is left untouched. The (possibly new) node is returned.
+func _updateAtPath(node interface{}, path []interface{}, create bool, fn func(current interface{}, exists bool) (interface{}, bool, error)) (interface{}, error) {
+	switch key := path[0].(type) {
+	case string:
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot access key %q of a non-object value (%T)", key, node)
+		}
+		child, exists := m[key]
+		if len(path) == 1 {
+			value, remove, err := fn(child, exists)
+			if err != nil {
+				return nil, err
+			}
+			if remove {
+				delete(m, key)
 			} else {
-				s.pkPaths = append(s.pkPaths, "/"+strings.TrimLeft(pkPath, "/"))
-				s.pkValues = append(s.pkValues, pkValue)
+				m[key] = value
 			}
-			temp = leftOver
-			switch v := pkValue.(type) {
-			case placeholder:
-				s.numInputs = g18.Max(s.numInputs, v.index)
+			return m, nil
+		}
+		if !exists || child == nil {
+			if !create {
+				return m, nil
 			}
+			if _, ok := path[1].(int); ok {
+				return nil, fmt.Errorf("cannot access index of missing array %q", key)
+			}
+			child = make(map[string]interface{})
+		}
+		newChild, err := _updateAtPath(child, path[1:], create, fn)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = newChild
+		return m, nil
+	case int:
+		a, ok := node.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cannot access index [%d] of a non-array value (%T)", key, node)
+		}
+		if key >= len(a) {
+			if !create {
+				return a, nil
+			}
+			return nil, fmt.Errorf("index [%d] out of range (array length %d)", key, len(a))
+		}
+		if len(path) == 1 {
+			value, remove, err := fn(a[key], true)
+			if err != nil {
+				return nil, err
+			}
+			if remove {
+				return append(a[:key:key], a[key+1:]...), nil
+			}
+			a[key] = value
+			return a, nil
+		}
+		newChild, err := _updateAtPath(a[key], path[1:], create, fn)
+		if err != nil {
+			return nil, err
+		}
+		a[key] = newChild
+		return a, nil
+	}
+	return nil, fmt.Errorf("invalid path segment %v", path[0])
+}
+
+// _toNumber converts a numeric value (any Go integer or float kind, or json.Number) to float64.
+func _toNumber(v interface{}) (float64, bool) {
+	if v == nil {
+		return 0, false
+	}
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		f, err := reddo.ToFloat(v)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// _toInt64 converts an integer value (any Go integer kind, or json.Number holding an integer) to int64. Floats are
+// not integers, even if their value is integral.
+func _toInt64(v interface{}) (int64, bool) {
+	if n, ok := v.(json.Number); ok {
+		i, err := n.Int64()
+		return i, err == nil
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), rv.Uint() <= math.MaxInt64
+	}
+	return 0, false
+}
+
+// _decodeDocExact decodes a document, keeping numbers as json.Number so that integers beyond 2^53 are not rounded.
+func _decodeDocExact(data []byte) (DocInfo, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc DocInfo
+	err := decoder.Decode(&doc)
+	return doc, err
+}
+
+// incrementsLargeNumber returns true if the SET clause increments a value of the document that may have been rounded
+// when decoded as float64 (i.e. beyond 2^53).
+func (s *StmtUpdate) incrementsLargeNumber(doc DocInfo) bool {
+	for i, field := range s.fields {
+		if _, ok := s.values[i].(updateIncrement); !ok {
 			continue
 		}
+		var current interface{} = map[string]interface{}(doc)
+		for _, segment := range _parseUpdatePath(field) {
+			switch key := segment.(type) {
+			case string:
+				m, _ := current.(map[string]interface{})
+				current = m[key]
+			case int:
+				if a, _ := current.([]interface{}); key < len(a) {
+					current = a[key]
+				} else {
+					current = nil
+				}
+			}
+		}
+		if f, ok := current.(float64); ok && math.Abs(f) > 1<<53 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *StmtUpdate) parse(where []sqlFieldValue) error {
+	if err := s.parseWithOpts(); err != nil {
 		return err
 	}
-	if !s.isSinglePathPk {
-		s.numPkPaths = len(s.pkPaths)
+
+	for k := range s.withOpts {
+		if k != "SINGLE_PK" && k != "SINGLEPK" && k != "CONSISTENCY" && k != "SESSION_TOKEN" && k != "MAX_ROWS" && k != "IF_MATCH" {
+			return fmt.Errorf("invalid query, parsing error at WITH %s", k)
+		}
+	}
+	if err := s.parseConsistencyOpts(); err != nil {
+		return err
+	}
+	if err := s.parseIfMatch(); err != nil {
+		return err
 	}
 
-	return nil
+	for _, value := range s.values {
+		switch v := value.(type) {
+		case updateIncrement:
+			s.trackPlaceholder(v.operand)
+		case updateAppend:
+			s.trackPlaceholder(v.value)
+		default:
+			s.trackPlaceholder(v)
+		}
+	}
+
+	if where == nil {
+		// (since v1.2.0) the WHERE clause is a Cosmos DB SQL predicate, UPDATE may modify multiple documents
+		return s.parsePredicate(s.whereStr)
+	}
+	var err error
+	s.id, s.pkValues, err = s.parseWherePointForm(where)
+	return err
 }
 
 func (s *StmtUpdate) validate() error {
-	if s.id == nil {
+	if s.id == nil && s.predicate == "" {
 		return errors.New("id value is missing")
 	}
+	if s.predicate != "" && s.isSinglePathPk {
+		return errors.New("WITH SINGLE_PK is not supported with a WHERE predicate")
+	}
+	if s.predicate == "" && s.maxRows > 0 {
+		return errors.New("WITH MAX_ROWS is only supported with a WHERE predicate")
+	}
+	if s.predicate != "" && s.etag != nil {
+		return errors.New("WITH IF_MATCH is not supported with a WHERE predicate, use c._etag in the predicate instead")
+	}
 	if s.dbName == "" || s.collName == "" {
 		return errors.New("database/collection is missing")
 	}
@@ -779,15 +1833,29 @@ func (s *StmtUpdate) validate() error {
 
 // Exec implements driver.Stmt/Exec.
 func (s *StmtUpdate) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext/ExecContext.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) ExecContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Result, error) {
+	args, err := s.bindArgs(namedArgs)
+	if err != nil {
+		return nil, err
+	}
 	if err := s.fetchPkInfo(); err != nil {
 		return nil, err
 	}
+	if s.predicate != "" {
+		return s.execMulti(ctx, args)
+	}
 
 	pkValues := make([]driver.Value, s.numPkPaths)
-	if n := len(args); n == s.numInputs+s.numPkPaths {
+	if n := len(args.positional); n == s.numInputs+s.numPkPaths {
 		_, _ = fmt.Fprintf(os.Stderr, "[WARN] suplying PK value at the end of parameter list is deprecated, please use WHERE pk=value\n")
-		copy(pkValues, args[s.numInputs:])
-		args = args[:s.numInputs]
+		copy(pkValues, args.positional[s.numInputs:])
+		args.positional = args.positional[:s.numInputs]
 	} else if n == s.numInputs {
 		for i, pkValue := range s.pkValues {
 			pkValues[i] = pkValue
@@ -797,26 +1865,18 @@ func (s *StmtUpdate) Exec(args []driver.Value) (driver.Result, error) {
 	}
 	pkValuesForApiCall := make([]any, len(pkValues))
 	for i, pkValue := range pkValues {
-		switch v := pkValue.(type) {
-		case placeholder:
-			pkValuesForApiCall[i] = args[v.index-1]
-		default:
-			pkValuesForApiCall[i] = pkValue
-		}
+		pkValuesForApiCall[i] = args.resolve(pkValue)
 	}
 
 	// firstly, fetch the document
-	id := s.id
-	switch v := s.id.(type) {
-	case placeholder:
-		id = args[v.index-1]
-	}
-	id, _ = reddo.ToString(id)
+	id, _ := reddo.ToString(args.resolve(s.id))
 	docReq := DocReq{
 		DbName:             s.dbName,
 		CollName:           s.collName,
-		DocId:              id.(string),
+		DocId:              id,
 		PartitionKeyValues: pkValuesForApiCall,
+		ConsistencyLevel:   s.consistencyLevel,
+		SessionToken:       s.readSessionToken(s.dbName, s.collName),
 	}
 	getDocResult := s.conn.restClient.GetDocument(docReq)
 	if err := getDocResult.Error(); err != nil {
@@ -834,22 +1894,35 @@ func (s *StmtUpdate) Exec(args []driver.Value) (driver.Result, error) {
 
 	// secondly, update the fetched document
 	etag := getDocResult.DocInfo.Etag()
+	ignoreErrorCode := 412
+	if expectedEtag, err := s.resolveEtag(args); err != nil {
+		return nil, err
+	} else if expectedEtag != "" {
+		// (since v1.2.0) optimistic concurrency: the document must not have been modified since the caller read it
+		if expectedEtag != etag {
+			return nil, ErrPreconditionFailure
+		}
+		ignoreErrorCode = 0
+	}
+	doc, err := _decodeDocExact(getDocResult.RespBody)
+	if err != nil {
+		return nil, err
+	}
 	spec := DocumentSpec{
 		DbName:             s.dbName,
 		CollName:           s.collName,
 		PartitionKeyValues: pkValuesForApiCall,
-		DocumentData:       getDocResult.DocInfo.RemoveSystemAttrs(),
+		DocumentData:       doc.RemoveSystemAttrs(),
 	}
-	for i, field := range s.fields {
-		switch v := s.values[i].(type) {
-		case placeholder:
-			spec.DocumentData[field] = args[v.index-1]
-		default:
-			spec.DocumentData[field] = s.values[i]
-		}
+	if err := s.applyUpdates(spec.DocumentData, args); err != nil {
+		return nil, err
 	}
 	replaceDocResult := s.conn.restClient.ReplaceDocument(etag, spec)
-	result := buildResultNoResultSet(&replaceDocResult.RestResponse, false, "", 412)
+	s.conn.sessions.Update(s.dbName, s.collName, replaceDocResult.SessionToken)
+	result := buildResultNoResultSet(&replaceDocResult.RestResponse, false, "", ignoreErrorCode)
+	if replaceDocResult.Error() == nil {
+		result.docs = []DocInfo{replaceDocResult.DocInfo}
+	}
 	switch replaceDocResult.StatusCode {
 	case 404: // rare case, but possible!
 		// consider "document not found" as successful operation
@@ -861,8 +1934,103 @@ func (s *StmtUpdate) Exec(args []driver.Value) (driver.Result, error) {
 	return result, result.err
 }
 
+// execMulti updates all documents matching the WHERE predicate, using up to bulkConcurrency parallel requests.
+//
+// Each matched document is replaced with its ETag, so that a document modified concurrently since it was queried is
+// not overwritten: the statement fails with ErrPreconditionFailure instead. Throttled requests (status 429) are retried
+// up to bulkMaxThrottleRetries times, honoring the retry-after hint from the server. Documents that no longer exist are
+// not counted in RowsAffected. The first other error stops the statement; RowsAffected then returns the number of
+// documents updated so far along with the error.
+func (s *StmtUpdate) execMulti(ctx context.Context, args *stmtArgs) (driver.Result, error) {
+	if n := len(args.positional); n != s.numInputs {
+		return nil, fmt.Errorf("expected %d input values, got %d", s.numInputs, n)
+	}
+	docs, err := s.queryMatchingDocs(args)
+	if err != nil {
+		return nil, err
+	}
+	result := &ResultNoResultSet{}
+
+	var numUpdated int64
+	updated := make([]DocInfo, len(docs))
+	errs := _parallelDo(ctx, len(docs), bulkConcurrency, true, func(i int) error {
+		doc := docs[i]
+		if s.incrementsLargeNumber(doc) {
+			// the queried document has been decoded as float64: re-read it to increment the exact integer value
+			docReq := DocReq{DbName: s.dbName, CollName: s.collName, DocId: doc.Id(),
+				PartitionKeyValues: _extractPkValues(doc, s.pkPaths), ConsistencyLevel: s.consistencyLevel,
+				SessionToken: s.readSessionToken(s.dbName, s.collName)}
+			var getDocResult *RespGetDoc
+			_retryThrottled(bulkMaxThrottleRetries, func() *RestResponse {
+				getDocResult = s.conn.restClient.GetDocument(docReq)
+				return &getDocResult.RestResponse
+			})
+			if getDocResult.StatusCode == http.StatusNotFound && strings.Contains(fmt.Sprintf("%s", getDocResult.Error()), "ResourceType: Document") {
+				// document has been deleted concurrently, but database/collection not found is an error!
+				return nil
+			}
+			if err := getDocResult.Error(); err != nil {
+				return normalizeError(getDocResult.StatusCode, 0, err)
+			}
+			var err error
+			if doc, err = _decodeDocExact(getDocResult.RespBody); err != nil {
+				return err
+			}
+		}
+		spec := DocumentSpec{
+			DbName:             s.dbName,
+			CollName:           s.collName,
+			PartitionKeyValues: _extractPkValues(doc, s.pkPaths),
+			DocumentData:       doc.RemoveSystemAttrs(),
+		}
+		if err := s.applyUpdates(spec.DocumentData, args); err != nil {
+			return err
+		}
+		var replaceDocResult *RespReplaceDoc
+		_retryThrottled(bulkMaxThrottleRetries, func() *RestResponse {
+			replaceDocResult = s.conn.restClient.ReplaceDocument(doc.Etag(), spec)
+			return &replaceDocResult.RestResponse
+		})
+		s.conn.sessions.Update(s.dbName, s.collName, replaceDocResult.SessionToken)
+		if replaceDocResult.StatusCode == http.StatusNotFound && strings.Contains(fmt.Sprintf("%s", replaceDocResult.Error()), "ResourceType: Document") {
+			// document has been deleted concurrently, but database/collection not found is an error!
+			return nil
+		}
+		if err := replaceDocResult.Error(); err != nil {
+			return normalizeError(replaceDocResult.StatusCode, 0, err)
+		}
+		updated[i] = replaceDocResult.DocInfo
+		atomic.AddInt64(&numUpdated, 1)
+		return nil
+	})
+	result.affectedRows = numUpdated
+	for _, doc := range updated {
+		if doc != nil {
+			result.docs = append(result.docs, doc)
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			result.err = err
+			break
+		}
+	}
+	return result, result.err
+}
+
 // Query implements driver.Stmt/Query.
-// This function is not implemented, use Exec instead.
-func (s *StmtUpdate) Query(_ []driver.Value) (driver.Rows, error) {
-	return nil, ErrQueryNotSupported
+// (since v1.2.0) This function is supported only if the statement has a RETURNING clause, otherwise use Exec instead.
+func (s *StmtUpdate) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), _valuesToNamedValues(args))
+}
+
+// QueryContext implements driver.StmtQueryContext/QueryContext.
+// The statement is executed and the resulting documents are returned as rows, see StmtCRUD.buildReturningRows.
+//
+// @Available since v1.2.0
+func (s *StmtUpdate) QueryContext(ctx context.Context, namedArgs []driver.NamedValue) (driver.Rows, error) {
+	if s.returning == nil {
+		return nil, ErrQueryNotSupported
+	}
+	return s.buildReturningRows(s.ExecContext(ctx, namedArgs))
 }
@@ -1,6 +1,9 @@
 package gocosmos
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 )
@@ -101,6 +104,14 @@ $1, :3, @2)`,
 			sql:       `INSERT INTO db.table (a,b,c) VALUES (:1,$2,3) WITH Pk=/mypk WITH SINGLE_PK`,
 			mustError: true,
 		},
+		{
+			name:     "multi_rows",
+			sql:      `INSERT INTO db.table (a,b) VALUES (1,"\"x\""), (:1, $2),(@3,null) WITH pk=/a`,
+			expected: &StmtInsert{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 3}, dbName: "db", collName: "table", numPkPaths: 1, withPk: "/a", pkPaths: []string{"/a"}}, fields: []string{"a", "b"}, values: []interface{}{1.0, "x"}, moreValues: [][]interface{}{{placeholder{1}, placeholder{2}}, {placeholder{3}, nil}}},
+		},
+		{name: "error_multi_rows_num_values_not_matched", sql: `INSERT INTO db.table (a,b) VALUES (1,2), (3)`, mustError: true},
+		{name: "error_multi_rows_invalid_value", sql: `INSERT INTO db.table (a,b) VALUES (1,2), (3,'a')`, mustError: true},
+		{name: "error_multi_rows_single_pk", sql: `INSERT INTO db.table (a,b) VALUES (1,2), (3,4) WITH SINGLE_PK`, mustError: true},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -121,6 +132,60 @@ $1, :3, @2)`,
 			stmt.Stmt = &Stmt{numInputs: stmt.numInputs}
 			stmt.fieldsStr = ""
 			stmt.valuesStr = ""
+			stmt.moreValuesStr = ""
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtInsertValue_parse(t *testing.T) {
+	testName := "TestStmtInsertValue_parse"
+	testData := []struct {
+		name      string
+		sql       string
+		expected  *StmtInsertValue
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: `INSERT INTO db. VALUE {"id":"1"}`, mustError: true},
+		{name: "error_invalid_json", sql: `INSERT INTO db.table VALUE {"id":1,}`, mustError: true},
+		{name: "error_not_placeholder", sql: `INSERT INTO db.table VALUE "{\"id\":\"1\"}"`, mustError: true},
+		{name: "error_invalid_with", sql: `INSERT INTO db.table VALUE :1 WITH SINGLE_PK`, mustError: true},
+		{
+			name:     "json_literal",
+			sql:      "INSERT INTO db.table VALUE {\"id\": \"1\", \"user\": {\"name\": \"me\", \"tags\": [1, \"with x\"]}}",
+			expected: &StmtInsertValue{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 0}, dbName: "db", collName: "table"}, value: map[string]interface{}{"id": "1", "user": map[string]interface{}{"name": "me", "tags": []interface{}{1.0, "with x"}}}},
+		},
+		{
+			name:     "upsert_placeholder_with_pk",
+			sql:      `UPSERT INTO db.table VALUE $1 WITH pk=/user/name`,
+			expected: &StmtInsertValue{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", numPkPaths: 1, withPk: "/user/name", pkPaths: []string{"/user/name"}}, isUpsert: true, value: placeholder{1}},
+		},
+		{
+			name:     "named_placeholder",
+			sql:      `INSERT INTO table VALUE @doc`,
+			expected: &StmtInsertValue{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 0}, dbName: "mydb", collName: "table"}, value: namedPlaceholder{"doc"}},
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "mydb", testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtInsertValue)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtInsertValue but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = &Stmt{numInputs: stmt.numInputs}
+			stmt.valueStr = ""
 			if !reflect.DeepEqual(stmt, testCase.expected) {
 				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, testCase.expected, stmt)
 			}
@@ -128,6 +193,103 @@ $1, :3, @2)`,
 	}
 }
 
+func TestStmt_toDocument(t *testing.T) {
+	testName := "TestStmt_toDocument"
+	type user struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+		Age  int    `json:"age,omitempty"`
+	}
+	testData := []struct {
+		name      string
+		value     interface{}
+		expected  map[string]interface{}
+		mustError bool
+	}{
+		{name: "map", value: map[string]interface{}{"id": "1", "a": 1}, expected: map[string]interface{}{"id": "1", "a": 1}},
+		{name: "string", value: `{"id":"1","a":[1,true]}`, expected: map[string]interface{}{"id": "1", "a": []interface{}{1.0, true}}},
+		{name: "bytes", value: []byte(`{"id":"1"}`), expected: map[string]interface{}{"id": "1"}},
+		{name: "struct", value: user{Id: "1", Name: "me"}, expected: map[string]interface{}{"id": "1", "name": "me"}},
+		{name: "struct_pointer", value: &user{Id: "1", Name: "me", Age: 10}, expected: map[string]interface{}{"id": "1", "name": "me", "age": 10.0}},
+		{name: "error_json_array", value: `[1,2]`, mustError: true},
+		{name: "error_number", value: 1, mustError: true},
+		{name: "error_nil", value: nil, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			doc, err := _toDocument(testCase.value)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if !reflect.DeepEqual(doc, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, doc)
+			}
+		})
+	}
+}
+
+func TestStmtInsertSelect_parse(t *testing.T) {
+	testName := "TestStmtInsertSelect_parse"
+	testData := []struct {
+		name         string
+		sql          string
+		defaultDb    string
+		upsert       bool
+		dbName       string
+		collName     string
+		srcDbName    string
+		srcCollName  string
+		srcNumInputs int
+		srcCrossPart bool
+		mustError    bool
+	}{
+		{name: "error_no_db", sql: `INSERT INTO table SELECT * FROM c`, mustError: true},
+		{name: "error_invalid_select", sql: `INSERT INTO db.table SELECT *`, mustError: true},
+		{name: "error_invalid_with", sql: `INSERT INTO db.table SELECT * FROM c WITH PK=/a`, mustError: true},
+		{name: "basic", sql: `INSERT INTO db.table SELECT * FROM src`, dbName: "db", collName: "table", srcDbName: "db", srcCollName: "src"},
+		{name: "upsert", sql: `UPSERT INTO db.table SELECT * FROM src`, upsert: true, dbName: "db", collName: "table", srcDbName: "db", srcCollName: "src"},
+		{name: "default_db", sql: `INSERT INTO table SELECT * FROM src`, defaultDb: "mydb", dbName: "mydb", collName: "table", srcDbName: "mydb", srcCollName: "src"},
+		{
+			name:   "cross_db",
+			sql:    "INSERT INTO db2.table\nSELECT CROSS PARTITION c.id, c.name FROM c WHERE c.age > :1 AND c.app=@app WITH db=db1 WITH collection=src",
+			dbName: "db2", collName: "table", srcDbName: "db1", srcCollName: "src", srcNumInputs: 1, srcCrossPart: true,
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.defaultDb, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtInsertSelect)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtInsertSelect but received %T", testName+"/"+testCase.name, s)
+			}
+			if stmt.isUpsert != testCase.upsert || stmt.dbName != testCase.dbName || stmt.collName != testCase.collName {
+				t.Fatalf("%s failed: expected target %v/%s.%s but received %v/%s.%s", testName+"/"+testCase.name,
+					testCase.upsert, testCase.dbName, testCase.collName, stmt.isUpsert, stmt.dbName, stmt.collName)
+			}
+			src := stmt.selectStmt
+			if src.dbName != testCase.srcDbName || src.collName != testCase.srcCollName || src.numInputs != testCase.srcNumInputs || src.isCrossPartition != testCase.srcCrossPart {
+				t.Fatalf("%s failed: expected source %s.%s (inputs: %d, cross-partition: %v) but received %s", testName+"/"+testCase.name,
+					testCase.srcDbName, testCase.srcCollName, testCase.srcNumInputs, testCase.srcCrossPart, src)
+			}
+		})
+	}
+}
+
 func TestStmtInsert_parse_defaultDb(t *testing.T) {
 	testName := "TestStmtInsert_parse_defaultDb"
 	testData := []struct {
@@ -301,6 +463,11 @@ a,b,c) VALUES ($1,
 			sql:       `UPSERT INTO db.table (a,b,c) VALUES (:1, :3, :2) WITH singlePK=false`,
 			mustError: true,
 		},
+		{
+			name:      "error_multi_rows_singlepk",
+			sql:       `UPSERT INTO db.table (a,b) VALUES (:1, :2), (:3, :4) WITH singlePK`,
+			mustError: true,
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -516,6 +683,44 @@ db1.table1 WHERE
 			sql:      `DELETE FROM db.table WHERE id=:3 AND app=$2 and Username=1`,
 			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 3}, dbName: "db", collName: "table", numPkPaths: 2, pkPaths: []string{"/app", "/Username"}}, id: placeholder{3}, pkValues: []interface{}{placeholder{2}, 1.0}},
 		},
+
+		{name: "error_predicate_singlepk", sql: `DELETE FROM db.table WHERE c.a<2 WITH SINGLE_PK`, mustError: true},
+		{name: "error_predicate_invalid_max_rows", sql: `DELETE FROM db.table WHERE c.a<2 WITH MAX_ROWS=-1`, mustError: true},
+		{name: "error_predicate_invalid_dry_run", sql: `DELETE FROM db.table WHERE c.a<2 WITH DRY_RUN=maybe`, mustError: true},
+		{name: "error_dry_run_without_predicate", sql: `DELETE FROM db.table WHERE id=1 WITH DRY_RUN`, mustError: true},
+		{name: "error_max_rows_without_predicate", sql: `DELETE FROM db.table WHERE id=1 WITH MAX_ROWS=10`, mustError: true},
+		{name: "error_predicate_placeholder_zero", sql: `DELETE FROM db.table WHERE c.a=:0`, mustError: true},
+		{name: "error_placeholder_zero", sql: `DELETE FROM db.table WHERE id=@0 AND pk=$1`, mustError: true},
+		{
+			name:     "predicate",
+			sql:      `DELETE FROM db.table WHERE c.createdAt < :1 AND c.status = @status`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", predicate: "c.createdAt < @_1 AND c.status = @status", placeholders: map[int]string{1: "@_1"}}},
+		},
+		{
+			name:     "predicate_without_id",
+			sql:      `DELETE FROM db.table WHERE status=:1`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", predicate: "status=@_1", placeholders: map[int]string{1: "@_1"}}},
+		},
+		{
+			name:     "predicate_and_comparison",
+			sql:      `DELETE FROM db.table WHERE status=:1 AND c.x>5`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", predicate: "status=@_1 AND c.x>5", placeholders: map[int]string{1: "@_1"}}},
+		},
+		{
+			name:     "predicate_id_or",
+			sql:      `DELETE FROM db.table WHERE id=:1 OR id=:2`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 2}, dbName: "db", collName: "table", predicate: "id=@_1 OR id=@_2", placeholders: map[int]string{1: "@_1", 2: "@_2"}}},
+		},
+		{
+			name:     "predicate_max_rows_dry_run",
+			sql:      `DELETE FROM db.table WHERE NOT IS_DEFINED(c.owner) WITH MAX_ROWS=50, WITH DRY_RUN`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{}, dbName: "db", collName: "table", predicate: "NOT IS_DEFINED(c.owner)", placeholders: map[int]string{}, maxRows: 50}, dryRun: true},
+		},
+		{
+			name:     "predicate_dry_run_false",
+			sql:      `DELETE FROM db.table WHERE c.a=$1 WITH dry_run=false`,
+			expected: &StmtDelete{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", predicate: "c.a=@_1", placeholders: map[int]string{1: "@_1"}}},
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -665,6 +870,12 @@ func TestStmtSelect_parse(t *testing.T) {
 		{name: "error_cross_partition_more_than_once2", sql: `SELECT CROSS PARTITION * FROM c WITH db=dbname WITH collection=collname WITH CrossPartition`, mustError: true},
 		{name: "error_invalid_with", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH a`, mustError: true},
 		{name: "error_invalid_with2", sql: `SELECT * FROM c WITH db=dbname WITH collection=collname WITH a=1`, mustError: true},
+		{name: "error_placeholder_zero", sql: `SELECT * FROM c WHERE c.a=:0 WITH db=dbname`, mustError: true},
+		{name: "error_placeholder_zero2", sql: `SELECT * FROM c WHERE c.a=@1 OR c.b=$0 WITH db=dbname`, mustError: true},
+		{name: "error_placeholder_zero3", sql: `EXPLAIN SELECT * FROM c WHERE c.a=@0 WITH db=dbname`, mustError: true},
+		{name: "error_read_many_num_values", sql: `SELECT * FROM c WHERE (id, pk) IN (('1', 'a'), ('2')) WITH db=dbname`, mustError: true},
+		{name: "error_read_many_unquoted_value", sql: `SELECT * FROM c WHERE (id, pk) IN (('1', a)) WITH db=dbname`, mustError: true},
+		{name: "error_read_many_trailing", sql: `SELECT * FROM c WHERE (id, pk) IN (('1', 'a')) AND c.b=1 WITH db=dbname`, mustError: true},
 
 		{
 			name:     "basic",
@@ -686,6 +897,23 @@ func TestStmtSelect_parse(t *testing.T) {
 			sql:      `SELECT a,b,c FROM user u WHERE u.id="1" WITH db=dbtemp WITH CrossPartition`,
 			expected: &StmtSelect{dbName: "dbtemp", collName: "user", isCrossPartition: true, selectQuery: `SELECT a,b,c FROM user u WHERE u.id="1"`, placeholders: map[int]string{}},
 		},
+		{
+			name: "read_many",
+			sql:  `SELECT * FROM c WHERE (id, pk) IN ((:1, :2), ('b', 2), ("c\"", null)) WITH db=db WITH table=tbl`,
+			expected: &StmtSelect{dbName: "db", collName: "tbl", selectQuery: `SELECT * FROM c WHERE (id, pk) IN ((@_1, @_2), ('b', 2), ("c\"", null))`, placeholders: map[int]string{1: "@_1", 2: "@_2"},
+				readManyItems: [][]interface{}{{placeholder{1}, placeholder{2}}, {"b", 2.0}, {`c"`, nil}}},
+		},
+		{
+			name: "read_many_alias_subpartitions",
+			sql:  `SELECT * FROM user u WHERE (u.id, u.app, u.username) IN (('1', @app, 'user1')) WITH db=db`,
+			expected: &StmtSelect{dbName: "db", collName: "user", selectQuery: `SELECT * FROM user u WHERE (u.id, u.app, u.username) IN (('1', @app, 'user1'))`, placeholders: map[int]string{},
+				readManyItems: [][]interface{}{{"1", namedPlaceholder{"app"}, "user1"}}},
+		},
+		{
+			name:     "not_read_many",
+			sql:      `SELECT * FROM c WHERE (c.a, c.b) IN ((1, 2)) WITH db=db`,
+			expected: &StmtSelect{dbName: "db", collName: "c", selectQuery: `SELECT * FROM c WHERE (c.a, c.b) IN ((1, 2))`, placeholders: map[int]string{}},
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -769,6 +997,56 @@ func TestStmtSelect_parse_defaultDb(t *testing.T) {
 	}
 }
 
+func TestStmtExplain_parse(t *testing.T) {
+	testName := "TestStmtExplain_parse"
+	testData := []struct {
+		name      string
+		sql       string
+		expected  *StmtExplain
+		mustError bool
+	}{
+		{name: "error_not_select", sql: `EXPLAIN DELETE FROM db.table WHERE id=1`, mustError: true},
+		{name: "error_no_db", sql: `EXPLAIN ANALYZE SELECT * FROM c WITH collection=collname`, mustError: true},
+		{name: "error_read_many", sql: `EXPLAIN SELECT * FROM c WHERE (id, pk) IN (('1', 'a')) WITH db=db`, mustError: true},
+
+		{
+			name:     "explain",
+			sql:      `EXPLAIN SELECT * FROM c WHERE c.a > :1 ORDER BY c.b WITH db=db WITH collection=tbl`,
+			expected: &StmtExplain{StmtSelect: &StmtSelect{dbName: "db", collName: "tbl", selectQuery: `SELECT * FROM c WHERE c.a > @_1 ORDER BY c.b`, placeholders: map[int]string{1: "@_1"}}},
+		},
+		{
+			name:     "explain_analyze",
+			sql:      `explain analyze SELECT CROSS PARTITION c.id FROM tbl c WITH db=db`,
+			expected: &StmtExplain{StmtSelect: &StmtSelect{dbName: "db", collName: "tbl", isCrossPartition: true, selectQuery: `SELECT c.id FROM tbl c`, placeholders: map[int]string{}}, analyze: true},
+		},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtExplain)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtExplain but received %T", testName+"/"+testCase.name, s)
+			}
+			if stmt.query != testCase.sql {
+				t.Fatalf("%s failed: expected query %q but received %q", testName+"/"+testCase.name, testCase.sql, stmt.query)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
 func TestStmtUpdate_parse(t *testing.T) {
 	testName := "TestStmtUpdate_parse"
 	testData := []struct {
@@ -778,7 +1056,7 @@ func TestStmtUpdate_parse(t *testing.T) {
 		mustError bool
 	}{
 		{name: "error_no_collection", sql: `UPDATE db SET a=1,b=2,c=3 WHERE id=4`, mustError: true},
-		{name: "error_where", sql: `UPDATE db.table SET a=1,b=2,c=3 WHERE username=4`, mustError: true},
+		{name: "error_where", sql: `UPDATE db.table SET a=1,b=2,c=3 WHERE username=4 5`, mustError: true},
 		{name: "error_no_where", sql: `UPDATE db.table SET a=1,b=2,c=3`, mustError: true},
 		{name: "error_no_set", sql: `UPDATE db.table WHERE id=1`, mustError: true},
 		{name: "error_empty_set", sql: `UPDATE db.table SET      WHERE id=1`, mustError: true},
@@ -875,6 +1153,82 @@ func TestStmtUpdate_parse(t *testing.T) {
 				fields: []string{"a", "b", "c", "d"},
 				values: []interface{}{1.0, placeholder{2}, "3", placeholder{9}}},
 		},
+
+		{name: "error_predicate_singlepk", sql: `UPDATE db.table SET a=1 WHERE c.b<2 WITH SINGLE_PK`, mustError: true},
+		{name: "error_predicate_invalid_max_rows", sql: `UPDATE db.table SET a=1 WHERE c.b<2 WITH MAX_ROWS=0`, mustError: true},
+		{name: "error_predicate_invalid_max_rows2", sql: `UPDATE db.table SET a=1 WHERE c.b<2 WITH MAX_ROWS=abc`, mustError: true},
+		{name: "error_predicate_placeholder_zero", sql: `UPDATE db.table SET a=1 WHERE c.b=:0`, mustError: true},
+		{name: "error_placeholder_zero", sql: `UPDATE db.table SET a=$0 WHERE id=1`, mustError: true},
+		{name: "error_max_rows_without_predicate", sql: `UPDATE db.table SET a=1 WHERE id=1 AND pk=2 WITH MAX_ROWS=10`, mustError: true},
+		{
+			name: "predicate",
+			sql:  `UPDATE db.table SET status="\"archived\"" WHERE c.createdAt < :1 AND c.status != @status`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table",
+				predicate: "c.createdAt < @_1 AND c.status != @status", placeholders: map[int]string{1: "@_1"}},
+				fields: []string{"status"}, values: []interface{}{"archived"}},
+		},
+		{
+			name: "predicate_without_id",
+			sql:  `UPDATE db.table SET a=1 WHERE status=:1`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table",
+				predicate: "status=@_1", placeholders: map[int]string{1: "@_1"}},
+				fields: []string{"a"}, values: []interface{}{1.0}},
+		},
+		{
+			name: "predicate_and_comparison",
+			sql:  `UPDATE db.table SET a=1 WHERE status=:1 AND c.x>5`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table",
+				predicate: "status=@_1 AND c.x>5", placeholders: map[int]string{1: "@_1"}},
+				fields: []string{"a"}, values: []interface{}{1.0}},
+		},
+		{
+			name: "predicate_id_or",
+			sql:  `UPDATE db.table SET a=1 WHERE id=:1 OR id=:2`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 2}, dbName: "db", collName: "table",
+				predicate: "id=@_1 OR id=@_2", placeholders: map[int]string{1: "@_1", 2: "@_2"}},
+				fields: []string{"a"}, values: []interface{}{1.0}},
+		},
+		{
+			name: "predicate_max_rows",
+			sql:  `UPDATE db.table SET a=$1, b=$3 WHERE c.a > $2 OR NOT IS_DEFINED(c.a) WITH max_rows=100`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 3}, dbName: "db", collName: "table",
+				predicate: "c.a > @_2 OR NOT IS_DEFINED(c.a)", placeholders: map[int]string{2: "@_2"}, maxRows: 100},
+				fields: []string{"a", "b"}, values: []interface{}{placeholder{1}, placeholder{3}}},
+		},
+
+		{name: "error_set_invalid_path", sql: `UPDATE db.table SET a..b=1 WHERE id=1`, mustError: true},
+		{name: "error_unset_invalid_path", sql: `UPDATE db.table UNSET a[x] WHERE id=1`, mustError: true},
+		{name: "error_append_other_field", sql: `UPDATE db.table SET a=ARRAY_APPEND(b, 1) WHERE id=1`, mustError: true},
+		{
+			name: "nested_paths",
+			sql:  `UPDATE db.table SET address.city="\"Hanoi\"", tags[0]=:1, a.b[2].c=null WHERE id=1`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", pkPaths: []string{}},
+				id: 1.0, pkValues: []interface{}{},
+				fields: []string{"address.city", "tags[0]", "a.b[2].c"},
+				values: []interface{}{"Hanoi", placeholder{1}, nil}},
+		},
+		{
+			name: "increments_append",
+			sql:  `UPDATE db.table SET views = views + 1, stock.count=stock.count - :1, tags = ARRAY_APPEND(tags, "\"new\""), b=b-c WHERE id=1`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", pkPaths: []string{}},
+				id: 1.0, pkValues: []interface{}{},
+				fields: []string{"views", "stock.count", "tags", "b"},
+				values: []interface{}{updateIncrement{operand: 1.0}, updateIncrement{operand: placeholder{1}, negative: true}, updateAppend{"new"}, "b-c"}},
+		},
+		{
+			name: "unset_remove",
+			sql:  `UPDATE db.table SET a=1, remove=2 UNSET b, c.d REMOVE e[1] WHERE c.x > 0`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 0}, dbName: "db", collName: "table", predicate: "c.x > 0", placeholders: map[int]string{}},
+				fields: []string{"a", "remove", "b", "c.d", "e[1]"},
+				values: []interface{}{1.0, 2.0, updateUnset{}, updateUnset{}, updateUnset{}}},
+		},
+		{
+			name: "unset_only",
+			sql:  `UPDATE db.table REMOVE a.b WHERE id=:1`,
+			expected: &StmtUpdate{StmtCRUD: &StmtCRUD{Stmt: &Stmt{numInputs: 1}, dbName: "db", collName: "table", pkPaths: []string{}},
+				id: placeholder{1}, pkValues: []interface{}{},
+				fields: []string{"a.b"}, values: []interface{}{updateUnset{}}},
+		},
 	}
 	for _, testCase := range testData {
 		t.Run(testCase.name, func(t *testing.T) {
@@ -1040,3 +1394,484 @@ SET a=null, b=
 		})
 	}
 }
+
+func TestStmt_namedPlaceholders(t *testing.T) {
+	testName := "TestStmt_namedPlaceholders"
+	testData := []struct {
+		name     string
+		sql      string
+		numInput int
+		named    map[string]bool
+	}{
+		{name: "insert", sql: `INSERT INTO db.table (id,a,b) VALUES (@id, :1, @b_2) WITH PK=/id`, numInput: 1, named: map[string]bool{"id": true, "b_2": true}},
+		{name: "upsert", sql: `UPSERT INTO db.table (id,a) VALUES (@id, @id)`, numInput: 0, named: map[string]bool{"id": true}},
+		{name: "delete", sql: `DELETE FROM db.table WHERE id=@id AND pk=@pk`, numInput: 0, named: map[string]bool{"id": true, "pk": true}},
+		{name: "update", sql: `UPDATE db.table SET a=@a, b=$1 WHERE id=@id`, numInput: 1, named: map[string]bool{"a": true, "id": true}},
+		{name: "select", sql: `SELECT * FROM c WHERE c.a=@a AND c.b=:1 AND c.email="someone@example.com" AND c.c='@notparam' WITH db=mydb`, numInput: 1, named: map[string]bool{"a": true}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var stmt *Stmt
+			switch v := s.(type) {
+			case *StmtInsert:
+				stmt = v.Stmt
+			case *StmtDelete:
+				stmt = v.Stmt
+			case *StmtUpdate:
+				stmt = v.Stmt
+			case *StmtSelect:
+				stmt = v.Stmt
+			}
+			if stmt.numInputs != testCase.numInput {
+				t.Fatalf("%s failed: expected %d positional inputs but received %d", testName+"/"+testCase.name, testCase.numInput, stmt.numInputs)
+			}
+			if !reflect.DeepEqual(stmt.namedInputs, testCase.named) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.named, stmt.namedInputs)
+			}
+		})
+	}
+}
+
+func TestRewritePlaceholders(t *testing.T) {
+	testName := "TestRewritePlaceholders"
+	query, placeholders, err := _rewritePlaceholders(`c.a > :1 AND c.b = "$2" AND c.c = @3`)
+	if err != nil {
+		t.Fatalf("%s failed: %s", testName, err)
+	}
+	if expected := `c.a > @_1 AND c.b = "$2" AND c.c = @_3`; query != expected {
+		t.Fatalf("%s failed: expected %q but received %q", testName, expected, query)
+	}
+	if expected := map[int]string{1: "@_1", 3: "@_3"}; !reflect.DeepEqual(placeholders, expected) {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName, expected, placeholders)
+	}
+
+	_, _, err = _rewritePlaceholders(`c.a > :1 AND c.b = "unterminated`)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("%s failed: expected SyntaxError but received %#v", testName, err)
+	}
+}
+
+func TestStmt_bindArgs(t *testing.T) {
+	testName := "TestStmt_bindArgs"
+	stmt := &Stmt{numInputs: 1, namedInputs: map[string]bool{"id": true, "pk": true}}
+	testData := []struct {
+		name      string
+		args      []driver.NamedValue
+		mustError bool
+	}{
+		{name: "ok", args: []driver.NamedValue{{Ordinal: 1, Value: 1}, {Name: "id", Ordinal: 2, Value: "myid"}, {Name: "pk", Ordinal: 3, Value: "mypk"}}},
+		{name: "ok_prefixed_name", args: []driver.NamedValue{{Name: "@id", Ordinal: 1, Value: "myid"}, {Ordinal: 2, Value: 1}, {Name: "pk", Ordinal: 3, Value: "mypk"}}},
+		{name: "error_missing", args: []driver.NamedValue{{Ordinal: 1, Value: 1}, {Name: "id", Ordinal: 2, Value: "myid"}}, mustError: true},
+		{name: "error_unused", args: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: "myid"}, {Name: "pk", Ordinal: 2, Value: "mypk"}, {Name: "other", Ordinal: 3, Value: 1}}, mustError: true},
+		{name: "error_duplicated", args: []driver.NamedValue{{Name: "id", Ordinal: 1, Value: "myid"}, {Name: "id", Ordinal: 2, Value: "myid"}, {Name: "pk", Ordinal: 3, Value: "mypk"}}, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			args, err := stmt.bindArgs(testCase.args)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: binding must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if v := args.resolve(placeholder{1}); v != 1 {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, 1, v)
+			}
+			if v := args.resolve(namedPlaceholder{"id"}); v != "myid" {
+				t.Fatalf("%s failed: expected %#v but received %#v", testName+"/"+testCase.name, "myid", v)
+			}
+		})
+	}
+}
+
+func TestStmt_consistencyOpts(t *testing.T) {
+	testName := "TestStmt_consistencyOpts"
+	testData := []struct {
+		name         string
+		sql          string
+		consistency  string
+		sessionToken string
+		mustError    bool
+	}{
+		{name: "error_invalid_level", sql: `SELECT * FROM c WITH db=mydb WITH consistency=weak`, mustError: true},
+		{name: "error_empty_level", sql: `SELECT * FROM c WITH db=mydb WITH consistency`, mustError: true},
+		{name: "error_empty_session_token", sql: `SELECT * FROM c WITH db=mydb WITH session_token`, mustError: true},
+		{name: "error_insert", sql: `INSERT INTO db.table (id) VALUES (1) WITH consistency=Eventual`, mustError: true},
+
+		{name: "select_none", sql: `SELECT * FROM c WITH db=mydb`},
+		{name: "select_eventual", sql: `SELECT * FROM c WITH db=mydb WITH consistency=eventual`, consistency: "Eventual"},
+		{name: "select_bounded", sql: `SELECT * FROM c WITH db=mydb, WITH CONSISTENCY=BoundedStaleness`, consistency: "BoundedStaleness"},
+		{name: "select_session_token", sql: `SELECT * FROM c WITH db=mydb WITH consistency=Session WITH session_token=0:-1#12,1:-1#7#1=5`, consistency: "Session", sessionToken: "0:-1#12,1:-1#7#1=5"},
+		{name: "select_quoted_session_token", sql: `SELECT * FROM c WITH db=mydb WITH session_token="0:-1#12"`, sessionToken: "0:-1#12"},
+		{name: "update", sql: `UPDATE db.table SET a=1 WHERE id=2 WITH consistency=strong, WITH session_token=0:15`, consistency: "Strong", sessionToken: "0:15"},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var stmt *Stmt
+			switch v := s.(type) {
+			case *StmtUpdate:
+				stmt = v.Stmt
+			case *StmtSelect:
+				stmt = v.Stmt
+			}
+			if stmt.consistencyLevel != testCase.consistency {
+				t.Fatalf("%s failed: expected consistency level %#v but received %#v", testName+"/"+testCase.name, testCase.consistency, stmt.consistencyLevel)
+			}
+			if stmt.sessionToken != testCase.sessionToken {
+				t.Fatalf("%s failed: expected session token %#v but received %#v", testName+"/"+testCase.name, testCase.sessionToken, stmt.sessionToken)
+			}
+		})
+	}
+}
+
+func TestStmtUpdate_applyUpdates(t *testing.T) {
+	testName := "TestStmtUpdate_applyUpdates"
+	newDoc := func() DocInfo {
+		return DocInfo{"id": "1", "views": 10.0, "tags": []interface{}{"a", "b"}, "address": map[string]interface{}{"city": "HCM", "zip": "70000"}}
+	}
+	testData := []struct {
+		name      string
+		sql       string
+		args      []driver.Value
+		expected  DocInfo
+		mustError bool
+	}{
+		{name: "set_nested", sql: `UPDATE db.table SET address.city="\"Hanoi\"", geo.lat=1.5, tags[1]="\"c\"" WHERE id=1`,
+			expected: DocInfo{"id": "1", "views": 10.0, "tags": []interface{}{"a", "c"}, "address": map[string]interface{}{"city": "Hanoi", "zip": "70000"}, "geo": map[string]interface{}{"lat": 1.5}}},
+		{name: "increments", sql: `UPDATE db.table SET views=views+:1, likes=likes-2 WHERE id=1`, args: []driver.Value{int64(5)},
+			expected: DocInfo{"id": "1", "views": 15.0, "likes": -2.0, "tags": []interface{}{"a", "b"}, "address": map[string]interface{}{"city": "HCM", "zip": "70000"}}},
+		{name: "append", sql: `UPDATE db.table SET tags=ARRAY_APPEND(tags, :1), list=ARRAY_APPEND(list, 1) WHERE id=1`, args: []driver.Value{"x"},
+			expected: DocInfo{"id": "1", "views": 10.0, "tags": []interface{}{"a", "b", "x"}, "list": []interface{}{1.0}, "address": map[string]interface{}{"city": "HCM", "zip": "70000"}}},
+		{name: "unset", sql: `UPDATE db.table UNSET views, address.zip, tags[0], missing.field, tags[5] WHERE id=1`,
+			expected: DocInfo{"id": "1", "tags": []interface{}{"b"}, "address": map[string]interface{}{"city": "HCM"}}},
+		{name: "error_increment_non_number", sql: `UPDATE db.table SET tags=tags+1 WHERE id=1`, mustError: true},
+		{name: "error_increment_by_string", sql: `UPDATE db.table SET views=views+:1 WHERE id=1`, args: []driver.Value{"1"}, mustError: true},
+		{name: "error_append_non_array", sql: `UPDATE db.table SET views=ARRAY_APPEND(views, 1) WHERE id=1`, mustError: true},
+		{name: "error_index_out_of_range", sql: `UPDATE db.table SET tags[2]=1 WHERE id=1`, mustError: true},
+		{name: "error_key_of_array", sql: `UPDATE db.table SET tags.a=1 WHERE id=1`, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt := s.(*StmtUpdate)
+			args, err := stmt.bindArgs(_valuesToNamedValues(testCase.args))
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			doc := newDoc()
+			err = stmt.applyUpdates(doc, args)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: expected error", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if !reflect.DeepEqual(doc, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, doc)
+			}
+		})
+	}
+}
+
+func TestStmtUpdate_applyUpdates_largeInteger(t *testing.T) {
+	testName := "TestStmtUpdate_applyUpdates_largeInteger"
+	testData := []struct {
+		name     string
+		doc      string
+		sql      string
+		args     []driver.Value
+		expected string
+	}{
+		{name: "increment_placeholder", doc: `{"id":"1","n":1152921504606846976}`, sql: `UPDATE db.table SET n=n+:1 WHERE id=1`, args: []driver.Value{int64(1)},
+			expected: `{"id":"1","n":1152921504606846977}`},
+		{name: "decrement_placeholder", doc: `{"id":"1","n":9007199254740993}`, sql: `UPDATE db.table SET n=n-:1 WHERE id=1`, args: []driver.Value{uint64(2)},
+			expected: `{"id":"1","n":9007199254740991}`},
+		{name: "increment_missing", doc: `{"id":"1"}`, sql: `UPDATE db.table SET n=n+:1 WHERE id=1`, args: []driver.Value{int64(9007199254740993)},
+			expected: `{"id":"1","n":9007199254740993}`},
+		{name: "increment_float", doc: `{"id":"1","n":1.5}`, sql: `UPDATE db.table SET n=n+:1 WHERE id=1`, args: []driver.Value{int64(1)},
+			expected: `{"id":"1","n":2.5}`},
+		{name: "overflow", doc: `{"id":"1","n":9223372036854775807}`, sql: `UPDATE db.table SET n=n+:1 WHERE id=1`, args: []driver.Value{int64(1)},
+			expected: `{"id":"1","n":9223372036854776000}`},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt := s.(*StmtUpdate)
+			args, err := stmt.bindArgs(_valuesToNamedValues(testCase.args))
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			doc, err := _decodeDocExact([]byte(testCase.doc))
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if err = stmt.applyUpdates(doc, args); err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if js, _ := json.Marshal(doc); string(js) != testCase.expected {
+				t.Fatalf("%s failed:\nexpected %s\nreceived %s", testName+"/"+testCase.name, testCase.expected, js)
+			}
+		})
+	}
+
+	// int64 value in the document, i.e. not decoded from JSON
+	s, _ := ParseQueryWithDefaultDb(nil, "", `UPDATE db.table SET n=n+:1 WHERE id=1`)
+	stmt := s.(*StmtUpdate)
+	args, _ := stmt.bindArgs(_valuesToNamedValues([]driver.Value{int64(1)}))
+	doc := DocInfo{"id": "1", "n": int64(1 << 60)}
+	if err := stmt.applyUpdates(doc, args); err != nil {
+		t.Fatalf("%s failed: %s", testName+"/int64", err)
+	}
+	if expected := int64(1<<60 + 1); doc["n"] != expected {
+		t.Fatalf("%s failed: expected %#v but received %#v", testName+"/int64", expected, doc["n"])
+	}
+}
+
+func TestStmt_returning(t *testing.T) {
+	testName := "TestStmt_returning"
+	testData := []struct {
+		name      string
+		sql       string
+		returning []string
+		withPk    string
+		mustError bool
+	}{
+		{name: "no_returning", sql: `INSERT INTO db.table (a,b) VALUES (1,2)`},
+		{name: "insert_star", sql: `INSERT INTO db.table (a,b) VALUES (1,2) RETURNING *`, returning: []string{"*"}},
+		{name: "insert_fields_with", sql: `INSERT INTO db.table (a,b) VALUES (1,2) returning id, _etag , a.b WITH pk=/a`, returning: []string{"id", "_etag", "a.b"}, withPk: "/a"},
+		{name: "upsert_value", sql: `UPSERT INTO db.table VALUE {"id":"1","note":"returning x"} RETURNING _ts`, returning: []string{"_ts"}},
+		{name: "insert_select", sql: `INSERT INTO db.table SELECT * FROM c WITH collection=src RETURNING id`, returning: []string{"id"}},
+		{name: "insert_select2", sql: `INSERT INTO db.table SELECT * FROM src RETURNING id`, returning: []string{"id"}},
+		{name: "update", sql: `UPDATE db.table SET a=1 WHERE id=1 RETURNING *`, returning: []string{"*"}},
+		{name: "delete_predicate", sql: `DELETE FROM db.table WHERE c.a > 1 RETURNING id, a WITH MAX_ROWS=10`, returning: []string{"id", "a"}},
+		{name: "error_invalid_field", sql: `DELETE FROM db.table WHERE id=1 RETURNING a+b`, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var crud *StmtCRUD
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				crud = stmt.StmtCRUD
+			case *StmtInsertValue:
+				crud = stmt.StmtCRUD
+			case *StmtInsertSelect:
+				crud = stmt.StmtCRUD
+			case *StmtUpdate:
+				crud = stmt.StmtCRUD
+			case *StmtDelete:
+				crud = stmt.StmtCRUD
+			default:
+				t.Fatalf("%s failed: unexpected statement type %T", testName+"/"+testCase.name, s)
+			}
+			if !reflect.DeepEqual(crud.returning, testCase.returning) {
+				t.Fatalf("%s failed: expected returning %#v but received %#v", testName+"/"+testCase.name, testCase.returning, crud.returning)
+			}
+			if crud.withPk != testCase.withPk {
+				t.Fatalf("%s failed: expected WITH PK %#v but received %#v", testName+"/"+testCase.name, testCase.withPk, crud.withPk)
+			}
+		})
+	}
+}
+
+func TestStmt_buildReturningRows(t *testing.T) {
+	testName := "TestStmt_buildReturningRows"
+	docs := []DocInfo{
+		{"id": "1", "a": map[string]interface{}{"b": 1.0}, "_etag": "e1"},
+		{"id": "2", "_etag": "e2"},
+	}
+	testData := []struct {
+		name      string
+		returning []string
+		columns   []string
+		rows      [][]driver.Value
+	}{
+		{name: "star", returning: []string{"*"}, columns: []string{"_etag", "a", "id"},
+			rows: [][]driver.Value{{"e1", map[string]interface{}{"b": 1.0}, "1"}, {"e2", nil, "2"}}},
+		{name: "fields", returning: []string{"id", "a.b", "_etag"}, columns: []string{"id", "a.b", "_etag"},
+			rows: [][]driver.Value{{"1", 1.0, "e1"}, {"2", nil, "e2"}}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s := &StmtCRUD{returning: testCase.returning}
+			rows, err := s.buildReturningRows(&ResultNoResultSet{affectedRows: 2, docs: docs}, nil)
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			if !reflect.DeepEqual(rows.Columns(), testCase.columns) {
+				t.Fatalf("%s failed: expected columns %#v but received %#v", testName+"/"+testCase.name, testCase.columns, rows.Columns())
+			}
+			for i, expected := range testCase.rows {
+				row := make([]driver.Value, len(testCase.columns))
+				if err := rows.Next(row); err != nil {
+					t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+				}
+				if !reflect.DeepEqual(row, expected) {
+					t.Fatalf("%s failed: row #%d expected %#v but received %#v", testName+"/"+testCase.name, i, expected, row)
+				}
+			}
+		})
+	}
+}
+
+func TestStmt_etagCondition(t *testing.T) {
+	testName := "TestStmt_etagCondition"
+	testData := []struct {
+		name      string
+		sql       string
+		etag      interface{}
+		numInputs int
+		mustError bool
+	}{
+		{name: "update_no_etag", sql: `UPDATE db.table SET a=1 WHERE id=:1`, numInputs: 1},
+		{name: "update_where_etag", sql: `UPDATE db.table SET a=1 WHERE id=:1 AND _etag=:2 AND pk=:3`, etag: placeholder{2}, numInputs: 3},
+		{name: "update_with_if_match", sql: `UPDATE db.table SET a=:1 WHERE id=:2 WITH IF_MATCH=:3`, etag: placeholder{3}, numInputs: 3},
+		{name: "update_with_if_match_named", sql: `UPDATE db.table SET a=1 WHERE id=:1 WITH if_match=@etag`, etag: namedPlaceholder{"etag"}, numInputs: 1},
+		{name: "delete_where_etag", sql: `DELETE FROM db.table WHERE id=:1 AND _etag=@etag`, etag: namedPlaceholder{"etag"}, numInputs: 1},
+		{name: "delete_with_if_match", sql: `DELETE FROM db.table WHERE id=:1 AND pk=:2 WITH IF_MATCH=:3`, etag: placeholder{3}, numInputs: 3},
+		{name: "upsert_with_if_match", sql: `UPSERT INTO db.table (id,a) VALUES (:1,:2) WITH IF_MATCH=:3`, etag: placeholder{3}, numInputs: 3},
+		{name: "upsert_value_with_if_match", sql: `UPSERT INTO db.table VALUE :1 WITH IF_MATCH=:2`, etag: placeholder{2}, numInputs: 2},
+		{name: "error_etag_twice", sql: `UPDATE db.table SET a=1 WHERE id=:1 AND _etag=:2 WITH IF_MATCH=:3`, mustError: true},
+		{name: "error_update_predicate", sql: `UPDATE db.table SET a=1 WHERE c.a > 1 WITH IF_MATCH=:1`, mustError: true},
+		{name: "error_delete_predicate", sql: `DELETE FROM db.table WHERE c.a > 1 WITH IF_MATCH=:1`, mustError: true},
+		{name: "error_insert", sql: `INSERT INTO db.table (id,a) VALUES (:1,:2) WITH IF_MATCH=:3`, mustError: true},
+		{name: "error_insert_value", sql: `INSERT INTO db.table VALUE :1 WITH IF_MATCH=:2`, mustError: true},
+		{name: "error_multi_rows_upsert", sql: `UPSERT INTO db.table (id,a) VALUES (:1,:2), (:3,:4) WITH IF_MATCH=:5`, mustError: true},
+		{name: "error_empty_if_match", sql: `UPDATE db.table SET a=1 WHERE id=:1 WITH IF_MATCH`, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var crud *StmtCRUD
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				crud = stmt.StmtCRUD
+			case *StmtInsertValue:
+				crud = stmt.StmtCRUD
+			case *StmtUpdate:
+				crud = stmt.StmtCRUD
+			case *StmtDelete:
+				crud = stmt.StmtCRUD
+			default:
+				t.Fatalf("%s failed: unexpected statement type %T", testName+"/"+testCase.name, s)
+			}
+			if !reflect.DeepEqual(crud.etag, testCase.etag) || crud.numInputs != testCase.numInputs {
+				t.Fatalf("%s failed: expected etag %#v (inputs: %d) but received %#v (inputs: %d)", testName+"/"+testCase.name,
+					testCase.etag, testCase.numInputs, crud.etag, crud.numInputs)
+			}
+		})
+	}
+}
+
+func TestStmt_ttl(t *testing.T) {
+	testName := "TestStmt_ttl"
+	testData := []struct {
+		name      string
+		sql       string
+		ttl       int
+		mustError bool
+	}{
+		{name: "insert_no_ttl", sql: `INSERT INTO db.table (id,a) VALUES (:1,:2)`},
+		{name: "insert_ttl", sql: `INSERT INTO db.table (id,a) VALUES (:1,:2) WITH TTL=3600`, ttl: 3600},
+		{name: "upsert_never_expire", sql: `UPSERT INTO db.table (id,a) VALUES (:1,:2), (:3,:4) WITH pk=/id WITH ttl=-1`, ttl: -1},
+		{name: "insert_value_ttl", sql: `INSERT INTO db.table VALUE :1 WITH TTL=60`, ttl: 60},
+		{name: "error_zero", sql: `INSERT INTO db.table (id,a) VALUES (:1,:2) WITH TTL=0`, mustError: true},
+		{name: "error_off", sql: `INSERT INTO db.table (id,a) VALUES (:1,:2) WITH TTL=OFF`, mustError: true},
+		{name: "error_negative", sql: `INSERT INTO db.table VALUE :1 WITH TTL=-2`, mustError: true},
+		{name: "error_update", sql: `UPDATE db.table SET a=1 WHERE id=:1 WITH TTL=60`, mustError: true},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, "", testCase.sql)
+			if testCase.mustError {
+				if err == nil {
+					t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			var crud *StmtCRUD
+			switch stmt := s.(type) {
+			case *StmtInsert:
+				crud = stmt.StmtCRUD
+			case *StmtInsertValue:
+				crud = stmt.StmtCRUD
+			default:
+				t.Fatalf("%s failed: unexpected statement type %T", testName+"/"+testCase.name, s)
+			}
+			if crud.ttl != testCase.ttl {
+				t.Fatalf("%s failed: expected ttl %d but received %d", testName+"/"+testCase.name, testCase.ttl, crud.ttl)
+			}
+		})
+	}
+}
+
+func TestStmt_extractPkValues(t *testing.T) {
+	testName := "TestStmt_extractPkValues"
+	doc := DocInfo{"id": "1", "app": "myapp", "user": map[string]interface{}{"name": "me", "age": 10.0}}
+	testData := []struct {
+		name     string
+		pkPaths  []string
+		expected []interface{}
+	}{
+		{name: "single", pkPaths: []string{"/app"}, expected: []interface{}{"myapp"}},
+		{name: "nested", pkPaths: []string{"/user/name"}, expected: []interface{}{"me"}},
+		{name: "subpartitions", pkPaths: []string{"/app", "/user/age"}, expected: []interface{}{"myapp", 10.0}},
+		{name: "missing", pkPaths: []string{"/tenant", "/app/name"}, expected: []interface{}{map[string]interface{}{}, map[string]interface{}{}}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			pkValues := _extractPkValues(doc, testCase.pkPaths)
+			if !reflect.DeepEqual(pkValues, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, pkValues)
+			}
+		})
+	}
+}
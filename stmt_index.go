@@ -0,0 +1,473 @@
+package gocosmos
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// indexPath is a path of an index, along with its sort order (composite indexes only).
+type indexPath struct {
+	path  string
+	order string // "ascending" or "descending", empty for non-composite indexes
+}
+
+var spatialTypes = []string{"Point", "Polygon", "MultiPolygon", "LineString"}
+
+// indexingOpts holds the indexing options of CREATE/ALTER COLLECTION statements.
+type indexingOpts struct {
+	mode      string        // indexing mode, specified via WITH INDEXING=consistent|none
+	include   []string      // included paths, specified via WITH INCLUDE=/path1/?,/path2/*
+	exclude   []string      // excluded paths, specified via WITH EXCLUDE=/path1/?,/path2/*
+	composite [][]indexPath // composite indexes, specified via WITH COMPOSITE=/path1 ASC,/path2 DESC;/path3,/path4
+	spatial   []string      // paths of spatial indexes, specified via WITH SPATIAL=/path1/*,/path2/*
+}
+
+var (
+	reIndexListSep      = regexp.MustCompile(`\s*,\s*`)
+	reCompositeIndexSep = regexp.MustCompile(`\s*[;:]\s*`)
+)
+
+// parseOpt parses the WITH option k=v if it is an indexing option, returns false if it is not.
+func (o *indexingOpts) parseOpt(k, v string) (bool, error) {
+	paths := func() ([]string, error) {
+		result := reIndexListSep.Split(strings.TrimSpace(v), -1)
+		for _, path := range result {
+			if !strings.HasPrefix(path, "/") {
+				return nil, fmt.Errorf("invalid path <%s> at WITH %s, a path must start with /", path, k)
+			}
+		}
+		return result, nil
+	}
+	var err error
+	switch k {
+	case "INDEXING":
+		switch mode := strings.ToLower(v); mode {
+		case "consistent", "none":
+			o.mode = mode
+		default:
+			return true, fmt.Errorf("invalid INDEXING value: %s (accepted values: consistent, none)", v)
+		}
+	case "INCLUDE":
+		o.include, err = paths()
+	case "EXCLUDE":
+		o.exclude, err = paths()
+	case "SPATIAL":
+		o.spatial, err = paths()
+	case "COMPOSITE":
+		for _, index := range reCompositeIndexSep.Split(strings.TrimSpace(v), -1) {
+			compositePaths, err := _parseIndexPaths(reIndexListSep.Split(index, -1), true)
+			if err != nil {
+				return true, err
+			}
+			if len(compositePaths) < 2 {
+				return true, fmt.Errorf("invalid composite index <%s>, a composite index must have at least 2 paths", index)
+			}
+			o.composite = append(o.composite, compositePaths)
+		}
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// _parseIndexPaths parses index paths in format "<path> [ASC|DESC]" (sort order is accepted for composite indexes only).
+func _parseIndexPaths(specs []string, composite bool) ([]indexPath, error) {
+	result := make([]indexPath, 0, len(specs))
+	for _, spec := range specs {
+		tokens := strings.Fields(spec)
+		if len(tokens) == 0 || len(tokens) > 2 || !strings.HasPrefix(tokens[0], "/") {
+			return nil, fmt.Errorf("invalid index path <%s>", spec)
+		}
+		p := indexPath{path: tokens[0]}
+		if composite {
+			p.order = "ascending"
+		}
+		if len(tokens) == 2 {
+			switch order := strings.ToUpper(tokens[1]); {
+			case !composite:
+				return nil, fmt.Errorf("invalid index path <%s>, sort order is accepted for composite indexes only", spec)
+			case order == "ASC":
+			case order == "DESC":
+				p.order = "descending"
+			default:
+				return nil, fmt.Errorf("invalid sort order of index path <%s>", spec)
+			}
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func (o *indexingOpts) isSpecified() bool {
+	return o.mode != "" || o.include != nil || o.exclude != nil || o.composite != nil || o.spatial != nil
+}
+
+func (o *indexingOpts) validate() error {
+	if o.mode == "none" && (o.include != nil || o.exclude != nil || o.composite != nil || o.spatial != nil) {
+		return errors.New("indexes can not be specified with INDEXING=none")
+	}
+	return nil
+}
+
+// defaultIndexingPolicy returns the indexing policy Cosmos DB assigns to a new collection.
+func defaultIndexingPolicy() map[string]interface{} {
+	return map[string]interface{}{
+		"indexingMode":  "consistent",
+		"automatic":     true,
+		"includedPaths": []interface{}{map[string]interface{}{"path": "/*"}},
+		"excludedPaths": []interface{}{map[string]interface{}{"path": `/"_etag"/?`}},
+	}
+}
+
+// apply applies the indexing options to the indexing policy.
+func (o *indexingOpts) apply(policy map[string]interface{}) {
+	pathList := func(paths []string) []interface{} {
+		result := make([]interface{}, 0, len(paths))
+		for _, path := range paths {
+			result = append(result, map[string]interface{}{"path": path})
+		}
+		return result
+	}
+	if o.mode != "" {
+		policy["indexingMode"] = o.mode
+		policy["automatic"] = o.mode != "none"
+		if o.mode == "none" {
+			policy["includedPaths"] = []interface{}{}
+			policy["excludedPaths"] = []interface{}{}
+			delete(policy, "compositeIndexes")
+			delete(policy, "spatialIndexes")
+		} else if len(_policyPaths(policy, "includedPaths")) == 0 && o.include == nil {
+			// switching from none to consistent: index all paths by default
+			policy["includedPaths"] = pathList([]string{"/*"})
+		}
+	}
+	if o.include != nil {
+		policy["includedPaths"] = pathList(o.include)
+	}
+	if o.exclude != nil {
+		policy["excludedPaths"] = pathList(o.exclude)
+	}
+	if o.composite != nil {
+		compositeIndexes := make([]interface{}, 0, len(o.composite))
+		for _, index := range o.composite {
+			compositeIndexes = append(compositeIndexes, _compositeIndex(index))
+		}
+		policy["compositeIndexes"] = compositeIndexes
+	}
+	if o.spatial != nil {
+		spatialIndexes := make([]interface{}, 0, len(o.spatial))
+		for _, path := range o.spatial {
+			spatialIndexes = append(spatialIndexes, _spatialIndex(path))
+		}
+		policy["spatialIndexes"] = spatialIndexes
+	}
+}
+
+func _compositeIndex(paths []indexPath) []interface{} {
+	result := make([]interface{}, 0, len(paths))
+	for _, p := range paths {
+		result = append(result, map[string]interface{}{"path": p.path, "order": p.order})
+	}
+	return result
+}
+
+func _spatialIndex(path string) map[string]interface{} {
+	types := make([]interface{}, len(spatialTypes))
+	for i, t := range spatialTypes {
+		types[i] = t
+	}
+	return map[string]interface{}{"path": path, "types": types}
+}
+
+// _policyPaths returns the paths of the list (e.g. includedPaths) of the indexing policy.
+func _policyPaths(policy map[string]interface{}, key string) []string {
+	list, _ := policy[key].([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			path, _ := m["path"].(string)
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// _containsPolicyItem returns true if an item of the list (e.g. includedPaths) of the indexing policy matches.
+func _containsPolicyItem(policy map[string]interface{}, key string, match func(item interface{}) bool) bool {
+	list, _ := policy[key].([]interface{})
+	for _, item := range list {
+		if match(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// _removePolicyItem removes the items of the list (e.g. includedPaths) of the indexing policy that match, and
+// returns true if an item was removed.
+func _removePolicyItem(policy map[string]interface{}, key string, match func(item interface{}) bool) bool {
+	list, _ := policy[key].([]interface{})
+	result := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		if !match(item) {
+			result = append(result, item)
+		}
+	}
+	policy[key] = result
+	return len(result) != len(list)
+}
+
+func _appendPolicyItem(policy map[string]interface{}, key string, item interface{}) {
+	list, _ := policy[key].([]interface{})
+	policy[key] = append(list, item)
+}
+
+func _matchPath(path string) func(item interface{}) bool {
+	return func(item interface{}) bool {
+		m, ok := item.(map[string]interface{})
+		return ok && m["path"] == path
+	}
+}
+
+func _matchCompositeIndex(paths []indexPath) func(item interface{}) bool {
+	return func(item interface{}) bool {
+		list, ok := item.([]interface{})
+		if !ok || len(list) != len(paths) {
+			return false
+		}
+		for i, p := range paths {
+			m, ok := list[i].(map[string]interface{})
+			order, _ := m["order"].(string)
+			if order == "" {
+				order = "ascending"
+			}
+			if !ok || m["path"] != p.path || !strings.EqualFold(order, p.order) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// _modifyIndexingPolicy reads the indexing policy of a collection, modifies it with fn then writes it back if fn
+// returned true. The returned result reports 1 affected row if the indexing policy was written, 0 otherwise.
+func _modifyIndexingPolicy(conn *Conn, dbName, collName string, fn func(policy map[string]interface{}) (bool, error)) (driver.Result, error) {
+	return _modifyCollection(conn, dbName, collName, func(spec *CollectionSpec) (bool, error) {
+		return fn(spec.IndexingPolicy)
+	})
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtCreateIndex implements "CREATE INDEX" statement, which adds an index to the indexing policy of a collection.
+//
+// Syntax:
+//
+//	CREATE [COMPOSITE|SPATIAL] INDEX [IF NOT EXISTS] ON [<db-name>.]<collection-name> (<path1> [ASC|DESC] [, <path2> [ASC|DESC], ...])
+//
+// - CREATE INDEX: each path (e.g. /name/? or /tags/*) is added to the included paths (and removed from the excluded paths).
+//
+// - CREATE COMPOSITE INDEX: a composite index is made of the paths (e.g. /name ASC, /age DESC), at least 2 paths are required.
+//
+// - CREATE SPATIAL INDEX: a spatial index (of types Point, Polygon, MultiPolygon and LineString) is added for each path (e.g. /location/*).
+//
+// - Exec returns ErrConflict if the index already exists. If "IF NOT EXISTS" is specified, RowsAffected returns 0 instead.
+//
+// - The indexes are built in the background, see StmtShowIndexProgress.
+//
+// @Available since v1.2.0
+type StmtCreateIndex struct {
+	*Stmt
+	dbName      string
+	collName    string
+	kind        string // "", "COMPOSITE" or "SPATIAL"
+	paths       []indexPath
+	ifNotExists bool
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtCreateIndex) String() string {
+	return fmt.Sprintf(`StmtCreateIndex{Stmt: %s, db: %q, collection: %q, kind: %q, paths: %v, if_not_exists: %v}`,
+		s.Stmt, s.dbName, s.collName, s.kind, s.paths, s.ifNotExists)
+}
+
+func (s *StmtCreateIndex) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	if s.kind == "COMPOSITE" && len(s.paths) < 2 {
+		return errors.New("a composite index must have at least 2 paths")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtCreateIndex) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtCreateIndex) Exec(_ []driver.Value) (driver.Result, error) {
+	return _modifyIndexingPolicy(s.conn, s.dbName, s.collName, func(policy map[string]interface{}) (bool, error) {
+		changed := false
+		switch s.kind {
+		case "COMPOSITE":
+			if !_containsPolicyItem(policy, "compositeIndexes", _matchCompositeIndex(s.paths)) {
+				_appendPolicyItem(policy, "compositeIndexes", _compositeIndex(s.paths))
+				changed = true
+			}
+		case "SPATIAL":
+			for _, p := range s.paths {
+				if !_containsPolicyItem(policy, "spatialIndexes", _matchPath(p.path)) {
+					_appendPolicyItem(policy, "spatialIndexes", _spatialIndex(p.path))
+					changed = true
+				}
+			}
+		default:
+			for _, p := range s.paths {
+				if _removePolicyItem(policy, "excludedPaths", _matchPath(p.path)) {
+					changed = true
+				}
+				if !_containsPolicyItem(policy, "includedPaths", _matchPath(p.path)) {
+					_appendPolicyItem(policy, "includedPaths", map[string]interface{}{"path": p.path})
+					changed = true
+				}
+			}
+		}
+		if !changed && !s.ifNotExists {
+			return false, ErrConflict
+		}
+		return changed, nil
+	})
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtDropIndex implements "DROP INDEX" statement, which removes an index from the indexing policy of a collection.
+//
+// Syntax:
+//
+//	DROP [COMPOSITE|SPATIAL] INDEX [IF EXISTS] ON [<db-name>.]<collection-name> (<path1> [ASC|DESC] [, <path2> [ASC|DESC], ...])
+//
+// - DROP INDEX: each path is removed from the included paths; if a path is not explicitly included (e.g. it is covered
+// by the wildcard path /*), it is added to the excluded paths instead.
+//
+// - DROP COMPOSITE INDEX: the composite index made of the paths, in the same order and with the same sort orders, is removed.
+//
+// - DROP SPATIAL INDEX: the spatial index of each path is removed.
+//
+// - Exec returns ErrNotFound if the index does not exist. If "IF EXISTS" is specified, RowsAffected returns 0 instead.
+//
+// @Available since v1.2.0
+type StmtDropIndex struct {
+	*Stmt
+	dbName   string
+	collName string
+	kind     string // "", "COMPOSITE" or "SPATIAL"
+	paths    []indexPath
+	ifExists bool
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtDropIndex) String() string {
+	return fmt.Sprintf(`StmtDropIndex{Stmt: %s, db: %q, collection: %q, kind: %q, paths: %v, if_exists: %v}`,
+		s.Stmt, s.dbName, s.collName, s.kind, s.paths, s.ifExists)
+}
+
+func (s *StmtDropIndex) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Query implements driver.Stmt/Query.
+// This function is not implemented, use Exec instead.
+func (s *StmtDropIndex) Query(_ []driver.Value) (driver.Rows, error) {
+	return nil, ErrQueryNotSupported
+}
+
+// Exec implements driver.Stmt/Exec.
+func (s *StmtDropIndex) Exec(_ []driver.Value) (driver.Result, error) {
+	return _modifyIndexingPolicy(s.conn, s.dbName, s.collName, func(policy map[string]interface{}) (bool, error) {
+		changed := false
+		switch s.kind {
+		case "COMPOSITE":
+			changed = _removePolicyItem(policy, "compositeIndexes", _matchCompositeIndex(s.paths))
+		case "SPATIAL":
+			for _, p := range s.paths {
+				changed = _removePolicyItem(policy, "spatialIndexes", _matchPath(p.path)) || changed
+			}
+		default:
+			for _, p := range s.paths {
+				if _removePolicyItem(policy, "includedPaths", _matchPath(p.path)) {
+					changed = true
+				} else if !_containsPolicyItem(policy, "excludedPaths", _matchPath(p.path)) {
+					_appendPolicyItem(policy, "excludedPaths", map[string]interface{}{"path": p.path})
+					changed = true
+				}
+			}
+		}
+		if !changed && !s.ifExists {
+			return false, ErrNotFound
+		}
+		return changed, nil
+	})
+}
+
+/*----------------------------------------------------------------------*/
+
+// StmtShowIndexProgress implements "SHOW INDEX PROGRESS" statement, which reports the progress of the transformation
+// of a collection's indexes after its indexing policy has been changed.
+//
+// Syntax:
+//
+//	SHOW INDEX PROGRESS [<db-name>.]<collection-name>
+//
+// The statement returns a single row with columns: id (name of the collection), indexingMode, progress (from 0 to 100,
+// 100 means all indexes are up-to-date, -1 if not reported by the server) and indexingPolicy.
+//
+// @Available since v1.2.0
+type StmtShowIndexProgress struct {
+	*Stmt
+	dbName   string
+	collName string
+}
+
+// String implements interface fmt.Stringer/String.
+func (s *StmtShowIndexProgress) String() string {
+	return fmt.Sprintf(`StmtShowIndexProgress{Stmt: %s, db: %q, collection: %q}`, s.Stmt, s.dbName, s.collName)
+}
+
+func (s *StmtShowIndexProgress) validate() error {
+	if s.dbName == "" || s.collName == "" {
+		return errors.New("database/collection is missing")
+	}
+	return nil
+}
+
+// Exec implements driver.Stmt/Exec.
+// This function is not implemented, use Query instead.
+func (s *StmtShowIndexProgress) Exec(_ []driver.Value) (driver.Result, error) {
+	return nil, ErrExecNotSupported
+}
+
+// Query implements driver.Stmt/Query.
+func (s *StmtShowIndexProgress) Query(_ []driver.Value) (driver.Rows, error) {
+	restResult := s.conn.restClient.GetIndexingProgress(s.dbName, s.collName)
+	if err := restResult.Error(); err != nil {
+		return nil, normalizeError(restResult.StatusCode, 0, err)
+	}
+	mode, _ := restResult.IndexingPolicy["indexingMode"].(string)
+	row := DocInfo{
+		"id":             restResult.Id,
+		"indexingMode":   mode,
+		"progress":       restResult.IndexTransformationProgress,
+		"indexingPolicy": restResult.IndexingPolicy,
+	}
+	return (&ResultResultSet{rows: []DocInfo{row}}).init(), nil
+}
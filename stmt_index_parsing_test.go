@@ -0,0 +1,178 @@
+package gocosmos
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStmtCreateIndex_parse(t *testing.T) {
+	testName := "TestStmtCreateIndex_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtCreateIndex
+		mustError bool
+	}{
+		{name: "error_no_collection", sql: "CREATE INDEX ON db. (/a/?)", mustError: true},
+		{name: "error_no_db", sql: "CREATE INDEX ON coll (/a/?)", mustError: true},
+		{name: "error_no_on", sql: "CREATE INDEX db.coll (/a/?)", mustError: true},
+		{name: "error_no_paths", sql: "CREATE INDEX ON db.coll ()", mustError: true},
+		{name: "error_invalid_path", sql: "CREATE INDEX ON db.coll (a)", mustError: true},
+		{name: "error_order_not_composite", sql: "CREATE INDEX ON db.coll (/a/? DESC)", mustError: true},
+		{name: "error_composite_single_path", sql: "CREATE COMPOSITE INDEX ON db.coll (/a)", mustError: true},
+		{name: "error_if_exists", sql: "CREATE INDEX IF EXISTS ON db.coll (/a/?)", mustError: true},
+		{name: "error_trailing_tokens", sql: "CREATE INDEX ON db.coll (/a/?) WITH ru=400", mustError: true},
+
+		{name: "basic", sql: "CREATE INDEX ON db1.table1 (/name/?, /tags/*)", expected: &StmtCreateIndex{dbName: "db1", collName: "table1",
+			paths: []indexPath{{path: "/name/?"}, {path: "/tags/*"}}}},
+		{name: "default_db", db: "mydb", sql: "create index if not exists on table1 (/\"_etag\"/?)", expected: &StmtCreateIndex{dbName: "mydb", collName: "table1",
+			paths: []indexPath{{path: `/"_etag"/?`}}, ifNotExists: true}},
+		{name: "composite", sql: "CREATE COMPOSITE INDEX ON db1.table1 (/name, /age DESC, /city asc)", expected: &StmtCreateIndex{dbName: "db1", collName: "table1", kind: "COMPOSITE",
+			paths: []indexPath{{"/name", "ascending"}, {"/age", "descending"}, {"/city", "ascending"}}}},
+		{name: "spatial", sql: "CREATE SPATIAL INDEX ON db1.table1 (/location/*)", expected: &StmtCreateIndex{dbName: "db1", collName: "table1", kind: "SPATIAL",
+			paths: []indexPath{{path: "/location/*"}}}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtCreateIndex)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtCreateIndex but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtDropIndex_parse(t *testing.T) {
+	testName := "TestStmtDropIndex_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtDropIndex
+		mustError bool
+	}{
+		{name: "error_no_db", sql: "DROP INDEX ON coll (/a/?)", mustError: true},
+		{name: "error_if_not_exists", sql: "DROP INDEX IF NOT EXISTS ON db.coll (/a/?)", mustError: true},
+		{name: "error_missing_parenthesis", sql: "DROP INDEX ON db.coll (/a/?", mustError: true},
+		{name: "error_alter_index", sql: "ALTER INDEX ON db.coll (/a/?)", mustError: true},
+
+		{name: "basic", sql: "DROP INDEX ON db1.table1 (/name/?)", expected: &StmtDropIndex{dbName: "db1", collName: "table1",
+			paths: []indexPath{{path: "/name/?"}}}},
+		{name: "if_exists_default_db", db: "mydb", sql: "drop spatial index if exists on table1 (/a/*, /b/*)", expected: &StmtDropIndex{dbName: "mydb", collName: "table1", kind: "SPATIAL",
+			paths: []indexPath{{path: "/a/*"}, {path: "/b/*"}}, ifExists: true}},
+		{name: "composite", sql: "DROP COMPOSITE INDEX ON db1.table1 (/name DESC, /age)", expected: &StmtDropIndex{dbName: "db1", collName: "table1", kind: "COMPOSITE",
+			paths: []indexPath{{"/name", "descending"}, {"/age", "ascending"}}}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtDropIndex)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtDropIndex but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestStmtShowIndexProgress_parse(t *testing.T) {
+	testName := "TestStmtShowIndexProgress_parse"
+	testData := []struct {
+		name      string
+		db        string
+		sql       string
+		expected  *StmtShowIndexProgress
+		mustError bool
+	}{
+		{name: "error_no_db", sql: "SHOW INDEX PROGRESS coll", mustError: true},
+		{name: "error_no_progress", sql: "SHOW INDEX db.coll", mustError: true},
+		{name: "error_trailing_tokens", sql: "SHOW INDEX PROGRESS db.coll WITH a=1", mustError: true},
+
+		{name: "basic", sql: "SHOW INDEX PROGRESS db1.table1", expected: &StmtShowIndexProgress{dbName: "db1", collName: "table1"}},
+		{name: "default_db", db: "mydb", sql: "show index progress table1;", expected: &StmtShowIndexProgress{dbName: "mydb", collName: "table1"}},
+	}
+	for _, testCase := range testData {
+		t.Run(testCase.name, func(t *testing.T) {
+			s, err := ParseQueryWithDefaultDb(nil, testCase.db, testCase.sql)
+			if testCase.mustError && err == nil {
+				t.Fatalf("%s failed: parsing must fail", testName+"/"+testCase.name)
+			}
+			if testCase.mustError {
+				return
+			}
+			if err != nil {
+				t.Fatalf("%s failed: %s", testName+"/"+testCase.name, err)
+			}
+			stmt, ok := s.(*StmtShowIndexProgress)
+			if !ok {
+				t.Fatalf("%s failed: expected StmtShowIndexProgress but received %T", testName+"/"+testCase.name, s)
+			}
+			stmt.Stmt = nil
+			if !reflect.DeepEqual(stmt, testCase.expected) {
+				t.Fatalf("%s failed:\nexpected %#v\nreceived %#v", testName+"/"+testCase.name, testCase.expected, stmt)
+			}
+		})
+	}
+}
+
+func TestIndexingOpts_apply(t *testing.T) {
+	testName := "TestIndexingOpts_apply"
+	policy := defaultIndexingPolicy()
+	opts := indexingOpts{
+		exclude:   []string{"/large/*"},
+		composite: [][]indexPath{{{"/a", "ascending"}, {"/b", "descending"}}},
+		spatial:   []string{"/loc/*"},
+	}
+	opts.apply(policy)
+	if paths := _policyPaths(policy, "includedPaths"); !reflect.DeepEqual(paths, []string{"/*"}) {
+		t.Fatalf("%s failed: received included paths %#v", testName, paths)
+	}
+	if paths := _policyPaths(policy, "excludedPaths"); !reflect.DeepEqual(paths, []string{"/large/*"}) {
+		t.Fatalf("%s failed: received excluded paths %#v", testName, paths)
+	}
+	if !_containsPolicyItem(policy, "compositeIndexes", _matchCompositeIndex([]indexPath{{"/a", "ascending"}, {"/b", "descending"}})) {
+		t.Fatalf("%s failed: received composite indexes %#v", testName, policy["compositeIndexes"])
+	}
+	if _containsPolicyItem(policy, "compositeIndexes", _matchCompositeIndex([]indexPath{{"/a", "ascending"}, {"/b", "ascending"}})) {
+		t.Fatalf("%s failed: composite index must not match another sort order", testName)
+	}
+	if paths := _policyPaths(policy, "spatialIndexes"); !reflect.DeepEqual(paths, []string{"/loc/*"}) {
+		t.Fatalf("%s failed: received spatial indexes %#v", testName, paths)
+	}
+
+	(&indexingOpts{mode: "none"}).apply(policy)
+	if policy["indexingMode"] != "none" || policy["automatic"] != false || len(_policyPaths(policy, "includedPaths")) != 0 || policy["compositeIndexes"] != nil {
+		t.Fatalf("%s failed: received policy %#v", testName, policy)
+	}
+	(&indexingOpts{mode: "consistent"}).apply(policy)
+	if paths := _policyPaths(policy, "includedPaths"); policy["indexingMode"] != "consistent" || !reflect.DeepEqual(paths, []string{"/*"}) {
+		t.Fatalf("%s failed: received policy %#v", testName, policy)
+	}
+}
@@ -8,6 +8,7 @@ const (
 	httpHeaderAuthorization = "Authorization"
 	httpHeaderIfMatch       = "If-Match"
 	httpHeaderIfNoneMatch   = "If-None-Match"
+	httpHeaderSlug          = "Slug"
 
 	restApiHeaderVersion                        = "x-ms-version"
 	restApiHeaderDate                           = "x-ms-date"
@@ -29,21 +30,36 @@ const (
 	restApiHeaderMigrateToAutopilotThroughput   = "x-ms-cosmos-migrate-offer-to-autopilot"
 	restApiHeaderSupportedQueryFeatures         = "x-ms-cosmos-supported-query-features"
 	restApiHeaderPopulateMetrics                = "x-ms-documentdb-populatequerymetrics"
+	restApiHeaderPopulateQuotaInfo              = "x-ms-documentdb-populatequotainfo"
 	restApiHeaderIncremental                    = "A-IM"
 
-	restApiParamIndexingPolicy  = "indexingPolicy"
-	restApiParamUniqueKeyPolicy = "uniqueKeyPolicy"
-	restApiParamPartitionKey    = "partitionKey"
-	restApiParamQuery           = "query"
-	restApiParamParameters      = "parameters"
-	restApiParamContent         = "content"
+	restApiParamIndexingPolicy           = "indexingPolicy"
+	restApiParamUniqueKeyPolicy          = "uniqueKeyPolicy"
+	restApiParamConflictResolutionPolicy = "conflictResolutionPolicy"
+	restApiParamPartitionKey             = "partitionKey"
+	restApiParamDefaultTtl               = "defaultTtl"
+	restApiParamQuery                    = "query"
+	restApiParamParameters               = "parameters"
+	restApiParamContent                  = "content"
 
 	respHeaderRequestCharge = "X-MS-REQUEST-CHARGE"
 	respHeaderSessionToken  = "X-MS-SESSION-TOKEN"
 	respHeaderContinuation  = "X-MS-CONTINUATION"
 	respHeaderEtag          = "ETAG"
+	respHeaderRetryAfterMs  = "X-MS-RETRY-AFTER-MS"
+	respHeaderQueryMetrics  = "X-MS-DOCUMENTDB-QUERY-METRICS"
+	respHeaderPkRangeId     = "X-MS-DOCUMENTDB-PARTITIONKEYRANGEID"
+	respHeaderSubStatus     = "X-MS-SUBSTATUS"
 
-	docFieldId = "id"
+	respHeaderOfferReplacePending = "X-MS-OFFER-REPLACE-PENDING"
+
+	respHeaderMaxMediaStorageUsageMb = "X-MS-MAX-MEDIA-STORAGE-USAGE-MB"
+	respHeaderMediaStorageUsageMb    = "X-MS-MEDIA-STORAGE-USAGE-MB"
+
+	respHeaderIndexTransformationProgress = "X-MS-DOCUMENTDB-COLLECTION-INDEX-TRANSFORMATION-PROGRESS"
+
+	docFieldId  = "id"
+	docFieldTtl = "ttl"
 )
 
 func goTypeToCosmosDbType(typ reflect.Type) string {